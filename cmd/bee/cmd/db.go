@@ -0,0 +1,191 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const optionNameDBDataDir = "data-dir"
+
+func (c *command) initDBCmd() {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the database of a stopped node",
+	}
+
+	cmd.AddCommand(
+		c.newDBCmd(&cobra.Command{
+			Use:   "export <file>",
+			Short: "Export all stored chunks to a tar file, or to the standard output if file is -",
+			Args:  cobra.ExactArgs(1),
+		}, func(cmd *cobra.Command, args []string, db *localstore.DB) (err error) {
+			w := cmd.OutOrStdout()
+			if args[0] != "-" {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			count, err := db.Export(w, storage.ExportFilter{})
+			if err != nil {
+				return fmt.Errorf("export: %w", err)
+			}
+			if args[0] != "-" {
+				cmd.Printf("exported %d chunks\n", count)
+			}
+			return nil
+		}),
+		c.newDBCmd(&cobra.Command{
+			Use:   "import <file>",
+			Short: "Import chunks from a tar file, or from the standard input if file is -",
+			Args:  cobra.ExactArgs(1),
+		}, func(cmd *cobra.Command, args []string, db *localstore.DB) (err error) {
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			result, err := db.Import(r, storage.ImportOptions{
+				PushSync: true,
+			})
+			if err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+			cmd.Printf("imported %d chunks\n", result.Imported)
+			return nil
+		}),
+		c.newDBCmd(&cobra.Command{
+			Use:   "stats",
+			Short: "Print the number of items in database indexes",
+			Args:  cobra.NoArgs,
+		}, func(cmd *cobra.Command, args []string, db *localstore.DB) (err error) {
+			indexes, err := db.DebugIndices()
+			if err != nil {
+				return fmt.Errorf("stats: %w", err)
+			}
+			names := make([]string, 0, len(indexes))
+			for name := range indexes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				cmd.Printf("%s: %d\n", name, indexes[name])
+			}
+			return nil
+		}),
+		c.newDBCmd(&cobra.Command{
+			Use:   "validate",
+			Short: "Check the consistency of database indexes",
+			Args:  cobra.NoArgs,
+		}, func(cmd *cobra.Command, args []string, db *localstore.DB) (err error) {
+			r, err := db.Validate()
+			if err != nil {
+				return fmt.Errorf("validate: %w", err)
+			}
+			printValidationReport(cmd, r)
+			if !r.Consistent() {
+				return errors.New("inconsistent database indexes, run bee db repair to rebuild them")
+			}
+			cmd.Println("database indexes are consistent")
+			return nil
+		}),
+		c.newDBCmd(&cobra.Command{
+			Use:   "repair",
+			Short: "Rebuild database indexes from the stored chunks",
+			Args:  cobra.NoArgs,
+		}, func(cmd *cobra.Command, args []string, db *localstore.DB) (err error) {
+			r, err := db.Repair()
+			if err != nil {
+				return fmt.Errorf("repair: %w", err)
+			}
+			printValidationReport(cmd, r)
+			if r.Consistent() {
+				cmd.Println("database indexes are consistent, nothing repaired")
+				return nil
+			}
+			cmd.Println("database indexes are repaired")
+			return nil
+		}),
+		c.newDBCmd(&cobra.Command{
+			Use:   "compact",
+			Short: "Compact the database to release the space of deleted data",
+			Args:  cobra.NoArgs,
+		}, func(cmd *cobra.Command, args []string, db *localstore.DB) (err error) {
+			if err := db.Compact(); err != nil {
+				return fmt.Errorf("compact: %w", err)
+			}
+			cmd.Println("database is compacted")
+			return nil
+		}),
+	)
+
+	c.root.AddCommand(cmd)
+}
+
+// newDBCmd sets the flags of the database subcommand and its run function
+// that is called with the database opened for maintenance.
+func (c *command) newDBCmd(cmd *cobra.Command, run func(cmd *cobra.Command, args []string, db *localstore.DB) error) *cobra.Command {
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		return c.config.BindPFlags(cmd.Flags())
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) (err error) {
+		path := filepath.Join(c.config.GetString(optionNameDBDataDir), "localstore")
+		// do not create a new database
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("localstore: %w", err)
+		}
+		logger := logging.New(cmd.ErrOrStderr(), logrus.WarnLevel)
+		db, err := localstore.New(path, nil, &localstore.Options{Maintenance: true}, logger)
+		if err != nil {
+			if errors.Is(err, localstore.ErrBaseKeyUnknown) {
+				return fmt.Errorf("localstore: %w, start the node once to store it", err)
+			}
+			return fmt.Errorf("localstore: %w, the node must be stopped", err)
+		}
+		defer func() {
+			if e := db.Close(); e != nil && err == nil {
+				err = fmt.Errorf("localstore: %w", e)
+			}
+		}()
+		return run(cmd, args, db)
+	}
+
+	cmd.Flags().String(optionNameDBDataDir, filepath.Join(c.homeDir, ".bee"), "data directory of the stopped node")
+	return cmd
+}
+
+func printValidationReport(cmd *cobra.Command, r *localstore.ValidationReport) {
+	cmd.Printf("chunks: %d\n", r.Chunks)
+	names := make([]string, 0, len(r.Indexes))
+	for name := range r.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i := r.Indexes[name]
+		cmd.Printf("%s: %d missing, %d dangling\n", name, i.Missing, i.Dangling)
+	}
+	cmd.Printf("inconsistent bin ids: %d\n", r.BinIDs)
+	cmd.Printf("gc size mismatch: %t\n", r.GCSize)
+	cmd.Printf("reserve size mismatch: %t\n", r.ReserveSize)
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/cmd/bee/cmd"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestDBCmd(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "bee-cmd-db-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	db, err := localstore.New(filepath.Join(dataDir, "localstore"), swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c").Bytes(), nil, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := chunktesting.GenerateTestRandomChunks(10)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(t *testing.T, args ...string) string {
+		t.Helper()
+
+		var outputBuf bytes.Buffer
+		if err := newCommand(t,
+			cmd.WithArgs(append([]string{"db"}, append(args, "--data-dir", dataDir)...)...),
+			cmd.WithOutput(&outputBuf),
+		).Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return outputBuf.String()
+	}
+
+	t.Run("stats", func(t *testing.T) {
+		got := run(t, "stats")
+		if want := "retrievalDataIndex: 10\n"; !strings.Contains(got, want) {
+			t.Errorf("got output %q, want it to contain %q", got, want)
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		got := run(t, "validate")
+		if want := "database indexes are consistent\n"; !strings.HasSuffix(got, want) {
+			t.Errorf("got output %q, want it to end with %q", got, want)
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		got := run(t, "export", filepath.Join(dataDir, "export.tar"))
+		if want := "exported 10 chunks\n"; got != want {
+			t.Errorf("got output %q, want %q", got, want)
+		}
+	})
+}
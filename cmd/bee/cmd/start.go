@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -28,6 +29,12 @@ func (c *command) initStartCmd() (err error) {
 	const (
 		optionNameDataDir              = "data-dir"
 		optionNameDBCapacity           = "db-capacity"
+		optionNameDBCapacityBytes      = "db-capacity-bytes"
+		optionNameDBReserveCapacity    = "db-reserve-capacity"
+		optionNameDBGCPolicy           = "db-gc-policy"
+		optionNameDBScrubRate          = "db-scrub-rate"
+		optionNameDBReadOnly           = "db-read-only"
+		optionNameDBCacheOnly          = "db-cache-only"
 		optionNamePassword             = "password"
 		optionNamePasswordFile         = "password-file"
 		optionNameAPIAddr              = "api-addr"
@@ -35,6 +42,7 @@ func (c *command) initStartCmd() (err error) {
 		optionNameNATAddr              = "nat-addr"
 		optionNameP2PWSEnable          = "p2p-ws-enable"
 		optionNameP2PQUICEnable        = "p2p-quic-enable"
+		optionNameP2PMDNSEnable        = "p2p-mdns-enable"
 		optionNameDebugAPIEnable       = "debug-api-enable"
 		optionNameDebugAPIAddr         = "debug-api-addr"
 		optionNameBootnodes            = "bootnode"
@@ -48,6 +56,15 @@ func (c *command) initStartCmd() (err error) {
 		optionNameGlobalPinningEnabled = "global-pinning-enable"
 		optionNamePaymentThreshold     = "payment-threshold"
 		optionNamePaymentTolerance     = "payment-tolerance"
+		optionNamePushMaxAttempts      = "push-max-attempts"
+		optionNameBandwidthUpload      = "bandwidth-upload-limit"
+		optionNameBandwidthDownload    = "bandwidth-download-limit"
+		optionNameBandwidthWeights     = "bandwidth-weights"
+		optionNameLightNode            = "light"
+		optionNameP2PMaxInbound        = "p2p-max-inbound"
+		optionNameP2PMaxOutbound       = "p2p-max-outbound"
+		optionNameP2PReservedSlots     = "p2p-reserved-slots"
+		optionNameP2PPinnedPeers       = "p2p-pinned-peers"
 	)
 
 	cmd := &cobra.Command{
@@ -114,9 +131,31 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 				password = p
 			}
 
+			bandwidthWeights, err := parseBandwidthWeights(c.config.GetStringSlice(optionNameBandwidthWeights))
+			if err != nil {
+				return err
+			}
+
+			pinnedPeers, err := parsePinnedPeers(c.config.GetStringSlice(optionNameP2PPinnedPeers))
+			if err != nil {
+				return err
+			}
+
+			// the reserve is a half of the capacity, unless it is set
+			reserveCapacity := c.config.GetUint64(optionNameDBCapacity) / 2
+			if c.config.IsSet(optionNameDBReserveCapacity) {
+				reserveCapacity = c.config.GetUint64(optionNameDBReserveCapacity)
+			}
+
 			b, err := node.NewBee(c.config.GetString(optionNameP2PAddr), logger, node.Options{
 				DataDir:              c.config.GetString(optionNameDataDir),
 				DBCapacity:           c.config.GetUint64(optionNameDBCapacity),
+				DBCapacityBytes:      c.config.GetUint64(optionNameDBCapacityBytes),
+				DBReserveCapacity:    reserveCapacity,
+				DBGCPolicy:           c.config.GetString(optionNameDBGCPolicy),
+				DBScrubRate:          c.config.GetInt(optionNameDBScrubRate),
+				DBReadOnly:           c.config.GetBool(optionNameDBReadOnly),
+				DBCacheOnly:          c.config.GetBool(optionNameDBCacheOnly),
 				Password:             password,
 				APIAddr:              c.config.GetString(optionNameAPIAddr),
 				DebugAPIAddr:         debugAPIAddr,
@@ -124,6 +163,7 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 				NATAddr:              c.config.GetString(optionNameNATAddr),
 				EnableWS:             c.config.GetBool(optionNameP2PWSEnable),
 				EnableQUIC:           c.config.GetBool(optionNameP2PQUICEnable),
+				EnableMDNS:           c.config.GetBool(optionNameP2PMDNSEnable),
 				NetworkID:            c.config.GetUint64(optionNameNetworkID),
 				WelcomeMessage:       c.config.GetString(optionWelcomeMessage),
 				Bootnodes:            c.config.GetStringSlice(optionNameBootnodes),
@@ -135,6 +175,15 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 				GlobalPinningEnabled: c.config.GetBool(optionNameGlobalPinningEnabled),
 				PaymentThreshold:     c.config.GetUint64(optionNamePaymentThreshold),
 				PaymentTolerance:     c.config.GetUint64(optionNamePaymentTolerance),
+				PushMaxAttempts:      c.config.GetInt(optionNamePushMaxAttempts),
+				BandwidthUpload:      c.config.GetUint64(optionNameBandwidthUpload),
+				BandwidthDownload:    c.config.GetUint64(optionNameBandwidthDownload),
+				BandwidthWeights:     bandwidthWeights,
+				LightNode:            c.config.GetBool(optionNameLightNode),
+				P2PMaxInbound:        c.config.GetInt(optionNameP2PMaxInbound),
+				P2PMaxOutbound:       c.config.GetInt(optionNameP2PMaxOutbound),
+				P2PReservedSlots:     c.config.GetInt(optionNameP2PReservedSlots),
+				P2PPinnedPeers:       pinnedPeers,
 			})
 			if err != nil {
 				return err
@@ -181,6 +230,12 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 
 	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
 	cmd.Flags().Uint64(optionNameDBCapacity, 5000000, fmt.Sprintf("db capacity in chunks, multiply by %d to get approximate capacity in bytes", swarm.ChunkSize))
+	cmd.Flags().Uint64(optionNameDBCapacityBytes, 0, "db capacity in bytes of the actual size on disk, including indexes, 0 is unlimited")
+	cmd.Flags().Uint64(optionNameDBReserveCapacity, 0, "part of db capacity in chunks reserved for the chunks within the storage radius, protected from garbage collection, half of db capacity if not set, 0 disables the reserve")
+	cmd.Flags().String(optionNameDBGCPolicy, "lru", "order in which chunks are garbage collected: lru (least recently used), lfu (least frequently used) or proximity (most distant first)")
+	cmd.Flags().Int(optionNameDBScrubRate, 100, "maximal number of stored chunks validated per second in the background, 0 disables validation")
+	cmd.Flags().Bool(optionNameDBReadOnly, false, "serve chunks from an existing db without changing it, disables storing chunks, garbage collection and syncing, the node joins the network as a light node")
+	cmd.Flags().Bool(optionNameDBCacheOnly, false, "store only chunks retrieved from the network, disables the reserve and pull syncing, the node joins the network as a light node")
 	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
 	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
 	cmd.Flags().String(optionNameAPIAddr, ":8080", "HTTP API listen address")
@@ -188,6 +243,7 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 	cmd.Flags().String(optionNameNATAddr, "", "NAT exposed address")
 	cmd.Flags().Bool(optionNameP2PWSEnable, false, "enable P2P WebSocket transport")
 	cmd.Flags().Bool(optionNameP2PQUICEnable, false, "enable P2P QUIC transport")
+	cmd.Flags().Bool(optionNameP2PMDNSEnable, false, "enable local network peer discovery over mDNS")
 	cmd.Flags().StringSlice(optionNameBootnodes, []string{"/dnsaddr/bootnode.ethswarm.org"}, "initial nodes to connect to")
 	cmd.Flags().Bool(optionNameDebugAPIEnable, false, "enable debug HTTP API")
 	cmd.Flags().String(optionNameDebugAPIAddr, ":6060", "debug HTTP API listen address")
@@ -200,7 +256,45 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 	cmd.Flags().String(optionWelcomeMessage, "", "send a welcome message string during handshakes")
 	cmd.Flags().Uint64(optionNamePaymentThreshold, 100000, "threshold in BZZ where you expect to get paid from your peers")
 	cmd.Flags().Uint64(optionNamePaymentTolerance, 10000, "excess debt above payment threshold in BZZ where you disconnect from your peer")
+	cmd.Flags().Int(optionNamePushMaxAttempts, 16, "number of attempts to push a chunk to its neighbourhood before it is marked as failed")
+	cmd.Flags().Uint64(optionNameBandwidthUpload, 0, "upload bandwidth limit of all protocols in bytes per second, 0 is unlimited")
+	cmd.Flags().Uint64(optionNameBandwidthDownload, 0, "download bandwidth limit of all protocols in bytes per second, 0 is unlimited")
+	cmd.Flags().StringSlice(optionNameBandwidthWeights, []string{"pullsync=0.5"}, "protocol bandwidth weights in the (0, 1] range, as protocol=weight pairs, lower weights back off first")
+	cmd.Flags().Bool(optionNameLightNode, false, "run a light node that does not store chunks for the network and routes uploads and retrievals through full nodes")
+	cmd.Flags().Int(optionNameP2PMaxInbound, 150, "maximal number of inbound peer connections, 0 is unlimited")
+	cmd.Flags().Int(optionNameP2PMaxOutbound, 50, "maximal number of outbound peer connections, 0 is unlimited")
+	cmd.Flags().Int(optionNameP2PReservedSlots, 10, "number of connection slots in each direction reserved for bootnodes, pinned and neighbourhood peers")
+	cmd.Flags().StringSlice(optionNameP2PPinnedPeers, nil, "overlay addresses of peers that can always use the reserved connection slots")
 
 	c.root.AddCommand(cmd)
 	return nil
 }
+
+// parseBandwidthWeights parses protocol=weight pairs.
+func parseBandwidthWeights(pairs []string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(pairs))
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid bandwidth weight %q", pair)
+		}
+		w, err := strconv.ParseFloat(pair[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bandwidth weight %q: %w", pair, err)
+		}
+		weights[pair[:i]] = w
+	}
+	return weights, nil
+}
+
+func parsePinnedPeers(overlays []string) ([]swarm.Address, error) {
+	peers := make([]swarm.Address, 0, len(overlays))
+	for _, o := range overlays {
+		a, err := swarm.ParseHexAddress(o)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pinned peer %q: %w", o, err)
+		}
+		peers = append(peers, a)
+	}
+	return peers, nil
+}
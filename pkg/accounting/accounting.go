@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
@@ -68,6 +69,10 @@ type Accounting struct {
 	metrics           metrics
 }
 
+// blocklistDuration is how long a peer that exceeded the disconnect threshold
+// is blocked from reconnecting.
+var blocklistDuration = time.Hour
+
 var (
 	// ErrOverdraft is the error returned if the expected debt in Reserve would exceed the payment thresholds
 	ErrOverdraft = errors.New("attempted overdraft")
@@ -253,7 +258,7 @@ func (a *Accounting) Debit(peer swarm.Address, price uint64) error {
 	if nextBalance >= int64(a.paymentThreshold+a.paymentTolerance) {
 		// peer too much in debt
 		a.metrics.AccountingDisconnectsCount.Inc()
-		return p2p.NewDisconnectError(ErrDisconnectThresholdExceeded)
+		return p2p.NewBlockPeerError(blocklistDuration, ErrDisconnectThresholdExceeded)
 	}
 
 	return nil
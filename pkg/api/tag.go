@@ -32,6 +32,7 @@ type tagResponse struct {
 	Stored    int64         `json:"stored"`
 	Sent      int64         `json:"sent"`
 	Synced    int64         `json:"synced"`
+	Failed    int64         `json:"failed"`
 	Uid       uint32        `json:"uid"`
 	Anonymous bool          `json:"anonymous"`
 	Name      string        `json:"name"`
@@ -47,6 +48,7 @@ func newTagResponse(tag *tags.Tag) tagResponse {
 		Stored:    tag.Stored,
 		Sent:      tag.Sent,
 		Synced:    tag.Synced,
+		Failed:    tag.Failed,
 		Uid:       tag.Uid,
 		Anonymous: tag.Anonymous,
 		Name:      tag.Name,
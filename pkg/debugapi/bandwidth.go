@@ -0,0 +1,64 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/p2p"
+)
+
+const bandwidthMaxRequestSize = 4096
+
+type bandwidthLimits struct {
+	Upload   uint64             `json:"upload"`
+	Download uint64             `json:"download"`
+	Weights  map[string]float64 `json:"weights"`
+}
+
+func (s *server) getBandwidthHandler(w http.ResponseWriter, r *http.Request) {
+	limits := s.P2P.GetBandwidthLimits()
+
+	weights := limits.Weights
+	if weights == nil {
+		weights = make(map[string]float64)
+	}
+
+	jsonhttp.OK(w, bandwidthLimits{
+		Upload:   limits.Upload,
+		Download: limits.Download,
+		Weights:  weights,
+	})
+}
+
+func (s *server) setBandwidthHandler(w http.ResponseWriter, r *http.Request) {
+	var data bandwidthLimits
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		s.Logger.Debugf("debug api: bandwidth: failed to read request: %v", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	if err := s.P2P.SetBandwidthLimits(p2p.BandwidthLimits{
+		Upload:   data.Upload,
+		Download: data.Download,
+		Weights:  data.Weights,
+	}); err != nil {
+		if errors.Is(err, p2p.ErrInvalidBandwidthWeight) {
+			s.Logger.Debugf("debug api: bandwidth: %v", err)
+			jsonhttp.BadRequest(w, err)
+			return
+		}
+		s.Logger.Debugf("debug api: bandwidth: failed to set: %v", err)
+		s.Logger.Error("debug api: bandwidth: failed to set")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/mock"
+)
+
+func TestGetBandwidth(t *testing.T) {
+	srv := newTestServer(t, testServerOptions{
+		P2P: mock.New(mock.WithBandwidthLimits(p2p.BandwidthLimits{
+			Upload:   1000,
+			Download: 2000,
+			Weights:  map[string]float64{"pullsync": 0.5},
+		})),
+	})
+
+	jsonhttptest.Request(t, srv.Client, http.MethodGet, "/bandwidth", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.BandwidthLimits{
+			Upload:   1000,
+			Download: 2000,
+			Weights:  map[string]float64{"pullsync": 0.5},
+		}),
+	)
+}
+
+func TestSetBandwidth(t *testing.T) {
+	var got p2p.BandwidthLimits
+	srv := newTestServer(t, testServerOptions{
+		P2P: mock.New(mock.WithSetBandwidthLimitsFunc(func(limits p2p.BandwidthLimits) error {
+			for _, w := range limits.Weights {
+				if w <= 0 || w > 1 {
+					return p2p.ErrInvalidBandwidthWeight
+				}
+			}
+			got = limits
+			return nil
+		})),
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		want := p2p.BandwidthLimits{
+			Upload:   100,
+			Download: 200,
+			Weights:  map[string]float64{"pullsync": 0.25},
+		}
+
+		jsonhttptest.Request(t, srv.Client, http.MethodPut, "/bandwidth", http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(debugapi.BandwidthLimits{
+				Upload:   want.Upload,
+				Download: want.Download,
+				Weights:  want.Weights,
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusOK),
+				Code:    http.StatusOK,
+			}),
+		)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got limits %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("invalid weight", func(t *testing.T) {
+		jsonhttptest.Request(t, srv.Client, http.MethodPut, "/bandwidth", http.StatusBadRequest,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte(`{"weights":{"pullsync":2}}`))),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: p2p.ErrInvalidBandwidthWeight.Error(),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		jsonhttptest.Request(t, srv.Client, http.MethodPut, "/bandwidth", http.StatusBadRequest,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("invalid"))),
+		)
+	})
+}
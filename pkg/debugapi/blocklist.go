@@ -0,0 +1,101 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type blockedPeerResponse struct {
+	Address   swarm.Address `json:"address"`
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  string        `json:"duration"`
+}
+
+type blockedPeersResponse struct {
+	Peers []blockedPeerResponse `json:"peers"`
+}
+
+func (s *server) blocklistedPeersHandler(w http.ResponseWriter, r *http.Request) {
+	peers, err := s.P2P.BlocklistedPeers()
+	if err != nil {
+		s.Logger.Debugf("debug api: blocklisted peers: %v", err)
+		s.Logger.Error("debug api: can not get blocklisted peers")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	resp := blockedPeersResponse{
+		Peers: make([]blockedPeerResponse, 0, len(peers)),
+	}
+	for _, p := range peers {
+		resp.Peers = append(resp.Peers, blockedPeerResponse{
+			Address:   p.Address,
+			Timestamp: p.Timestamp,
+			Duration:  p.Duration.String(),
+		})
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+func (s *server) blocklistPeerHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	swarmAddr, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: blocklist peer: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	var duration time.Duration
+	if d := r.URL.Query().Get("duration"); d != "" {
+		duration, err = time.ParseDuration(d)
+		if err != nil || duration < 0 {
+			s.Logger.Debugf("debug api: blocklist peer %s: parse duration %s: %v", addr, d, err)
+			jsonhttp.BadRequest(w, "invalid duration")
+			return
+		}
+	}
+
+	if err := s.P2P.Blocklist(swarmAddr, duration); err != nil {
+		s.Logger.Debugf("debug api: blocklist peer %s: %v", addr, err)
+		s.Logger.Errorf("unable to blocklist peer %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+func (s *server) removeFromBlocklistHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	swarmAddr, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: remove from blocklist: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	if err := s.P2P.RemoveFromBlocklist(swarmAddr); err != nil {
+		s.Logger.Debugf("debug api: remove from blocklist %s: %v", addr, err)
+		if errors.Is(err, p2p.ErrPeerNotFound) {
+			jsonhttp.NotFound(w, "peer not found")
+			return
+		}
+		s.Logger.Errorf("unable to remove peer %s from blocklist", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
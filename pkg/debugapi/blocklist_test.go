@@ -0,0 +1,128 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestBlocklist(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	timestamp := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		blocked         = make(map[string]time.Duration)
+		blocklistedPeer = p2p.BlockedPeer{
+			Address:   overlay,
+			Timestamp: timestamp,
+			Duration:  time.Hour,
+		}
+	)
+
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(
+			mock.WithBlocklistFunc(func(addr swarm.Address, d time.Duration) error {
+				blocked[addr.String()] = d
+				return nil
+			}),
+			mock.WithBlocklistedPeersFunc(func() ([]p2p.BlockedPeer, error) {
+				return []p2p.BlockedPeer{blocklistedPeer}, nil
+			}),
+			mock.WithRemoveFromBlocklistFunc(func(addr swarm.Address) error {
+				if _, ok := blocked[addr.String()]; !ok {
+					return p2p.ErrPeerNotFound
+				}
+				delete(blocked, addr.String())
+				return nil
+			}),
+		),
+	})
+
+	t.Run("list", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/blocklist", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.BlockedPeersResponse{
+				Peers: []debugapi.BlockedPeerResponse{
+					{
+						Address:   overlay,
+						Timestamp: timestamp,
+						Duration:  time.Hour.String(),
+					},
+				},
+			}),
+		)
+	})
+
+	t.Run("add permanent", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusOK,
+				Message: http.StatusText(http.StatusOK),
+			}),
+		)
+		if d, ok := blocked[overlay.String()]; !ok || d != 0 {
+			t.Fatalf("got blocked %v for %v, want permanent block", ok, d)
+		}
+	})
+
+	t.Run("add with duration", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String()+"?duration=10m", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusOK,
+				Message: http.StatusText(http.StatusOK),
+			}),
+		)
+		if d := blocked[overlay.String()]; d != 10*time.Minute {
+			t.Fatalf("got duration %v, want %v", d, 10*time.Minute)
+		}
+	})
+
+	t.Run("add invalid duration", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/"+overlay.String()+"?duration=forever", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid duration",
+			}),
+		)
+	})
+
+	t.Run("add invalid address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/blocklist/invalid-address", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid peer address",
+			}),
+		)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/blocklist/"+overlay.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusOK,
+				Message: http.StatusText(http.StatusOK),
+			}),
+		)
+		if _, ok := blocked[overlay.String()]; ok {
+			t.Fatal("peer not removed from blocklist")
+		}
+	})
+
+	t.Run("remove not found", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/blocklist/"+overlay.String(), http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "peer not found",
+			}),
+		)
+	})
+}
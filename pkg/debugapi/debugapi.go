@@ -8,9 +8,13 @@ import (
 	"net/http"
 
 	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/puller"
+	"github.com/ethersphere/bee/pkg/pusher"
+	"github.com/ethersphere/bee/pkg/reputation"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -34,12 +38,16 @@ type server struct {
 	Tracer         *tracing.Tracer
 	Tags           *tags.Tags
 	Accounting     accounting.Interface
+	Pusher         pusher.Interface
+	Puller         puller.Interface
+	Reputation     reputation.Interface
+	Addressbook    addressbook.Getter
 	http.Handler
 
 	metricsRegistry *prometheus.Registry
 }
 
-func New(overlay swarm.Address, p2p p2p.DebugService, pingpong pingpong.Interface, topologyDriver topology.PeerAdder, storer storage.Storer, logger logging.Logger, tracer *tracing.Tracer, tags *tags.Tags, accounting accounting.Interface) Service {
+func New(overlay swarm.Address, p2p p2p.DebugService, pingpong pingpong.Interface, topologyDriver topology.PeerAdder, storer storage.Storer, logger logging.Logger, tracer *tracing.Tracer, tags *tags.Tags, accounting accounting.Interface, pusher pusher.Interface, puller puller.Interface, reputation reputation.Interface, addressbook addressbook.Getter) Service {
 	s := &server{
 		Overlay:         overlay,
 		P2P:             p2p,
@@ -50,6 +58,10 @@ func New(overlay swarm.Address, p2p p2p.DebugService, pingpong pingpong.Interfac
 		Tracer:          tracer,
 		Tags:            tags,
 		Accounting:      accounting,
+		Pusher:          pusher,
+		Puller:          puller,
+		Reputation:      reputation,
+		Addressbook:     addressbook,
 		metricsRegistry: newMetricsRegistry(),
 	}
 
@@ -12,11 +12,15 @@ import (
 	"testing"
 
 	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/logging"
 	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	pullermock "github.com/ethersphere/bee/pkg/puller/mock"
+	pushermock "github.com/ethersphere/bee/pkg/pusher/mock"
+	reputationmock "github.com/ethersphere/bee/pkg/reputation/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -33,6 +37,10 @@ type testServerOptions struct {
 	TopologyOpts   []topologymock.Option
 	Tags           *tags.Tags
 	AccountingOpts []accountingmock.Option
+	PusherOpts     []pushermock.Option
+	PullerOpts     []pullermock.Option
+	ReputationOpts []reputationmock.Option
+	Addressbook    addressbook.Getter
 }
 
 type testServer struct {
@@ -43,8 +51,11 @@ type testServer struct {
 func newTestServer(t *testing.T, o testServerOptions) *testServer {
 	topologyDriver := topologymock.NewTopologyDriver(o.TopologyOpts...)
 	acc := accountingmock.NewAccounting(o.AccountingOpts...)
+	pusher := pushermock.NewService(o.PusherOpts...)
+	puller := pullermock.NewService(o.PullerOpts...)
+	rep := reputationmock.NewReputation(o.ReputationOpts...)
 
-	s := debugapi.New(o.Overlay, o.P2P, o.Pingpong, topologyDriver, o.Storer, logging.New(ioutil.Discard, 0), nil, o.Tags, acc)
+	s := debugapi.New(o.Overlay, o.P2P, o.Pingpong, topologyDriver, o.Storer, logging.New(ioutil.Discard, 0), nil, o.Tags, acc, pusher, puller, rep, o.Addressbook)
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
 
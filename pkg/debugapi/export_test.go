@@ -8,7 +8,13 @@ type (
 	StatusResponse           = statusResponse
 	PingpongResponse         = pingpongResponse
 	PeerConnectResponse      = peerConnectResponse
+	PeerResponse             = peerResponse
 	PeersResponse            = peersResponse
+	PeerInfoResponse         = peerInfoResponse
+	TrafficResponse          = trafficResponse
+	PeerTrafficResponse      = peerTrafficResponse
+	ProtocolTrafficResponse  = protocolTrafficResponse
+	PeerProtocolResponse     = peerProtocolResponse
 	AddressesResponse        = addressesResponse
 	PinnedChunk              = pinnedChunk
 	ListPinnedChunksResponse = listPinnedChunksResponse
@@ -16,6 +22,22 @@ type (
 	WelcomeMessageResponse   = welcomeMessageResponse
 	BalancesResponse         = balancesResponse
 	BalanceResponse          = balanceResponse
+	PushAttemptResponse      = pushAttemptResponse
+	PushAttemptsResponse     = pushAttemptsResponse
+	PushReceiptResponse      = pushReceiptResponse
+	PullerBinResponse        = pullerBinResponse
+	PullerPeerResponse       = pullerPeerResponse
+	PullerStatusResponse     = pullerStatusResponse
+	BandwidthLimits          = bandwidthLimits
+	BlockedPeerResponse      = blockedPeerResponse
+	BlockedPeersResponse     = blockedPeersResponse
+	ConnectionSlotsResponse  = connectionSlotsResponse
+	SlotsResponse            = slotsResponse
+	StorageUsageResponse     = storageUsageResponse
+	ScrubStatusResponse      = scrubStatusResponse
+	GCStatusResponse         = gcStatusResponse
+	StorageCapacityRequest   = storageCapacityRequest
+	StorageImportResponse    = storageImportResponse
 )
 
 var (
@@ -7,7 +7,9 @@ package debugapi
 import (
 	"errors"
 	"net/http"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -63,12 +65,115 @@ func (s *server) peerDisconnectHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, nil)
 }
 
+type peerResponse struct {
+	Address swarm.Address `json:"address"`
+	Score   float64       `json:"score"`
+	Latency string        `json:"latency,omitempty"`
+}
+
 type peersResponse struct {
-	Peers []p2p.Peer `json:"peers"`
+	Peers []peerResponse `json:"peers"`
 }
 
 func (s *server) peersHandler(w http.ResponseWriter, r *http.Request) {
-	jsonhttp.OK(w, peersResponse{
-		Peers: s.P2P.Peers(),
+	peers := s.P2P.Peers()
+
+	resp := peersResponse{
+		Peers: make([]peerResponse, 0, len(peers)),
+	}
+	for _, p := range peers {
+		peer := peerResponse{
+			Address: p.Address,
+			Score:   s.Reputation.Score(p.Address),
+		}
+		if l, ok := s.Pingpong.Latency(p.Address); ok {
+			peer.Latency = l.String()
+		}
+		resp.Peers = append(resp.Peers, peer)
+	}
+
+	jsonhttp.OK(w, resp)
+}
+
+type peerProtocolResponse struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type peerInfoResponse struct {
+	Address        swarm.Address          `json:"address"`
+	Underlays      []string               `json:"underlays"`
+	Direction      p2p.Direction          `json:"direction"`
+	ConnectedAt    time.Time              `json:"connectedAt"`
+	Duration       string                 `json:"duration"`
+	Bin            uint8                  `json:"bin"`
+	Light          bool                   `json:"light"`
+	WelcomeMessage string                 `json:"welcomeMessage"`
+	Balance        int64                  `json:"balance"`
+	Protocols      []peerProtocolResponse `json:"protocols"`
+}
+
+func (s *server) peerInfoHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	swarmAddr, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	info, err := s.P2P.PeerInfo(swarmAddr)
+	if err != nil {
+		s.Logger.Debugf("debug api: peer info %s: %v", addr, err)
+		if errors.Is(err, p2p.ErrPeerNotFound) {
+			jsonhttp.NotFound(w, "peer not found")
+			return
+		}
+		s.Logger.Errorf("unable to get peer %s info", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	underlays := make([]string, 0)
+	bzzAddr, err := s.Addressbook.Get(swarmAddr)
+	switch {
+	case err == nil:
+		underlays = append(underlays, bzzAddr.Underlay.String())
+	case errors.Is(err, addressbook.ErrNotFound):
+		// light peers are not kept in the addressbook
+	default:
+		s.Logger.Debugf("debug api: peer info %s: addressbook: %v", addr, err)
+		s.Logger.Errorf("unable to get peer %s info", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	balance, err := s.Accounting.Balance(swarmAddr)
+	if err != nil {
+		s.Logger.Debugf("debug api: peer info %s: balance: %v", addr, err)
+		s.Logger.Errorf("unable to get peer %s info", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	protocols := make([]peerProtocolResponse, 0, len(info.Protocols))
+	for _, p := range info.Protocols {
+		protocols = append(protocols, peerProtocolResponse{
+			Name:    p.Name,
+			Version: p.Version,
+		})
+	}
+
+	jsonhttp.OK(w, peerInfoResponse{
+		Address:        info.Address,
+		Underlays:      underlays,
+		Direction:      info.Direction,
+		ConnectedAt:    info.ConnectedAt,
+		Duration:       time.Since(info.ConnectedAt).Round(time.Second).String(),
+		Bin:            swarm.Proximity(s.Overlay.Bytes(), info.Address.Bytes()),
+		Light:          info.Light,
+		WelcomeMessage: info.WelcomeMessage,
+		Balance:        balance,
+		Protocols:      protocols,
 	})
 }
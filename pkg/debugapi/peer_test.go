@@ -8,8 +8,12 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"reflect"
 	"testing"
+	"time"
 
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/bzz"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
@@ -17,6 +21,9 @@ import (
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/mock"
+	pingpongmock "github.com/ethersphere/bee/pkg/pingpong/mock"
+	reputationmock "github.com/ethersphere/bee/pkg/reputation/mock"
+	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 	ma "github.com/multiformats/go-multiaddr"
 )
@@ -161,16 +168,20 @@ func TestDisconnect(t *testing.T) {
 
 func TestPeer(t *testing.T) {
 	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	pingpong := pingpongmock.New(nil)
+	pingpong.SetLatency(overlay, 35*time.Millisecond)
 	testServer := newTestServer(t, testServerOptions{
 		P2P: mock.New(mock.WithPeersFunc(func() []p2p.Peer {
 			return []p2p.Peer{{Address: overlay}}
 		})),
+		Pingpong:       pingpong,
+		ReputationOpts: []reputationmock.Option{reputationmock.WithScore(overlay, -12.5)},
 	})
 
 	t.Run("ok", func(t *testing.T) {
 		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers", http.StatusOK,
 			jsonhttptest.WithExpectedJSONResponse(debugapi.PeersResponse{
-				Peers: []p2p.Peer{{Address: overlay}},
+				Peers: []debugapi.PeerResponse{{Address: overlay, Score: -12.5, Latency: "35ms"}},
 			}),
 		)
 	})
@@ -184,3 +195,93 @@ func TestPeer(t *testing.T) {
 		)
 	})
 }
+
+func TestPeerInfo(t *testing.T) {
+	underlay := "/ip4/127.0.0.1/tcp/7070/p2p/16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkS"
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
+
+	privateKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(privateKey.PublicKey, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	underlayMA, err := ma.NewMultiaddr(underlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bzzAddress, err := bzz.NewAddress(crypto.NewDefaultSigner(privateKey), underlayMA, overlay, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab := addressbook.New(mockstate.NewStateStore())
+	if err := ab.Put(overlay, *bzzAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	connectedAt := time.Now().Add(-time.Minute).UTC()
+	testServer := newTestServer(t, testServerOptions{
+		Overlay: base,
+		P2P: mock.New(mock.WithPeerInfoFunc(func(addr swarm.Address) (*p2p.PeerInfo, error) {
+			if !addr.Equal(overlay) {
+				return nil, p2p.ErrPeerNotFound
+			}
+			return &p2p.PeerInfo{
+				Address:        overlay,
+				Direction:      p2p.DirectionInbound,
+				ConnectedAt:    connectedAt,
+				WelcomeMessage: "hello",
+				Protocols:      []p2p.ProtocolVersion{{Name: "pingpong", Version: "1.0.0"}},
+			}, nil
+		})),
+		AccountingOpts: []accountingmock.Option{accountingmock.WithBalanceFunc(func(swarm.Address) (int64, error) {
+			return 42, nil
+		})},
+		Addressbook: ab,
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var got debugapi.PeerInfoResponse
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/"+overlay.String(), http.StatusOK,
+			jsonhttptest.WithUnmarshalJSONResponse(&got),
+		)
+
+		if got.Duration == "" {
+			t.Error("got empty connection duration")
+		}
+		got.Duration = ""
+		want := debugapi.PeerInfoResponse{
+			Address:        overlay,
+			Underlays:      []string{underlay},
+			Direction:      p2p.DirectionInbound,
+			ConnectedAt:    connectedAt,
+			Bin:            swarm.Proximity(base.Bytes(), overlay.Bytes()),
+			WelcomeMessage: "hello",
+			Balance:        42,
+			Protocols:      []debugapi.PeerProtocolResponse{{Name: "pingpong", Version: "1.0.0"}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got peer info %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/"+base.String(), http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusNotFound,
+				Message: "peer not found",
+			}),
+		)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/peers/invalid-address", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid peer address",
+			}),
+		)
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/puller"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type pullerBinResponse struct {
+	Bin                 uint8       `json:"bin"`
+	Cursor              uint64      `json:"cursor"`
+	Intervals           [][2]uint64 `json:"intervals"`
+	HistoricalRemaining uint64      `json:"historicalRemaining"`
+	HistoricalSyncing   bool        `json:"historicalSyncing"`
+	LiveSyncing         bool        `json:"liveSyncing"`
+}
+
+type pullerPeerResponse struct {
+	Address        swarm.Address       `json:"address"`
+	PO             uint8               `json:"po"`
+	SyncPercentage float64             `json:"syncPercentage"`
+	Bins           []pullerBinResponse `json:"bins"`
+}
+
+type pullerStatusResponse struct {
+	SyncPercentage float64              `json:"syncPercentage"`
+	Peers          []pullerPeerResponse `json:"peers"`
+}
+
+// pullerStatusHandler returns the pull sync state of all peers that the node
+// is syncing with.
+func (s *server) pullerStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := s.Puller.SyncStatus()
+	if err != nil {
+		s.Logger.Debugf("debug api: puller status: %v", err)
+		s.Logger.Error("debug api: puller status")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	peers := make([]pullerPeerResponse, 0, len(status.Peers))
+	for _, ps := range status.Peers {
+		peers = append(peers, newPullerPeerResponse(ps))
+	}
+
+	jsonhttp.OK(w, pullerStatusResponse{
+		SyncPercentage: status.SyncPercentage,
+		Peers:          peers,
+	})
+}
+
+// pullerPeerStatusHandler returns the pull sync state of a single peer.
+func (s *server) pullerPeerStatusHandler(w http.ResponseWriter, r *http.Request) {
+	peer, err := swarm.ParseHexAddress(mux.Vars(r)["peer"])
+	if err != nil {
+		s.Logger.Debugf("debug api: puller peer status: parse peer address: %v", err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	status, err := s.Puller.PeerSyncStatus(peer)
+	if err != nil {
+		if errors.Is(err, puller.ErrPeerNotSyncing) {
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		s.Logger.Debugf("debug api: puller peer status: peer %s: %v", peer, err)
+		s.Logger.Errorf("debug api: puller peer status: peer %s", peer)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, newPullerPeerResponse(*status))
+}
+
+func newPullerPeerResponse(ps puller.PeerStatus) pullerPeerResponse {
+	bins := make([]pullerBinResponse, 0, len(ps.Bins))
+	for _, b := range ps.Bins {
+		intervals := b.Intervals
+		if intervals == nil {
+			intervals = make([][2]uint64, 0)
+		}
+		bins = append(bins, pullerBinResponse{
+			Bin:                 b.Bin,
+			Cursor:              b.Cursor,
+			Intervals:           intervals,
+			HistoricalRemaining: b.HistoricalRemaining,
+			HistoricalSyncing:   b.HistoricalSyncing,
+			LiveSyncing:         b.LiveSyncing,
+		})
+	}
+	return pullerPeerResponse{
+		Address:        ps.Address,
+		PO:             ps.PO,
+		SyncPercentage: ps.SyncPercentage,
+		Bins:           bins,
+	}
+}
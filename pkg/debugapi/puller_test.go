@@ -0,0 +1,100 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/puller"
+	"github.com/ethersphere/bee/pkg/puller/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestPullerStatus(t *testing.T) {
+	peer := swarm.MustParseHexAddress("aabbcc")
+
+	testServer := newTestServer(t, testServerOptions{
+		PullerOpts: []mock.Option{mock.WithStatus(&puller.Status{
+			SyncPercentage: 50,
+			Peers: []puller.PeerStatus{
+				{
+					Address:        peer,
+					PO:             2,
+					SyncPercentage: 50,
+					Bins: []puller.BinStatus{
+						{
+							Bin:                 2,
+							Cursor:              10,
+							Intervals:           [][2]uint64{{1, 5}},
+							HistoricalRemaining: 5,
+							HistoricalSyncing:   true,
+							LiveSyncing:         true,
+						},
+						{
+							Bin: 3,
+						},
+					},
+				},
+			},
+		})},
+	})
+
+	expectedPeer := debugapi.PullerPeerResponse{
+		Address:        peer,
+		PO:             2,
+		SyncPercentage: 50,
+		Bins: []debugapi.PullerBinResponse{
+			{
+				Bin:                 2,
+				Cursor:              10,
+				Intervals:           [][2]uint64{{1, 5}},
+				HistoricalRemaining: 5,
+				HistoricalSyncing:   true,
+				LiveSyncing:         true,
+			},
+			{
+				Bin:       3,
+				Intervals: [][2]uint64{},
+			},
+		},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/puller", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.PullerStatusResponse{
+				SyncPercentage: 50,
+				Peers:          []debugapi.PullerPeerResponse{expectedPeer},
+			}),
+		)
+	})
+
+	t.Run("peer", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/puller/"+peer.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(expectedPeer),
+		)
+	})
+
+	t.Run("peer not syncing", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/puller/ddeeff", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusNotFound),
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("bad address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/puller/invalid", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad address",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/pusher"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type pushAttemptResponse struct {
+	Address     swarm.Address `json:"address"`
+	Attempts    int           `json:"attempts"`
+	LastAttempt time.Time     `json:"lastAttempt"`
+	NextAttempt time.Time     `json:"nextAttempt"`
+	Failed      bool          `json:"failed"`
+	Reason      string        `json:"reason"`
+}
+
+type pushAttemptsResponse struct {
+	Chunks []pushAttemptResponse `json:"chunks"`
+}
+
+// pusherChunksHandler lists chunks that are backing off after failed push
+// attempts or have been marked as failed.
+func (s *server) pusherChunksHandler(w http.ResponseWriter, r *http.Request) {
+	attempts := s.Pusher.Attempts()
+
+	chunks := make([]pushAttemptResponse, 0, len(attempts))
+	for _, a := range attempts {
+		chunks = append(chunks, pushAttemptResponse{
+			Address:     a.Address,
+			Attempts:    a.Count,
+			LastAttempt: a.LastAttempt,
+			NextAttempt: a.NextAttempt,
+			Failed:      a.Failed,
+			Reason:      a.Reason,
+		})
+	}
+
+	jsonhttp.OK(w, pushAttemptsResponse{Chunks: chunks})
+}
+
+// pusherRetryChunkHandler resets the push attempts of a chunk so that it is
+// pushed again.
+func (s *server) pusherRetryChunkHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: pusher retry: parse chunk address: %v", err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	if err := s.Pusher.Retry(addr); err != nil {
+		if errors.Is(err, pusher.ErrChunkNotTracked) {
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		s.Logger.Debugf("debug api: pusher retry: chunk %s: %v", addr, err)
+		s.Logger.Errorf("debug api: pusher retry: chunk %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+type pushReceiptResponse struct {
+	Address   swarm.Address `json:"address"`
+	Storer    swarm.Address `json:"storer"`
+	Signature string        `json:"signature"`
+}
+
+// pusherReceiptHandler returns the verified push-sync receipt of a chunk,
+// recording the node that stored it.
+func (s *server) pusherReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: pusher receipt: parse chunk address: %v", err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	receipt, err := s.Pusher.Receipt(addr)
+	if err != nil {
+		if errors.Is(err, pusher.ErrReceiptNotFound) {
+			jsonhttp.NotFound(w, nil)
+			return
+		}
+		s.Logger.Debugf("debug api: pusher receipt: chunk %s: %v", addr, err)
+		s.Logger.Errorf("debug api: pusher receipt: chunk %s", addr)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, pushReceiptResponse{
+		Address:   receipt.Address,
+		Storer:    receipt.Storer,
+		Signature: hex.EncodeToString(receipt.Signature),
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/pusher"
+	"github.com/ethersphere/bee/pkg/pusher/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestPusherChunks(t *testing.T) {
+	addr := swarm.MustParseHexAddress("aabbcc")
+	last := time.Unix(1600000000, 0).UTC()
+
+	testServer := newTestServer(t, testServerOptions{
+		PusherOpts: []mock.Option{mock.WithAttempts(pusher.Attempt{
+			Address:     addr,
+			Count:       3,
+			LastAttempt: last,
+			Failed:      true,
+			Reason:      "invalid receipt",
+		})},
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/pusher/chunks", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.PushAttemptsResponse{
+			Chunks: []debugapi.PushAttemptResponse{
+				{
+					Address:     addr,
+					Attempts:    3,
+					LastAttempt: last,
+					Failed:      true,
+					Reason:      "invalid receipt",
+				},
+			},
+		}),
+	)
+}
+
+func TestPusherRetryChunk(t *testing.T) {
+	tracked := swarm.MustParseHexAddress("aabbcc")
+
+	testServer := newTestServer(t, testServerOptions{
+		PusherOpts: []mock.Option{mock.WithRetryFunc(func(addr swarm.Address) error {
+			if !addr.Equal(tracked) {
+				return pusher.ErrChunkNotTracked
+			}
+			return nil
+		})},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/pusher/chunks/"+tracked.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusOK),
+				Code:    http.StatusOK,
+			}),
+		)
+	})
+
+	t.Run("not tracked", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/pusher/chunks/ddeeff", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusNotFound),
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("bad address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/pusher/chunks/invalid", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad address",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
+
+func TestPusherReceipt(t *testing.T) {
+	addr := swarm.MustParseHexAddress("aabbcc")
+	storer := swarm.MustParseHexAddress("ddeeff")
+
+	testServer := newTestServer(t, testServerOptions{
+		PusherOpts: []mock.Option{mock.WithReceipts(pusher.Receipt{
+			Address:   addr,
+			Storer:    storer,
+			Signature: []byte{1, 2, 3},
+		})},
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/pusher/receipts/"+addr.String(), http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.PushReceiptResponse{
+				Address:   addr,
+				Storer:    storer,
+				Signature: "010203",
+			}),
+		)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/pusher/receipts/ddeeff", http.StatusNotFound,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusNotFound),
+				Code:    http.StatusNotFound,
+			}),
+		)
+	})
+
+	t.Run("bad address", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/pusher/receipts/invalid", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "bad address",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
@@ -64,8 +64,16 @@ func (s *server) setupRouting() {
 		"GET": http.HandlerFunc(s.peersHandler),
 	})
 	router.Handle("/peers/{address}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.peerInfoHandler),
 		"DELETE": http.HandlerFunc(s.peerDisconnectHandler),
 	})
+	router.Handle("/blocklist", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.blocklistedPeersHandler),
+	})
+	router.Handle("/blocklist/{address}", jsonhttp.MethodHandler{
+		"POST":   http.HandlerFunc(s.blocklistPeerHandler),
+		"DELETE": http.HandlerFunc(s.removeFromBlocklistHandler),
+	})
 	router.Handle("/chunks/{address}", jsonhttp.MethodHandler{
 		"GET":    http.HandlerFunc(s.hasChunkHandler),
 		"DELETE": http.HandlerFunc(s.removeChunk),
@@ -78,6 +86,32 @@ func (s *server) setupRouting() {
 	router.Handle("/chunks-pin", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.listPinnedChunks),
 	})
+	router.Handle("/storage", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.storageUsageHandler),
+	})
+	router.Handle("/storage/scrub", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.storageScrubHandler),
+	})
+	router.Handle("/storage/gc", jsonhttp.MethodHandler{
+		"GET":  http.HandlerFunc(s.storageGCHandler),
+		"POST": http.HandlerFunc(s.storageGCTriggerHandler),
+	})
+	router.Handle("/storage/gc/pause", jsonhttp.MethodHandler{
+		"POST":   http.HandlerFunc(s.storageGCPauseHandler),
+		"DELETE": http.HandlerFunc(s.storageGCResumeHandler),
+	})
+	router.Handle("/storage/capacity", jsonhttp.MethodHandler{
+		"PUT": web.ChainHandlers(
+			jsonhttp.NewMaxBodyBytesHandler(storageCapacityMaxRequestSize),
+			web.FinalHandlerFunc(s.storageCapacityHandler),
+		),
+	})
+	router.Handle("/storage/export", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.storageExportHandler),
+	})
+	router.Handle("/storage/import", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.storageImportHandler),
+	})
 	router.Handle("/topology", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.topologyHandler),
 	})
@@ -88,6 +122,37 @@ func (s *server) setupRouting() {
 			web.FinalHandlerFunc(s.setWelcomeMessageHandler),
 		),
 	})
+	// pusher is not running on nodes with read-only db
+	if s.Pusher != nil {
+		router.Handle("/pusher/chunks", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.pusherChunksHandler),
+		})
+		router.Handle("/pusher/chunks/{address}", jsonhttp.MethodHandler{
+			"POST": http.HandlerFunc(s.pusherRetryChunkHandler),
+		})
+		router.Handle("/pusher/receipts/{address}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.pusherReceiptHandler),
+		})
+	}
+	router.Handle("/bandwidth", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.getBandwidthHandler),
+		"PUT": web.ChainHandlers(
+			jsonhttp.NewMaxBodyBytesHandler(bandwidthMaxRequestSize),
+			web.FinalHandlerFunc(s.setBandwidthHandler),
+		),
+	})
+	router.Handle("/traffic", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.trafficHandler),
+	})
+	// puller is not running on light nodes
+	if s.Puller != nil {
+		router.Handle("/puller", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.pullerStatusHandler),
+		})
+		router.Handle("/puller/{peer}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.pullerPeerStatusHandler),
+		})
+	}
 	router.Handle("/balances", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.balancesHandler),
 	})
@@ -0,0 +1,287 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type storageUsageResponse struct {
+	Size            uint64 `json:"size"`
+	Capacity        uint64 `json:"capacity"`
+	Chunks          uint64 `json:"chunks"`
+	ChunkCapacity   uint64 `json:"chunkCapacity"`
+	Reserve         uint64 `json:"reserve"`
+	ReserveCapacity uint64 `json:"reserveCapacity"`
+	StorageRadius   uint8  `json:"storageRadius"`
+}
+
+// storageUsageHandler returns the size of the local store on disk and the
+// number of garbage collectable and reserve chunks against the configured
+// capacities, together with the storage radius of the reserve.
+func (s *server) storageUsageHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := s.Storer.Usage()
+	if err != nil {
+		s.Logger.Debugf("debug api: storage usage: %v", err)
+		s.Logger.Error("debug api: storage usage")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, storageUsageResponse{
+		Size:            u.Size,
+		Capacity:        u.Capacity,
+		Chunks:          u.Chunks,
+		ChunkCapacity:   u.ChunkCapacity,
+		Reserve:         u.Reserve,
+		ReserveCapacity: u.ReserveCapacity,
+		StorageRadius:   u.StorageRadius,
+	})
+}
+
+type scrubStatusResponse struct {
+	Enabled       bool      `json:"enabled"`
+	Running       bool      `json:"running"`
+	Passes        uint64    `json:"passes"`
+	Checked       uint64    `json:"checked"`
+	Corrupt       uint64    `json:"corrupt"`
+	Removed       uint64    `json:"removed"`
+	Repaired      uint64    `json:"repaired"`
+	Quarantined   uint64    `json:"quarantined"`
+	LastPassStart time.Time `json:"lastPassStart"`
+	LastPassEnd   time.Time `json:"lastPassEnd"`
+}
+
+// storageScrubHandler returns the progress and findings of the background
+// validation of chunks in the local store.
+func (s *server) storageScrubHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := s.Storer.ScrubStatus()
+	if err != nil {
+		s.Logger.Debugf("debug api: storage scrub: %v", err)
+		s.Logger.Error("debug api: storage scrub")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, scrubStatusResponse{
+		Enabled:       status.Enabled,
+		Running:       status.Running,
+		Passes:        status.Passes,
+		Checked:       status.Checked,
+		Corrupt:       status.Corrupt,
+		Removed:       status.Removed,
+		Repaired:      status.Repaired,
+		Quarantined:   status.Quarantined,
+		LastPassStart: status.LastPassStart,
+		LastPassEnd:   status.LastPassEnd,
+	})
+}
+
+type gcStatusResponse struct {
+	Paused             bool        `json:"paused"`
+	Running            bool        `json:"running"`
+	Size               uint64      `json:"size"`
+	Capacity           uint64      `json:"capacity"`
+	Target             uint64      `json:"target"`
+	Excluded           uint64      `json:"excluded"`
+	NextEvictionAccess []time.Time `json:"nextEvictionAccess"`
+	Runs               uint64      `json:"runs"`
+	LastRunStart       time.Time   `json:"lastRunStart"`
+	LastRunEnd         time.Time   `json:"lastRunEnd"`
+	LastRunCollected   uint64      `json:"lastRunCollected"`
+	LastRunError       string      `json:"lastRunError"`
+}
+
+// storageGCHandler returns the number of garbage collectable chunks in
+// the local store against the capacity and the garbage collection target,
+// and the outcome of the last garbage collection run.
+func (s *server) storageGCHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := s.Storer.GCStatus()
+	if err != nil {
+		s.Logger.Debugf("debug api: storage gc: %v", err)
+		s.Logger.Error("debug api: storage gc")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	nextEvictionAccess := status.NextEvictionAccess
+	if nextEvictionAccess == nil {
+		nextEvictionAccess = make([]time.Time, 0)
+	}
+
+	jsonhttp.OK(w, gcStatusResponse{
+		Paused:             status.Paused,
+		Running:            status.Running,
+		Size:               status.Size,
+		Capacity:           status.Capacity,
+		Target:             status.Target,
+		Excluded:           status.Excluded,
+		NextEvictionAccess: nextEvictionAccess,
+		Runs:               status.Runs,
+		LastRunStart:       status.LastRunStart,
+		LastRunEnd:         status.LastRunEnd,
+		LastRunCollected:   status.LastRunCollected,
+		LastRunError:       status.LastRunError,
+	})
+}
+
+// storageGCTriggerHandler starts a garbage collection run in the
+// background.
+func (s *server) storageGCTriggerHandler(w http.ResponseWriter, r *http.Request) {
+	s.Storer.TriggerGC()
+	jsonhttp.Accepted(w, nil)
+}
+
+// storageGCPauseHandler pauses garbage collection.
+func (s *server) storageGCPauseHandler(w http.ResponseWriter, r *http.Request) {
+	s.Storer.SetGCPaused(true)
+	jsonhttp.OK(w, nil)
+}
+
+// storageGCResumeHandler resumes paused garbage collection.
+func (s *server) storageGCResumeHandler(w http.ResponseWriter, r *http.Request) {
+	s.Storer.SetGCPaused(false)
+	jsonhttp.OK(w, nil)
+}
+
+const storageCapacityMaxRequestSize = 1024
+
+type storageCapacityRequest struct {
+	Capacity uint64 `json:"capacity"`
+}
+
+// storageCapacityHandler changes the capacity of the local store in
+// number of chunks until the node is restarted.
+func (s *server) storageCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	var data storageCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		s.Logger.Debugf("debug api: storage capacity: failed to read request: %v", err)
+		jsonhttp.BadRequest(w, err)
+		return
+	}
+
+	if err := s.Storer.SetCapacity(data.Capacity); err != nil {
+		if errors.Is(err, storage.ErrInvalidCapacity) {
+			s.Logger.Debugf("debug api: storage capacity: %v", err)
+			jsonhttp.BadRequest(w, err)
+			return
+		}
+		s.Logger.Debugf("debug api: storage capacity: failed to set: %v", err)
+		s.Logger.Error("debug api: storage capacity: failed to set")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// importValidator validates content addressed and single owner chunks
+// that are imported to the local store.
+var importValidator = swarm.NewChunkValidator(soc.NewValidator(), content.NewValidator())
+
+// storageExportHandler streams the chunks of the local store in the tar
+// export format. Chunks are filtered by proximity order bins, pinning and
+// the time range in which they are stored.
+func (s *server) storageExportHandler(w http.ResponseWriter, r *http.Request) {
+	var f storage.ExportFilter
+	q := r.URL.Query()
+	for _, v := range q["bin"] {
+		bin, err := strconv.ParseUint(v, 10, 8)
+		if err != nil || bin >= uint64(swarm.MaxBins) {
+			s.Logger.Debugf("debug api: storage export: parse bin %s: %v", v, err)
+			jsonhttp.BadRequest(w, "invalid bin")
+			return
+		}
+		f.Bins = append(f.Bins, uint8(bin))
+	}
+	if v := q.Get("pinned"); v != "" {
+		pinned, err := strconv.ParseBool(v)
+		if err != nil {
+			s.Logger.Debugf("debug api: storage export: parse pinned %s: %v", v, err)
+			jsonhttp.BadRequest(w, "invalid pinned")
+			return
+		}
+		f.Pinned = pinned
+	}
+	for _, t := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{name: "since", value: &f.Since},
+		{name: "until", value: &f.Until},
+	} {
+		v := q.Get(t.name)
+		if v == "" {
+			continue
+		}
+		tm, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.Logger.Debugf("debug api: storage export: parse %s %s: %v", t.name, v, err)
+			jsonhttp.BadRequest(w, "invalid "+t.name)
+			return
+		}
+		*t.value = tm
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	count, err := s.Storer.Export(w, f)
+	if err != nil {
+		// the response status is already written
+		s.Logger.Debugf("debug api: storage export: %v", err)
+		s.Logger.Error("debug api: storage export")
+		return
+	}
+	s.Logger.Debugf("debug api: storage export: exported %d chunks", count)
+}
+
+type storageImportResponse struct {
+	Imported uint64 `json:"imported"`
+	Invalid  uint64 `json:"invalid"`
+}
+
+// storageImportHandler stores the valid chunks from the request body in
+// the tar export format. Imported chunks are push synced if requested.
+func (s *server) storageImportHandler(w http.ResponseWriter, r *http.Request) {
+	var pushSync bool
+	if v := r.URL.Query().Get("push-sync"); v != "" {
+		var err error
+		pushSync, err = strconv.ParseBool(v)
+		if err != nil {
+			s.Logger.Debugf("debug api: storage import: parse push sync %s: %v", v, err)
+			jsonhttp.BadRequest(w, "invalid push-sync")
+			return
+		}
+	}
+
+	result, err := s.Storer.Import(r.Body, storage.ImportOptions{
+		Validator: importValidator,
+		PushSync:  pushSync,
+	})
+	if err != nil {
+		s.Logger.Debugf("debug api: storage import: %v", err)
+		if errors.Is(err, storage.ErrInvalidImport) {
+			jsonhttp.BadRequest(w, "invalid import data")
+			return
+		}
+		s.Logger.Error("debug api: storage import")
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	jsonhttp.OK(w, storageImportResponse{
+		Imported: result.Imported,
+		Invalid:  result.Invalid,
+	})
+}
@@ -0,0 +1,285 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestStorageUsage(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(mock.WithUsage(storage.Usage{
+			Size:            3 << 30,
+			Capacity:        4 << 30,
+			Chunks:          500000,
+			ChunkCapacity:   5000000,
+			Reserve:         1200000,
+			ReserveCapacity: 2500000,
+			StorageRadius:   8,
+		})),
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/storage", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.StorageUsageResponse{
+			Size:            3 << 30,
+			Capacity:        4 << 30,
+			Chunks:          500000,
+			ChunkCapacity:   5000000,
+			Reserve:         1200000,
+			ReserveCapacity: 2500000,
+			StorageRadius:   8,
+		}),
+	)
+}
+
+func TestStorageScrub(t *testing.T) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	testServer := newTestServer(t, testServerOptions{
+		Storer: mock.NewStorer(mock.WithScrubStatus(storage.ScrubStatus{
+			Enabled:       true,
+			Running:       true,
+			Passes:        3,
+			Checked:       12000,
+			Corrupt:       5,
+			Removed:       4,
+			Repaired:      1,
+			LastPassStart: start,
+			LastPassEnd:   start.Add(-time.Hour),
+		})),
+	})
+
+	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/storage/scrub", http.StatusOK,
+		jsonhttptest.WithExpectedJSONResponse(debugapi.ScrubStatusResponse{
+			Enabled:       true,
+			Running:       true,
+			Passes:        3,
+			Checked:       12000,
+			Corrupt:       5,
+			Removed:       4,
+			Repaired:      1,
+			LastPassStart: start,
+			LastPassEnd:   start.Add(-time.Hour),
+		}),
+	)
+}
+
+func TestStorageGC(t *testing.T) {
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	storer := mock.NewStorer(mock.WithGCStatus(storage.GCStatus{
+		Size:               1200,
+		Capacity:           1000,
+		Target:             900,
+		Excluded:           2,
+		NextEvictionAccess: []time.Time{start.Add(-48 * time.Hour), start.Add(-24 * time.Hour)},
+		Runs:               5,
+		LastRunStart:       start,
+		LastRunEnd:         start.Add(time.Minute),
+		LastRunCollected:   100,
+		LastRunError:       "some error",
+	}))
+	testServer := newTestServer(t, testServerOptions{
+		Storer: storer,
+	})
+
+	status := func(t *testing.T, paused bool, runs uint64) {
+		t.Helper()
+
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/storage/gc", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(debugapi.GCStatusResponse{
+				Paused:             paused,
+				Size:               1200,
+				Capacity:           1000,
+				Target:             900,
+				Excluded:           2,
+				NextEvictionAccess: []time.Time{start.Add(-48 * time.Hour), start.Add(-24 * time.Hour)},
+				Runs:               runs,
+				LastRunStart:       start,
+				LastRunEnd:         start.Add(time.Minute),
+				LastRunCollected:   100,
+				LastRunError:       "some error",
+			}),
+		)
+	}
+
+	t.Run("status", func(t *testing.T) {
+		status(t, false, 5)
+	})
+
+	t.Run("trigger", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/storage/gc", http.StatusAccepted,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusAccepted),
+				Code:    http.StatusAccepted,
+			}),
+		)
+		status(t, false, 6)
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/storage/gc/pause", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusOK),
+				Code:    http.StatusOK,
+			}),
+		)
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/storage/gc", http.StatusAccepted)
+		status(t, true, 6)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodDelete, "/storage/gc/pause", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusOK),
+				Code:    http.StatusOK,
+			}),
+		)
+		status(t, false, 6)
+	})
+}
+
+func TestStorageCapacity(t *testing.T) {
+	storer := mock.NewStorer(mock.WithUsage(storage.Usage{
+		ChunkCapacity:   1000,
+		ReserveCapacity: 100,
+	}))
+	testServer := newTestServer(t, testServerOptions{
+		Storer: storer,
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPut, "/storage/capacity", http.StatusOK,
+			jsonhttptest.WithJSONRequestBody(debugapi.StorageCapacityRequest{
+				Capacity: 500,
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: http.StatusText(http.StatusOK),
+				Code:    http.StatusOK,
+			}),
+		)
+
+		u, err := storer.Usage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.ChunkCapacity != 500 {
+			t.Errorf("got chunk capacity %v, want %v", u.ChunkCapacity, 500)
+		}
+	})
+
+	t.Run("invalid capacity", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPut, "/storage/capacity", http.StatusBadRequest,
+			jsonhttptest.WithJSONRequestBody(debugapi.StorageCapacityRequest{
+				Capacity: 100,
+			}),
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: storage.ErrInvalidCapacity.Error(),
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodPut, "/storage/capacity", http.StatusBadRequest,
+			jsonhttptest.WithRequestBody(bytes.NewReader([]byte("invalid"))),
+		)
+	})
+}
+
+func TestStorageExportImport(t *testing.T) {
+	var chunks []swarm.Chunk
+	for i := 0; i < 3; i++ {
+		ch, err := content.NewChunk([]byte{byte(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, ch)
+	}
+	invalid := chunktesting.GenerateTestRandomChunk()
+
+	storer := mock.NewStorer()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, append(chunks, invalid)...); err != nil {
+		t.Fatal(err)
+	}
+	if err := storer.Set(context.Background(), storage.ModeSetPin, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	testServer := newTestServer(t, testServerOptions{
+		Storer: storer,
+	})
+
+	for _, tc := range []struct {
+		name     string
+		query    string
+		pushSync bool
+		want     debugapi.StorageImportResponse
+		wantMode storage.ModePut
+	}{
+		{
+			name:     "all",
+			pushSync: true,
+			want: debugapi.StorageImportResponse{
+				Imported: 3,
+				Invalid:  1,
+			},
+			wantMode: storage.ModePutUpload,
+		},
+		{
+			name:  "pinned",
+			query: "?pinned=true",
+			want: debugapi.StorageImportResponse{
+				Imported: 1,
+			},
+			wantMode: storage.ModePutRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var export []byte
+			header := jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/storage/export"+tc.query, http.StatusOK,
+				jsonhttptest.WithPutResponseBody(&export),
+			)
+			if got := header.Get("Content-Type"); got != "application/x-tar" {
+				t.Errorf("got content type %q, want %q", got, "application/x-tar")
+			}
+
+			target := mock.NewStorer()
+			importServer := newTestServer(t, testServerOptions{
+				Storer: target,
+			})
+			jsonhttptest.Request(t, importServer.Client, http.MethodPost, fmt.Sprintf("/storage/import?push-sync=%t", tc.pushSync), http.StatusOK,
+				jsonhttptest.WithRequestBody(bytes.NewReader(export)),
+				jsonhttptest.WithExpectedJSONResponse(tc.want),
+			)
+			if got := target.GetModePut(chunks[0].Address()); got != tc.wantMode {
+				t.Errorf("got put mode %v, want %v", got, tc.wantMode)
+			}
+		})
+	}
+
+	t.Run("invalid query", func(t *testing.T) {
+		for _, q := range []string{"bin=256", "pinned=maybe", "since=yesterday", "until=1"} {
+			jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/storage/export?"+q, http.StatusBadRequest)
+		}
+		jsonhttptest.Request(t, testServer.Client, http.MethodPost, "/storage/import?push-sync=maybe", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Message: "invalid push-sync",
+				Code:    http.StatusBadRequest,
+			}),
+		)
+	})
+}
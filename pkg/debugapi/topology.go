@@ -5,14 +5,23 @@
 package debugapi
 
 import (
-	"bytes"
 	"encoding/json"
-	"io"
 	"net/http"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 )
 
+type connectionSlotsResponse struct {
+	Inbound  slotsResponse `json:"inbound"`
+	Outbound slotsResponse `json:"outbound"`
+	Reserved int           `json:"reserved"`
+}
+
+type slotsResponse struct {
+	Limit int `json:"limit"`
+	Used  int `json:"used"`
+}
+
 func (s *server) topologyHandler(w http.ResponseWriter, r *http.Request) {
 	ms, ok := s.TopologyDriver.(json.Marshaler)
 	if !ok {
@@ -27,6 +36,32 @@ func (s *server) topologyHandler(w http.ResponseWriter, r *http.Request) {
 		jsonhttp.InternalServerError(w, err)
 		return
 	}
-	w.Header().Set("Content-Type", jsonhttp.DefaultContentTypeHeader)
-	_, _ = io.Copy(w, bytes.NewBuffer(b))
+
+	var topology map[string]json.RawMessage
+	if err := json.Unmarshal(b, &topology); err != nil {
+		s.Logger.Errorf("topology unmarshal json: %v", err)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+
+	slots := s.P2P.ConnectionSlots()
+	b, err = json.Marshal(connectionSlotsResponse{
+		Inbound: slotsResponse{
+			Limit: slots.InboundLimit,
+			Used:  slots.Inbound,
+		},
+		Outbound: slotsResponse{
+			Limit: slots.OutboundLimit,
+			Used:  slots.Outbound,
+		},
+		Reserved: slots.Reserved,
+	})
+	if err != nil {
+		s.Logger.Errorf("connection slots marshal to json: %v", err)
+		jsonhttp.InternalServerError(w, err)
+		return
+	}
+	topology["slots"] = b
+
+	jsonhttp.OK(w, topology)
 }
@@ -10,13 +10,17 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/p2p"
+	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
 	topmock "github.com/ethersphere/bee/pkg/topology/mock"
 )
 
 type topologyResponse struct {
-	Topology string `json:"topology"`
+	Slots    *debugapi.ConnectionSlotsResponse `json:"slots,omitempty"`
+	Topology string                            `json:"topology"`
 }
 
 func TestTopologyOK(t *testing.T) {
@@ -25,11 +29,23 @@ func TestTopologyOK(t *testing.T) {
 	}
 	testServer := newTestServer(t, testServerOptions{
 		TopologyOpts: []topmock.Option{topmock.WithMarshalJSONFunc(marshalFunc)},
+		P2P: p2pmock.New(p2pmock.WithConnectionSlots(p2p.ConnectionSlots{
+			InboundLimit:  150,
+			Inbound:       12,
+			OutboundLimit: 50,
+			Outbound:      8,
+			Reserved:      10,
+		})),
 	})
 
 	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/topology", http.StatusOK,
 		jsonhttptest.WithExpectedJSONResponse(topologyResponse{
 			Topology: "abcd",
+			Slots: &debugapi.ConnectionSlotsResponse{
+				Inbound:  debugapi.SlotsResponse{Limit: 150, Used: 12},
+				Outbound: debugapi.SlotsResponse{Limit: 50, Used: 8},
+				Reserved: 10,
+			},
 		}),
 	)
 }
@@ -40,6 +56,7 @@ func TestTopologyError(t *testing.T) {
 	}
 	testServer := newTestServer(t, testServerOptions{
 		TopologyOpts: []topmock.Option{topmock.WithMarshalJSONFunc(marshalFunc)},
+		P2P:          p2pmock.New(),
 	})
 
 	jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/topology", http.StatusInternalServerError,
@@ -0,0 +1,78 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// defaultTrafficLimit is the number of peers returned by the traffic endpoint
+// if the limit is not specified.
+const defaultTrafficLimit = 10
+
+type protocolTrafficResponse struct {
+	Protocol        string `json:"protocol"`
+	Stream          string `json:"stream"`
+	Read            uint64 `json:"read"`
+	Written         uint64 `json:"written"`
+	InboundStreams  uint64 `json:"inboundStreams"`
+	OutboundStreams uint64 `json:"outboundStreams"`
+	StreamDuration  string `json:"streamDuration"`
+}
+
+type peerTrafficResponse struct {
+	Address   swarm.Address             `json:"address"`
+	Read      uint64                    `json:"read"`
+	Written   uint64                    `json:"written"`
+	Protocols []protocolTrafficResponse `json:"protocols"`
+}
+
+type trafficResponse struct {
+	Peers []peerTrafficResponse `json:"peers"`
+}
+
+func (s *server) trafficHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTrafficLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			s.Logger.Debugf("debug api: traffic: parse limit %s: %v", l, err)
+			jsonhttp.BadRequest(w, "invalid limit")
+			return
+		}
+	}
+
+	peers := s.P2P.Traffic(limit)
+	resp := trafficResponse{
+		Peers: make([]peerTrafficResponse, 0, len(peers)),
+	}
+	for _, p := range peers {
+		pr := peerTrafficResponse{
+			Address:   p.Address,
+			Read:      p.Read,
+			Written:   p.Written,
+			Protocols: make([]protocolTrafficResponse, 0, len(p.Protocols)),
+		}
+		for _, pt := range p.Protocols {
+			pr.Protocols = append(pr.Protocols, protocolTrafficResponse{
+				Protocol:        pt.Protocol,
+				Stream:          pt.Stream,
+				Read:            pt.Read,
+				Written:         pt.Written,
+				InboundStreams:  pt.InboundStreams,
+				OutboundStreams: pt.OutboundStreams,
+				StreamDuration:  pt.StreamDuration.String(),
+			})
+		}
+		resp.Peers = append(resp.Peers, pr)
+	}
+
+	jsonhttp.OK(w, resp)
+}
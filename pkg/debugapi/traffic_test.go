@@ -0,0 +1,84 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestTraffic(t *testing.T) {
+	overlay := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	var gotLimit int
+	testServer := newTestServer(t, testServerOptions{
+		P2P: mock.New(mock.WithTrafficFunc(func(limit int) []p2p.PeerTraffic {
+			gotLimit = limit
+			return []p2p.PeerTraffic{{
+				Address: overlay,
+				Read:    10,
+				Written: 4096,
+				Protocols: []p2p.ProtocolTraffic{{
+					Protocol:        "pushsync",
+					Stream:          "pushsync",
+					Read:            10,
+					Written:         4096,
+					OutboundStreams: 2,
+					StreamDuration:  3 * time.Second,
+				}},
+			}}
+		})),
+	})
+
+	want := debugapi.TrafficResponse{
+		Peers: []debugapi.PeerTrafficResponse{{
+			Address: overlay,
+			Read:    10,
+			Written: 4096,
+			Protocols: []debugapi.ProtocolTrafficResponse{{
+				Protocol:        "pushsync",
+				Stream:          "pushsync",
+				Read:            10,
+				Written:         4096,
+				OutboundStreams: 2,
+				StreamDuration:  "3s",
+			}},
+		}},
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/traffic", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(want),
+		)
+		if gotLimit != 10 {
+			t.Fatalf("got limit %v, want default 10", gotLimit)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/traffic?limit=3", http.StatusOK,
+			jsonhttptest.WithExpectedJSONResponse(want),
+		)
+		if gotLimit != 3 {
+			t.Fatalf("got limit %v, want 3", gotLimit)
+		}
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		jsonhttptest.Request(t, testServer.Client, http.MethodGet, "/traffic?limit=-1", http.StatusBadRequest,
+			jsonhttptest.WithExpectedJSONResponse(jsonhttp.StatusResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid limit",
+			}),
+		)
+	})
+}
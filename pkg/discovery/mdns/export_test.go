@@ -0,0 +1,16 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mdns
+
+import "context"
+
+var (
+	TXTRecord      = txtRecord
+	ParseTXTRecord = parseTXTRecord
+)
+
+func (s *Service) AddPeers(records ...[]string) error {
+	return s.addPeers(context.Background(), records...)
+}
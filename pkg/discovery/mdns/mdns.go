@@ -0,0 +1,276 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mdns discovers bee nodes in the local network over multicast DNS.
+//
+// Every node advertises its signed bzz address in the TXT record of a DNS-SD
+// service and periodically queries for the other nodes. Addresses of the
+// nodes with the same network id are verified, put into the addressbook and
+// passed to the add peers handler, usually the kademlia topology driver.
+package mdns
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/swarm"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/whyrusleeping/mdns"
+)
+
+const (
+	serviceName   = "_bee-discovery._udp"
+	queryTimeout  = 2 * time.Second
+	queryInterval = 10 * time.Second
+
+	txtNetworkID = "network="
+	txtOverlay   = "overlay="
+	txtUnderlay  = "underlay="
+	txtSignature = "signature="
+)
+
+var (
+	// ErrNoUnderlay is returned if none of the addresses can be advertised
+	// in the local network.
+	ErrNoUnderlay = errors.New("mdns: no advertisable address")
+	// ErrInvalidRecord is returned if the TXT record of a discovered node is
+	// malformed.
+	ErrInvalidRecord = errors.New("mdns: invalid record")
+	// ErrNetworkMismatch is returned if a discovered node is in a different
+	// network.
+	ErrNetworkMismatch = errors.New("mdns: network id mismatch")
+)
+
+func init() {
+	// the package logs with the standard logger
+	mdns.DisableLogging = true
+}
+
+type Service struct {
+	address         bzz.Address
+	networkID       uint64
+	addressBook     addressbook.Putter
+	addPeersHandler func(context.Context, ...swarm.Address) error
+	logger          logging.Logger
+	server          *mdns.Server
+	seen            map[string]string // advertised underlays of discovered overlays
+	quit            chan struct{}
+	wg              sync.WaitGroup
+}
+
+// New creates a new mDNS discovery service that advertises the signed bzz
+// address.
+func New(address bzz.Address, networkID uint64, addressbook addressbook.Putter, logger logging.Logger) *Service {
+	return &Service{
+		address:     address,
+		networkID:   networkID,
+		addressBook: addressbook,
+		logger:      logger,
+		seen:        make(map[string]string),
+		quit:        make(chan struct{}),
+	}
+}
+
+func (s *Service) SetAddPeersHandler(h func(ctx context.Context, addr ...swarm.Address) error) {
+	s.addPeersHandler = h
+}
+
+// Start starts advertising the address and querying for other nodes.
+func (s *Service) Start() error {
+	na, err := manet.ToNetAddr(s.address.Underlay.Decapsulate(ma.StringCast("/p2p/" + peerIDOf(s.address.Underlay))))
+	if err != nil {
+		return fmt.Errorf("advertised address: %w", err)
+	}
+	tcp, ok := na.(*net.TCPAddr)
+	if !ok {
+		return ErrNoUnderlay
+	}
+
+	overlay := s.address.Overlay.String()
+	service, err := mdns.NewMDNSService(overlay[:32], serviceName, "", overlay[:32]+".local.", tcp.Port, []net.IP{tcp.IP}, txtRecord(s.address, s.networkID))
+	if err != nil {
+		return fmt.Errorf("mdns service: %w", err)
+	}
+	s.server, err = mdns.NewServer(&mdns.Config{Zone: service})
+	if err != nil {
+		return fmt.Errorf("mdns server: %w", err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(queryInterval)
+		defer ticker.Stop()
+
+		for {
+			s.query()
+
+			select {
+			case <-ticker.C:
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// query looks up the nodes in the local network and adds the discovered ones.
+func (s *Service) query() {
+	entries := make(chan *mdns.ServiceEntry, 32)
+	done := make(chan struct{})
+	var records [][]string
+	go func() {
+		defer close(done)
+		for e := range entries {
+			records = append(records, e.InfoFields)
+		}
+	}()
+
+	err := mdns.Query(&mdns.QueryParam{
+		Service: serviceName,
+		Timeout: queryTimeout,
+		Entries: entries,
+	})
+	close(entries)
+	<-done
+	if err != nil {
+		s.logger.Debugf("mdns: query: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if err := s.addPeers(ctx, records...); err != nil {
+		s.logger.Debugf("mdns: add peers: %v", err)
+	}
+}
+
+// addPeers verifies the addresses in the TXT records, puts them into the
+// addressbook and passes the newly discovered overlays to the handler.
+func (s *Service) addPeers(ctx context.Context, records ...[]string) error {
+	var peers []swarm.Address
+	for _, r := range records {
+		addr, err := parseTXTRecord(r, s.networkID)
+		if err != nil {
+			if !errors.Is(err, ErrNetworkMismatch) {
+				s.logger.Debugf("mdns: skipping record %v: %v", r, err)
+			}
+			continue
+		}
+		if addr.Overlay.Equal(s.address.Overlay) {
+			continue
+		}
+		underlay := addr.Underlay.String()
+		if s.seen[addr.Overlay.ByteString()] == underlay {
+			continue
+		}
+
+		if err := s.addressBook.Put(addr.Overlay, *addr); err != nil {
+			return err
+		}
+		s.seen[addr.Overlay.ByteString()] = underlay
+		s.logger.Debugf("mdns: discovered peer %s at %s", addr.Overlay, underlay)
+		peers = append(peers, addr.Overlay)
+	}
+
+	if len(peers) == 0 || s.addPeersHandler == nil {
+		return nil
+	}
+	return s.addPeersHandler(ctx, peers...)
+}
+
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	if s.server != nil {
+		return s.server.Shutdown()
+	}
+	return nil
+}
+
+// Underlay returns the first address that is reachable in the local network,
+// a non-loopback IP address on TCP.
+func Underlay(addrs []ma.Multiaddr) (ma.Multiaddr, error) {
+	for _, addr := range addrs {
+		ip, err := manet.ToIP(addr)
+		if err != nil || ip.IsLoopback() || ip.IsUnspecified() {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(ma.P_TCP); err != nil {
+			continue
+		}
+		if peerIDOf(addr) == "" {
+			continue
+		}
+		return addr, nil
+	}
+	return nil, ErrNoUnderlay
+}
+
+func peerIDOf(addr ma.Multiaddr) string {
+	id, _ := addr.ValueForProtocol(ma.P_P2P)
+	return id
+}
+
+// txtRecord encodes the bzz address into TXT record strings, each shorter
+// than the 255 bytes limit.
+func txtRecord(addr bzz.Address, networkID uint64) []string {
+	return []string{
+		txtNetworkID + strconv.FormatUint(networkID, 10),
+		txtOverlay + addr.Overlay.String(),
+		txtUnderlay + hex.EncodeToString(addr.Underlay.Bytes()),
+		txtSignature + hex.EncodeToString(addr.Signature),
+	}
+}
+
+// parseTXTRecord decodes and verifies the bzz address from the TXT record.
+func parseTXTRecord(fields []string, networkID uint64) (*bzz.Address, error) {
+	var (
+		network                      string
+		overlay, underlay, signature []byte
+		err                          error
+	)
+	for _, f := range fields {
+		switch {
+		case strings.HasPrefix(f, txtNetworkID):
+			network = strings.TrimPrefix(f, txtNetworkID)
+		case strings.HasPrefix(f, txtOverlay):
+			overlay, err = hex.DecodeString(strings.TrimPrefix(f, txtOverlay))
+		case strings.HasPrefix(f, txtUnderlay):
+			underlay, err = hex.DecodeString(strings.TrimPrefix(f, txtUnderlay))
+		case strings.HasPrefix(f, txtSignature):
+			signature, err = hex.DecodeString(strings.TrimPrefix(f, txtSignature))
+		}
+		if err != nil {
+			return nil, ErrInvalidRecord
+		}
+	}
+	if network == "" || overlay == nil || underlay == nil || signature == nil {
+		return nil, ErrInvalidRecord
+	}
+	if network != strconv.FormatUint(networkID, 10) {
+		return nil, ErrNetworkMismatch
+	}
+	return bzz.ParseAddress(underlay, overlay, signature, networkID)
+}
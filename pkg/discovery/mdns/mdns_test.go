@@ -0,0 +1,148 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mdns_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	ab "github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/discovery/mdns"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const testUnderlay = "/ip4/192.168.1.10/tcp/1634/p2p/16Uiu2HAm3g4hXfCWTDhPBq3NkqpZhT8Ai5o4h4mrFLC3y7Qc3h8y"
+
+func TestTXTRecord(t *testing.T) {
+	addr := newAddress(t, testUnderlay, 1)
+
+	fields := mdns.TXTRecord(addr, 1)
+	for _, f := range fields {
+		if len(f) > 255 {
+			t.Fatalf("got record field of %d bytes, want at most 255", len(f))
+		}
+	}
+
+	got, err := mdns.ParseTXTRecord(fields, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(&addr) {
+		t.Fatalf("got address %s, want %s", got, &addr)
+	}
+
+	if _, err := mdns.ParseTXTRecord(fields, 2); !errors.Is(err, mdns.ErrNetworkMismatch) {
+		t.Fatalf("got error %v, want %v", err, mdns.ErrNetworkMismatch)
+	}
+	if _, err := mdns.ParseTXTRecord(fields[1:], 1); !errors.Is(err, mdns.ErrInvalidRecord) {
+		t.Fatalf("got error %v, want %v", err, mdns.ErrInvalidRecord)
+	}
+	if _, err := mdns.ParseTXTRecord(append(fields, "overlay=zz"), 1); !errors.Is(err, mdns.ErrInvalidRecord) {
+		t.Fatalf("got error %v, want %v", err, mdns.ErrInvalidRecord)
+	}
+
+	// signature of a different address
+	other := newAddress(t, testUnderlay, 1)
+	tampered := append([]string{}, fields...)
+	tampered[3] = mdns.TXTRecord(other, 1)[3]
+	if _, err := mdns.ParseTXTRecord(tampered, 1); err == nil {
+		t.Fatal("expected error for a tampered record")
+	}
+}
+
+func TestAddPeers(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	addressbook := ab.New(mock.NewStateStore())
+
+	self := newAddress(t, testUnderlay, 1)
+	peer := newAddress(t, testUnderlay, 1)
+	foreign := newAddress(t, testUnderlay, 2)
+
+	var added []swarm.Address
+	s := mdns.New(self, 1, addressbook, logger)
+	s.SetAddPeersHandler(func(_ context.Context, addrs ...swarm.Address) error {
+		added = append(added, addrs...)
+		return nil
+	})
+
+	if err := s.AddPeers(
+		mdns.TXTRecord(self, 1),
+		mdns.TXTRecord(peer, 1),
+		mdns.TXTRecord(foreign, 2),
+		[]string{"malformed"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || !added[0].Equal(peer.Overlay) {
+		t.Fatalf("got added peers %v, want %v", added, peer.Overlay)
+	}
+	got, err := addressbook.Get(peer.Overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(&peer) {
+		t.Fatalf("got address %s, want %s", got, &peer)
+	}
+	if _, err := addressbook.Get(self.Overlay); !errors.Is(err, ab.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, ab.ErrNotFound)
+	}
+	if _, err := addressbook.Get(foreign.Overlay); !errors.Is(err, ab.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, ab.ErrNotFound)
+	}
+
+	// already discovered peers are not added again
+	if err := s.AddPeers(mdns.TXTRecord(peer, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 {
+		t.Fatalf("got %d added peers, want 1", len(added))
+	}
+}
+
+func TestUnderlay(t *testing.T) {
+	addrs := []ma.Multiaddr{
+		ma.StringCast("/ip4/127.0.0.1/tcp/1634/p2p/16Uiu2HAm3g4hXfCWTDhPBq3NkqpZhT8Ai5o4h4mrFLC3y7Qc3h8y"),
+		ma.StringCast("/ip4/192.168.1.10/udp/1634/quic/p2p/16Uiu2HAm3g4hXfCWTDhPBq3NkqpZhT8Ai5o4h4mrFLC3y7Qc3h8y"),
+		ma.StringCast(testUnderlay),
+	}
+
+	got, err := mdns.Underlay(addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(addrs[2]) {
+		t.Fatalf("got underlay %s, want %s", got, addrs[2])
+	}
+
+	if _, err := mdns.Underlay(addrs[:2]); !errors.Is(err, mdns.ErrNoUnderlay) {
+		t.Fatalf("got error %v, want %v", err, mdns.ErrNoUnderlay)
+	}
+}
+
+func newAddress(t *testing.T, underlay string, networkID uint64) bzz.Address {
+	t.Helper()
+
+	pk, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(pk.PublicKey, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := bzz.NewAddress(crypto.NewDefaultSigner(pk), ma.StringCast(underlay), overlay, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *addr
+}
@@ -167,6 +167,17 @@ func (i *Intervals) Last() (end uint64) {
 	return i.ranges[l-1][1]
 }
 
+// Ranges returns a copy of the stored ranges. Range start and end values
+// are both inclusive.
+func (i *Intervals) Ranges() [][2]uint64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	ranges := make([][2]uint64, len(i.ranges))
+	copy(ranges, i.ranges)
+	return ranges
+}
+
 // String returns a descriptive representation of range intervals
 // in [] notation, as a list of two element vectors.
 func (i *Intervals) String() string {
@@ -16,7 +16,10 @@
 
 package intervalstore
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 // Test tests Interval methods Add, Next and Last for various
 // initial state.
@@ -399,6 +402,9 @@ func Test(t *testing.T) {
 		if got != tc.expected {
 			t.Errorf("interval #%d: expected %s, got %s", i, tc.expected, got)
 		}
+		if got := fmt.Sprint(intervals.Ranges()); got != tc.expected {
+			t.Errorf("interval #%d: expected ranges %s, got %s", i, tc.expected, got)
+		}
 		nextStart, nextEnd, nextEmptyRange := intervals.Next(tc.ceiling)
 		if nextStart != tc.nextStart {
 			t.Errorf("interval #%d, expected next start %d, got %d", i, tc.nextStart, nextStart)
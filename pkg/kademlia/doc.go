@@ -48,17 +48,19 @@ for the nodes, maintaining a general upper bound of the assigned nominal
 area of responsibility in terms of actual storage requirement. See book of Swarm for more details.
 
 Worth to note is that `manage()` will always try to initiate connections when
-a bin is not saturated, however currently it will not try to eliminate connections
-on bins which might be over-saturated. Ideally it should be very cheap to maintain a
-connection to a peer in a bin, so we should theoretically not aspire to eliminate connections prematurely.
+a bin is not saturated. Connections within a bin are balanced: the bin is divided
+into sub-bins by the address bits that follow the bin prefix, and peers from the
+sub-bins with the fewest connected peers are dialed first, so that connected peers
+cover the address space of the bin evenly.
 It is also safe to assume we will always have more than the lower bound of peers in a bin, why?
 (1) Initially, we will always try to satisfy our own connectivity requirement to saturate the bin
 (2) Later on, other peers will get notified about our advertised address and
 will try to connect to us in order to satisfy their own connectivity thresholds
 
 We should allow other nodes to dial in, in order to help them maintain a healthy topolgy.
-It could be, however, that we would need to mark-and-sweep certain connections once a
-theorical upper bound has been reached.
+Once a bin shallower than depth has more connected peers than the over-saturation
+upper bound, the surplus peers are pruned from the most populated sub-bins, which
+keeps slots free for inbound connections.
 
 Depth calculation explained:
 When we calculate depth we must keep in mind the following constraints:
@@ -8,3 +8,5 @@ var (
 	TimeToRetry     = &timeToRetry
 	SaturationPeers = &saturationPeers
 )
+
+var SubBin = subBin
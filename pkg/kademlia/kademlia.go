@@ -17,6 +17,8 @@ import (
 	"github.com/ethersphere/bee/pkg/kademlia/pslice"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/reputation"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	ma "github.com/multiformats/go-multiaddr"
@@ -33,37 +35,67 @@ var (
 	timeToRetry                = 60 * time.Second
 	shortRetry                 = 30 * time.Second
 	saturationPeers            = 4
+	overSaturationPeers        = 16
 )
 
 type binSaturationFunc func(bin uint8, peers, connected *pslice.PSlice) bool
 
+// PruneFunc disconnects surplus peers given the current depth. It is called
+// after every connection round of kademlia.
+type PruneFunc func(depth uint8)
+
 // Options for injecting services to Kademlia.
 type Options struct {
 	SaturationFunc binSaturationFunc
-	Bootnodes      []ma.Multiaddr
+	// SaturationPeers is the number of connected peers at which a bin
+	// shallower than the depth is saturated. It also sets the number of
+	// sub-bins that the connections are balanced across.
+	SaturationPeers int
+	// OverSaturationPeers is the number of connected peers in a bin
+	// shallower than the depth above which surplus peers are pruned.
+	OverSaturationPeers int
+	// PruneFunc replaces the default pruning of the bins with more than
+	// OverSaturationPeers connected peers.
+	PruneFunc PruneFunc
+	// PinnedPeers are the overlay addresses of peers that are never pruned.
+	PinnedPeers []swarm.Address
+	Bootnodes   []ma.Multiaddr
+	Reputation  reputation.Interface
+	Latency     pingpong.LatencyReporter // prefer lower latency peers among equally close ones
+	LightNode   bool                     // light nodes always forward chunks to the closest peer
 }
 
 // Kad is the Swarm forwarding kademlia implementation.
 type Kad struct {
-	base           swarm.Address         // this node's overlay address
-	discovery      discovery.Driver      // the discovery driver
-	addressBook    addressbook.Interface // address book to get underlays
-	p2p            p2p.Service           // p2p service to connect to nodes with
-	saturationFunc binSaturationFunc     // pluggable saturation function
-	connectedPeers *pslice.PSlice        // a slice of peers sorted and indexed by po, indexes kept in `bins`
-	knownPeers     *pslice.PSlice        // both are po aware slice of addresses
-	bootnodes      []ma.Multiaddr
-	depth          uint8                // current neighborhood depth
-	depthMu        sync.RWMutex         // protect depth changes
-	manageC        chan struct{}        // trigger the manage forever loop to connect to new peers
-	waitNext       map[string]retryInfo // sanction connections to a peer, key is overlay string and value is a retry information
-	waitNextMu     sync.Mutex           // synchronize map
-	peerSig        []chan struct{}
-	peerSigMtx     sync.Mutex
-	logger         logging.Logger // logger
-	quit           chan struct{}  // quit channel
-	done           chan struct{}  // signal that `manage` has quit
-	wg             sync.WaitGroup
+	base                swarm.Address            // this node's overlay address
+	discovery           discovery.Driver         // the discovery driver
+	addressBook         addressbook.Interface    // address book to get underlays
+	p2p                 p2p.Service              // p2p service to connect to nodes with
+	saturationFunc      binSaturationFunc        // pluggable saturation function
+	pruneFunc           PruneFunc                // pluggable pruning function
+	saturationPeers     int                      // connected peers at which a shallow bin is saturated
+	subBinBits          int                      // number of address bits that divide a bin into sub-bins
+	overSaturationPeers int                      // connected peers in a shallow bin above which peers are pruned
+	pinnedPeers         []swarm.Address          // peers that are never pruned
+	bootnodePeers       map[string]struct{}      // overlays of connected bootnodes that are never pruned
+	bootnodePeersMu     sync.Mutex               // protect bootnodePeers
+	reputation          reputation.Interface     // peer scores, may be nil
+	latency             pingpong.LatencyReporter // peer latencies, may be nil
+	lightNode           bool                     // this node does not store chunks for the network
+	connectedPeers      *pslice.PSlice           // a slice of peers sorted and indexed by po, indexes kept in `bins`
+	knownPeers          *pslice.PSlice           // both are po aware slice of addresses
+	bootnodes           []ma.Multiaddr
+	depth               uint8                // current neighborhood depth
+	depthMu             sync.RWMutex         // protect depth changes
+	manageC             chan struct{}        // trigger the manage forever loop to connect to new peers
+	waitNext            map[string]retryInfo // sanction connections to a peer, key is overlay string and value is a retry information
+	waitNextMu          sync.Mutex           // synchronize map
+	peerSig             []chan struct{}
+	peerSigMtx          sync.Mutex
+	logger              logging.Logger // logger
+	quit                chan struct{}  // quit channel
+	done                chan struct{}  // signal that `manage` has quit
+	wg                  sync.WaitGroup
 }
 
 type retryInfo struct {
@@ -73,25 +105,44 @@ type retryInfo struct {
 
 // New returns a new Kademlia.
 func New(base swarm.Address, addressbook addressbook.Interface, discovery discovery.Driver, p2p p2p.Service, logger logging.Logger, o Options) *Kad {
+	if o.SaturationPeers == 0 {
+		o.SaturationPeers = saturationPeers
+	}
+	if o.OverSaturationPeers == 0 {
+		o.OverSaturationPeers = overSaturationPeers
+	}
 	if o.SaturationFunc == nil {
-		o.SaturationFunc = binSaturated
+		o.SaturationFunc = binSaturated(o.SaturationPeers)
 	}
 
 	k := &Kad{
-		base:           base,
-		discovery:      discovery,
-		addressBook:    addressbook,
-		p2p:            p2p,
-		saturationFunc: o.SaturationFunc,
-		connectedPeers: pslice.New(int(swarm.MaxBins)),
-		knownPeers:     pslice.New(int(swarm.MaxBins)),
-		bootnodes:      o.Bootnodes,
-		manageC:        make(chan struct{}, 1),
-		waitNext:       make(map[string]retryInfo),
-		logger:         logger,
-		quit:           make(chan struct{}),
-		done:           make(chan struct{}),
-		wg:             sync.WaitGroup{},
+		base:                base,
+		discovery:           discovery,
+		addressBook:         addressbook,
+		p2p:                 p2p,
+		saturationFunc:      o.SaturationFunc,
+		pruneFunc:           o.PruneFunc,
+		saturationPeers:     o.SaturationPeers,
+		subBinBits:          subBinBits(o.SaturationPeers),
+		overSaturationPeers: o.OverSaturationPeers,
+		pinnedPeers:         o.PinnedPeers,
+		bootnodePeers:       make(map[string]struct{}),
+		reputation:          o.Reputation,
+		latency:             o.Latency,
+		lightNode:           o.LightNode,
+		connectedPeers:      pslice.New(int(swarm.MaxBins)),
+		knownPeers:          pslice.New(int(swarm.MaxBins)),
+		bootnodes:           o.Bootnodes,
+		manageC:             make(chan struct{}, 1),
+		waitNext:            make(map[string]retryInfo),
+		logger:              logger,
+		quit:                make(chan struct{}),
+		done:                make(chan struct{}),
+		wg:                  sync.WaitGroup{},
+	}
+
+	if k.pruneFunc == nil {
+		k.pruneFunc = k.pruneOversaturatedBins
 	}
 
 	return k
@@ -100,10 +151,7 @@ func New(base swarm.Address, addressbook addressbook.Interface, discovery discov
 // manage is a forever loop that manages the connection to new peers
 // once they get added or once others leave.
 func (k *Kad) manage() {
-	var (
-		peerToRemove swarm.Address
-		start        time.Time
-	)
+	var start time.Time
 
 	defer k.wg.Done()
 	defer close(k.done)
@@ -131,92 +179,246 @@ func (k *Kad) manage() {
 			default:
 			}
 
-			err := k.knownPeers.EachBinRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
-				if k.connectedPeers.Exists(peer) {
-					return false, false, nil
-				}
+			k.connectBins(ctx)
+			k.logger.Tracef("kademlia iterator took %s to finish", time.Since(start))
 
-				k.waitNextMu.Lock()
-				if next, ok := k.waitNext[peer.String()]; ok && time.Now().Before(next.tryAfter) {
-					k.waitNextMu.Unlock()
-					return false, false, nil
-				}
-				k.waitNextMu.Unlock()
+			k.pruneFunc(k.NeighborhoodDepth())
 
-				currentDepth := k.NeighborhoodDepth()
-				if saturated := k.saturationFunc(po, k.knownPeers, k.connectedPeers); saturated {
-					return false, true, nil // bin is saturated, skip to next bin
-				}
+			if k.connectedPeers.Length() == 0 {
+				k.connectBootnodes(ctx)
+			}
+		}
+	}
+}
 
-				bzzAddr, err := k.addressBook.Get(peer)
-				if err != nil {
-					if err == addressbook.ErrNotFound {
-						k.logger.Debugf("failed to get address book entry for peer: %s", peer.String())
-						peerToRemove = peer
-						return false, false, errMissingAddressBookEntry
-					}
-					// either a peer is not known in the address book, in which case it
-					// should be removed, or that some severe I/O problem is at hand
-					return false, false, err
-				}
+// connectBins dials known peers bin by bin, starting from the shallowest
+// bin, until the bin is saturated. Within a bin, peers from the sub-bins
+// with the fewest connected peers are dialed first, so that the connections
+// are balanced across the address space of the bin.
+func (k *Kad) connectBins(ctx context.Context) {
+	candidates := make([][]swarm.Address, swarm.MaxBins)
+	_ = k.knownPeers.EachBinRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
+		if k.connectedPeers.Exists(peer) {
+			return false, false, nil
+		}
 
-				k.logger.Debugf("kademlia dialing to peer %s", peer.String())
+		k.waitNextMu.Lock()
+		if next, ok := k.waitNext[peer.String()]; ok && time.Now().Before(next.tryAfter) {
+			k.waitNextMu.Unlock()
+			return false, false, nil
+		}
+		k.waitNextMu.Unlock()
 
-				err = k.connect(ctx, peer, bzzAddr.Underlay, po)
-				if err != nil {
-					if errors.Is(err, errOverlayMismatch) {
-						k.knownPeers.Remove(peer, po)
-						if err := k.addressBook.Remove(peer); err != nil {
-							k.logger.Debugf("could not remove peer from addressbook: %s", peer.String())
-						}
-					}
-					k.logger.Debugf("error connecting to peer from kademlia %s: %v", bzzAddr.String(), err)
-					k.logger.Warningf("connecting to peer %s: %v", bzzAddr.ShortString(), err)
-					// continue to next
-					return false, false, nil
+		if k.skipped(peer) {
+			return false, false, nil
+		}
+
+		candidates[po] = append(candidates[po], peer)
+		return false, false, nil
+	})
+
+	potentialDepth := recalcDepth(k.knownPeers)
+	for bin, peers := range candidates {
+		po := uint8(bin)
+		subBins := k.connectedSubBins(po)
+
+		for len(peers) > 0 {
+			if saturated := k.saturationFunc(po, k.knownPeers, k.connectedPeers); saturated {
+				break // bin is saturated, skip to next bin
+			}
+
+			// pick the peer from the least connected sub-bin
+			i := 0
+			for j, peer := range peers {
+				if subBins[subBin(peer, po, k.subBinBits)] < subBins[subBin(peers[i], po, k.subBinBits)] {
+					i = j
 				}
+			}
+			peer := peers[i]
+			peers = append(peers[:i], peers[i+1:]...)
 
+			// a shallow bin with enough peers is only missing peers
+			// in the uncovered sub-bins, dialing peers in the covered
+			// ones would only oversaturate it
+			if po < potentialDepth && subBins[subBin(peer, po, k.subBinBits)] > 0 && binSize(subBins) >= k.saturationPeers {
+				break
+			}
+
+			// the blocklist is checked only for the peer that is about
+			// to be dialed, as it is kept in the state store
+			if k.blocklisted(peer) {
 				k.waitNextMu.Lock()
-				k.waitNext[peer.String()] = retryInfo{tryAfter: time.Now().Add(shortRetry)}
+				info := k.waitNext[peer.String()]
+				info.tryAfter = time.Now().Add(timeToRetry)
+				k.waitNext[peer.String()] = info
 				k.waitNextMu.Unlock()
+				continue
+			}
 
-				k.connectedPeers.Add(peer, po)
+			if err := k.dial(ctx, peer, po); err != nil {
+				// continue to next
+				continue
+			}
+			subBins[subBin(peer, po, k.subBinBits)]++
 
-				k.depthMu.Lock()
-				k.depth = recalcDepth(k.connectedPeers)
-				k.depthMu.Unlock()
+			select {
+			case <-k.quit:
+				return
+			default:
+			}
+		}
+	}
+}
 
-				k.logger.Debugf("connected to peer: %s old depth: %d new depth: %d", peer, currentDepth, k.NeighborhoodDepth())
+// dial connects to a known peer and adds it to the connected peers.
+func (k *Kad) dial(ctx context.Context, peer swarm.Address, po uint8) error {
+	bzzAddr, err := k.addressBook.Get(peer)
+	if err != nil {
+		if err == addressbook.ErrNotFound {
+			k.logger.Debugf("failed to get address book entry for peer: %s", peer.String())
+			k.knownPeers.Remove(peer, po)
+			return errMissingAddressBookEntry
+		}
+		// some severe I/O problem is at hand
+		k.logger.Errorf("kademlia manage loop addressbook: %v", err)
+		return err
+	}
 
-				k.notifyPeerSig()
+	k.logger.Debugf("kademlia dialing to peer %s", peer.String())
 
-				select {
-				case <-k.quit:
-					return true, false, nil
-				default:
-				}
+	currentDepth := k.NeighborhoodDepth()
+	if err := k.connect(ctx, peer, bzzAddr.Underlay, po); err != nil {
+		if errors.Is(err, errOverlayMismatch) {
+			k.knownPeers.Remove(peer, po)
+			if err := k.addressBook.Remove(peer); err != nil {
+				k.logger.Debugf("could not remove peer from addressbook: %s", peer.String())
+			}
+		}
+		k.logger.Debugf("error connecting to peer from kademlia %s: %v", bzzAddr.String(), err)
+		k.logger.Warningf("connecting to peer %s: %v", bzzAddr.ShortString(), err)
+		return err
+	}
 
-				// the bin could be saturated or not, so a decision cannot
-				// be made before checking the next peer, so we iterate to next
-				return false, false, nil
-			})
-			k.logger.Tracef("kademlia iterator took %s to finish", time.Since(start))
+	k.waitNextMu.Lock()
+	k.waitNext[peer.String()] = retryInfo{tryAfter: time.Now().Add(shortRetry)}
+	k.waitNextMu.Unlock()
+
+	k.connectedPeers.Add(peer, po)
+
+	k.depthMu.Lock()
+	k.depth = recalcDepth(k.connectedPeers)
+	k.depthMu.Unlock()
+
+	k.logger.Debugf("connected to peer: %s old depth: %d new depth: %d", peer, currentDepth, k.NeighborhoodDepth())
+
+	k.notifyPeerSig()
+	return nil
+}
 
-			if err != nil {
-				if errors.Is(err, errMissingAddressBookEntry) {
-					po := swarm.Proximity(k.base.Bytes(), peerToRemove.Bytes())
-					k.knownPeers.Remove(peerToRemove, po)
-				} else {
-					k.logger.Errorf("kademlia manage loop iterator: %v", err)
+// pruneOversaturatedBins disconnects surplus peers in the bins shallower than
+// the depth that have more than overSaturationPeers connected peers, so that
+// there are free slots for inbound connections. Peers are disconnected from
+// the sub-bins with the most connected peers first. Pinned peers and
+// bootnodes, which may use the reserved connection slots, are never pruned,
+// as well as neighbourhood peers which are not in the shallow bins.
+func (k *Kad) pruneOversaturatedBins(depth uint8) {
+	for bin := uint8(0); bin < depth; bin++ {
+		subBins := make(map[int][]swarm.Address)
+		size := 0
+		_ = k.connectedPeers.EachBin(func(peer swarm.Address, po uint8) (bool, bool, error) {
+			if po == bin {
+				size++
+				if !k.protected(peer) {
+					sb := subBin(peer, po, k.subBinBits)
+					subBins[sb] = append(subBins[sb], peer)
 				}
 			}
+			return false, false, nil
+		})
 
-			if k.connectedPeers.Length() == 0 {
-				k.connectBootnodes(ctx)
+		for ; size > k.overSaturationPeers && len(subBins) > 0; size-- {
+			largest := -1
+			for sb, peers := range subBins {
+				if largest == -1 || len(peers) > len(subBins[largest]) {
+					largest = sb
+				}
 			}
+			peers := subBins[largest]
+			peer := peers[len(peers)-1]
+			if len(peers) == 1 {
+				delete(subBins, largest)
+			} else {
+				subBins[largest] = peers[:len(peers)-1]
+			}
+
+			k.logger.Debugf("kademlia pruning peer %s from oversaturated bin %d", peer, bin)
+			if err := k.p2p.Disconnect(peer); err != nil {
+				k.logger.Debugf("kademlia prune peer %s: %v", peer, err)
+			}
+		}
+	}
+}
 
+// protected reports whether the peer is pinned or a bootnode, so that it is
+// not pruned.
+func (k *Kad) protected(peer swarm.Address) bool {
+	for _, p := range k.pinnedPeers {
+		if p.Equal(peer) {
+			return true
 		}
 	}
+
+	k.bootnodePeersMu.Lock()
+	defer k.bootnodePeersMu.Unlock()
+	_, ok := k.bootnodePeers[peer.ByteString()]
+	return ok
+}
+
+// connectedSubBins returns the number of connected peers in each sub-bin of
+// the bin.
+func (k *Kad) connectedSubBins(bin uint8) map[int]int {
+	subBins := make(map[int]int)
+	_ = k.connectedPeers.EachBin(func(peer swarm.Address, po uint8) (bool, bool, error) {
+		if po == bin {
+			subBins[subBin(peer, po, k.subBinBits)]++
+		}
+		return false, false, nil
+	})
+	return subBins
+}
+
+// binSize returns the number of connected peers in all sub-bins.
+func binSize(subBins map[int]int) (size int) {
+	for _, n := range subBins {
+		size += n
+	}
+	return size
+}
+
+// subBin returns the index of the sub-bin of a peer in the bin, which is
+// given by the bits that follow the first bit that differs from the base
+// address.
+func subBin(peer swarm.Address, bin uint8, bits int) int {
+	b := peer.Bytes()
+	index := 0
+	for i := 0; i < bits; i++ {
+		pos := int(bin) + 1 + i
+		index <<= 1
+		if pos/8 < len(b) {
+			index |= int(b[pos/8]>>(7-uint(pos%8))) & 1
+		}
+	}
+	return index
+}
+
+// subBinBits returns the number of address bits that divide a bin into at
+// most saturationPeers sub-bins.
+func subBinBits(saturationPeers int) int {
+	bits := 0
+	for 1<<(bits+1) <= saturationPeers {
+		bits++
+	}
+	return bits
 }
 
 func (k *Kad) Start(ctx context.Context) error {
@@ -232,6 +434,9 @@ func (k *Kad) Start(ctx context.Context) error {
 }
 
 func (k *Kad) connectBootnodes(ctx context.Context) {
+	// bootnodes are allowed to use the reserved connection slots
+	ctx = p2p.WithBootnode(ctx)
+
 	var wg sync.WaitGroup
 	for _, addr := range k.bootnodes {
 		wg.Add(1)
@@ -240,7 +445,7 @@ func (k *Kad) connectBootnodes(ctx context.Context) {
 			var count int
 			if _, err := p2p.Discover(ctx, a, func(addr ma.Multiaddr) (stop bool, err error) {
 				k.logger.Tracef("connecting to bootnode %s", addr)
-				_, err = k.p2p.ConnectNotify(ctx, addr)
+				bzzAddr, err := k.p2p.ConnectNotify(ctx, addr)
 				if err != nil {
 					if !errors.Is(err, p2p.ErrAlreadyConnected) {
 						k.logger.Debugf("connect fail %s: %v", addr, err)
@@ -249,6 +454,11 @@ func (k *Kad) connectBootnodes(ctx context.Context) {
 					return false, nil
 				}
 				k.logger.Tracef("connected to bootnode %s", addr)
+				if bzzAddr != nil {
+					k.bootnodePeersMu.Lock()
+					k.bootnodePeers[bzzAddr.Overlay.ByteString()] = struct{}{}
+					k.bootnodePeersMu.Unlock()
+				}
 				count++
 				// connect to max 3 bootnodes
 				return count > 3, nil
@@ -262,33 +472,51 @@ func (k *Kad) connectBootnodes(ctx context.Context) {
 	wg.Wait()
 }
 
-// binSaturated indicates whether a certain bin is saturated or not.
-// when a bin is not saturated it means we would like to proactively
-// initiate connections to other peers in the bin.
-func binSaturated(bin uint8, peers, connected *pslice.PSlice) bool {
-	potentialDepth := recalcDepth(peers)
-
-	// short circuit for bins which are >= depth
-	if bin >= potentialDepth {
-		return false
-	}
+// binSaturated returns a function that indicates whether a certain bin is
+// saturated or not. when a bin is not saturated it means we would like to
+// proactively initiate connections to other peers in the bin. A bin is
+// saturated when it has at least saturationPeers connected peers which cover
+// every sub-bin that has known peers.
+func binSaturated(saturationPeers int) binSaturationFunc {
+	bits := subBinBits(saturationPeers)
+	return func(bin uint8, peers, connected *pslice.PSlice) bool {
+		potentialDepth := recalcDepth(peers)
+
+		// short circuit for bins which are >= depth
+		if bin >= potentialDepth {
+			return false
+		}
 
-	// lets assume for now that the minimum number of peers in a bin
-	// would be 2, under which we would always want to connect to new peers
-	// obviously this should be replaced with a better optimization
-	// the iterator is used here since when we check if a bin is saturated,
-	// the plain number of size of bin might not suffice (for example for squared
-	// gaps measurement)
+		// the iterator is used here since when we check if a bin is saturated,
+		// the plain number of size of bin might not suffice (for example for squared
+		// gaps measurement)
 
-	size := 0
-	_ = connected.EachBin(func(_ swarm.Address, po uint8) (bool, bool, error) {
-		if po == bin {
-			size++
+		size := 0
+		covered := make(map[int]struct{})
+		_ = connected.EachBin(func(peer swarm.Address, po uint8) (bool, bool, error) {
+			if po == bin {
+				size++
+				covered[subBin(peer, po, bits)] = struct{}{}
+			}
+			return false, false, nil
+		})
+		if size < saturationPeers {
+			return false
 		}
-		return false, false, nil
-	})
 
-	return size >= saturationPeers
+		saturated := true
+		_ = peers.EachBin(func(peer swarm.Address, po uint8) (bool, bool, error) {
+			if po != bin {
+				return false, false, nil
+			}
+			if _, ok := covered[subBin(peer, po, bits)]; !ok {
+				saturated = false
+				return true, false, nil
+			}
+			return false, false, nil
+		})
+		return saturated
+	}
 }
 
 // recalcDepth calculates and returns the kademlia depth.
@@ -324,7 +552,7 @@ func recalcDepth(peers *pslice.PSlice) uint8 {
 func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr, po uint8) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
-	i, err := k.p2p.Connect(ctx, ma)
+	i, err := k.p2p.Connect(p2p.WithOverlay(ctx, peer), ma)
 	if err != nil {
 		if errors.Is(err, p2p.ErrAlreadyConnected) {
 			return nil
@@ -337,6 +565,17 @@ func (k *Kad) connect(ctx context.Context, peer swarm.Address, ma ma.Multiaddr,
 		failedAttempts := 0
 		if errors.As(err, &e) {
 			retryTime = e.TryAfter()
+		} else if errors.Is(err, p2p.ErrLightPeer) {
+			// light nodes are not part of the topology
+			k.logger.Debugf("kademlia: peer %s is a light node", peer)
+			failedAttempts = maxConnAttempts + 1
+		} else if errors.Is(err, p2p.ErrPeerBlocklisted) {
+			// keep the peer in the address book, it may be removed
+			// from the blocklist
+			k.logger.Debugf("kademlia: peer %s is blocklisted", peer)
+		} else if errors.Is(err, p2p.ErrConnectionLimit) {
+			// the peer is fine, retry when a connection slot is free
+			k.logger.Debugf("kademlia: connection limit reached, peer %s", peer)
 		} else {
 			info, ok := k.waitNext[peer.String()]
 			if ok {
@@ -492,15 +731,31 @@ func (k *Kad) notifyPeerSig() {
 	}
 }
 
-// ClosestPeer returns the closest peer to a given address.
+// ClosestPeer returns the closest peer to a given address. Peers with a
+// negative reputation score are considered farther from the address by their
+// reputation penalty, and skipped peers are not returned at all.
 func (k *Kad) ClosestPeer(addr swarm.Address) (swarm.Address, error) {
 	if k.connectedPeers.Length() == 0 {
 		return swarm.Address{}, topology.ErrNotFound
 	}
 
 	closest := k.base
+	if k.lightNode {
+		// light nodes do not store chunks, so any peer is closer than self
+		closest = swarm.ZeroAddress
+	}
+	depth := k.NeighborhoodDepth()
 	err := k.connectedPeers.EachBinRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
-		dcmp, err := swarm.DistanceCmp(addr.Bytes(), closest.Bytes(), peer.Bytes())
+		if k.skipped(peer) {
+			return false, false, nil
+		}
+
+		if closest.IsZero() {
+			closest = peer
+			return false, false, nil
+		}
+
+		dcmp, err := k.closerCmp(addr, closest, peer, depth)
 		if err != nil {
 			return false, false, err
 		}
@@ -508,7 +763,8 @@ func (k *Kad) ClosestPeer(addr swarm.Address) (swarm.Address, error) {
 		case 0:
 			// do nothing
 		case -1:
-			// current peer is closer
+			// current peer is closer, has a better score or is
+			// equally close and faster
 			closest = peer
 		case 1:
 			// closest is already closer to chunk
@@ -520,6 +776,11 @@ func (k *Kad) ClosestPeer(addr swarm.Address) (swarm.Address, error) {
 		return swarm.Address{}, err
 	}
 
+	// all peers are skipped
+	if closest.IsZero() {
+		return swarm.Address{}, topology.ErrNotFound
+	}
+
 	// check if self
 	if closest.Equal(k.base) {
 		return swarm.Address{}, topology.ErrWantSelf
@@ -528,6 +789,80 @@ func (k *Kad) ClosestPeer(addr swarm.Address) (swarm.Address, error) {
 	return closest, nil
 }
 
+// closerCmp compares peers x and y by their proximity to the address reduced
+// by their reputation penalty, then by their reputation penalty, latency and
+// distance to the address. It returns 1 if x is preferred, -1 if y is
+// preferred and 0 if they are the same.
+func (k *Kad) closerCmp(addr, x, y swarm.Address, depth uint8) (int, error) {
+	if k.reputation != nil {
+		penaltyX := reputation.Penalty(k.reputation.Score(x))
+		penaltyY := reputation.Penalty(k.reputation.Score(y))
+		px := int(swarm.Proximity(addr.Bytes(), x.Bytes())) - penaltyX
+		py := int(swarm.Proximity(addr.Bytes(), y.Bytes())) - penaltyY
+		switch {
+		case px > py:
+			return 1, nil
+		case px < py:
+			return -1, nil
+		case penaltyX < penaltyY:
+			return 1, nil
+		case penaltyX > penaltyY:
+			return -1, nil
+		}
+	}
+	if dcmp, ok := k.latencyCmp(addr, x, y, depth); ok {
+		return dcmp, nil
+	}
+	return swarm.DistanceCmp(addr.Bytes(), x.Bytes(), y.Bytes())
+}
+
+// latencyCmp compares peers x and y by their latency, if they are equally
+// close to the address, as in having the same proximity order to it, and the
+// address is outside of the neighborhood. It returns 1 if x has the lower
+// latency and -1 if y has. The result is not ok if the peers are not equally
+// close, their latencies are not known or they are the same.
+func (k *Kad) latencyCmp(addr, x, y swarm.Address, depth uint8) (int, bool) {
+	if k.latency == nil {
+		return 0, false
+	}
+	po := swarm.Proximity(addr.Bytes(), x.Bytes())
+	if po >= depth || po != swarm.Proximity(addr.Bytes(), y.Bytes()) {
+		return 0, false
+	}
+	lx, ok := k.latency.Latency(x)
+	if !ok {
+		return 0, false
+	}
+	ly, ok := k.latency.Latency(y)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case lx < ly:
+		return 1, true
+	case lx > ly:
+		return -1, true
+	}
+	return 0, false
+}
+
+// blocklisted reports whether the peer is blocklisted, so that it is not
+// dialed. Blocklisted peers are not dialed again before timeToRetry.
+func (k *Kad) blocklisted(peer swarm.Address) bool {
+	blocked, err := k.p2p.Blocklisted(peer)
+	if err != nil {
+		k.logger.Debugf("kademlia: blocklist check of peer %s: %v", peer, err)
+		return false
+	}
+	return blocked
+}
+
+// skipped reports whether the peer is temporarily excluded due to its
+// reputation score.
+func (k *Kad) skipped(peer swarm.Address) bool {
+	return k.reputation != nil && k.reputation.Skipped(peer)
+}
+
 // EachPeer iterates from closest bin to farthest
 func (k *Kad) EachPeer(f topology.EachPeerFunc) error {
 	return k.connectedPeers.EachBin(f)
@@ -589,6 +924,8 @@ func (k *Kad) marshal(indent bool) ([]byte, error) {
 		BinConnected      uint     `json:"connected"`
 		DisconnectedPeers []string `json:"disconnectedPeers"`
 		ConnectedPeers    []string `json:"connectedPeers"`
+		// Latencies maps the connected peers to their measured latency.
+		Latencies map[string]string `json:"latencies,omitempty"`
 	}
 
 	type kadBins struct {
@@ -628,6 +965,14 @@ func (k *Kad) marshal(indent bool) ([]byte, error) {
 	_ = k.connectedPeers.EachBin(func(addr swarm.Address, po uint8) (bool, bool, error) {
 		infos[po].BinConnected++
 		infos[po].ConnectedPeers = append(infos[po].ConnectedPeers, addr.String())
+		if k.latency != nil {
+			if l, ok := k.latency.Latency(addr); ok {
+				if infos[po].Latencies == nil {
+					infos[po].Latencies = make(map[string]string)
+				}
+				infos[po].Latencies[addr.String()] = l.String()
+			}
+		}
 		return false, false, nil
 	})
 
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"math/rand"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -25,6 +26,8 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	p2pmock "github.com/ethersphere/bee/pkg/p2p/mock"
+	pingpongmock "github.com/ethersphere/bee/pkg/pingpong/mock"
+	reputationmock "github.com/ethersphere/bee/pkg/reputation/mock"
 	mockstate "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/swarm/test"
@@ -35,7 +38,10 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-var nonConnectableAddress, _ = ma.NewMultiaddr(underlayBase + "16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA")
+var (
+	nonConnectableAddress, _ = ma.NewMultiaddr(underlayBase + "16Uiu2HAkx8ULY8cTXhdVAcMmLcH9AsTKz6uBQ7DPLKRjMLgBVYkA")
+	blocklistedAddress, _    = ma.NewMultiaddr(underlayBase + "16Uiu2HAmTm17toLDaPYzRyjKn27iCB76yjKnJ5DjQXneFmifFvaX")
+)
 
 // TestNeighborhoodDepth tests that the kademlia depth changes correctly
 // according to the change to known peers slice. This inadvertently tests
@@ -226,10 +232,10 @@ func TestBinSaturation(t *testing.T) {
 
 	// add two peers in a few bins to generate some depth >= 0, this will
 	// make the next iteration result in binSaturated==true, causing no new
-	// connections to be made
+	// connections to be made, as the peers cover both sub-bins of a bin
 	for i := 0; i < 5; i++ {
 		for j := 0; j < 2; j++ {
-			addr := test.RandomAddressAt(base, i)
+			addr := subBinAddress(base, uint8(i), 1, j)
 			addOne(t, signer, kad, ab, addr)
 			peers = append(peers, addr)
 		}
@@ -262,6 +268,182 @@ func TestBinSaturation(t *testing.T) {
 	waitCounter(t, &conns, 1)
 }
 
+// TestBinSaturationBalanced tests that connections in a saturated bin are
+// spread across its sub-bins.
+func TestBinSaturationBalanced(t *testing.T) {
+	var (
+		conns  int32 // how many connect calls were made to the p2p mock
+		base   = test.RandomAddress()
+		ab     = addressbook.New(mockstate.NewStateStore())
+		logger = logging.New(ioutil.Discard, 0)
+		kad    = kademlia.New(base, ab, mock.NewDiscovery(), p2pMock(ab, &conns, nil), logger, kademlia.Options{
+			SaturationPeers: 4, // 4 sub-bins in a bin
+		})
+		pk, _  = crypto.GenerateSecp256k1Key()
+		signer = beeCrypto.NewDefaultSigner(pk)
+	)
+
+	// most peers in bin 0 are in the first sub-bin
+	var peers []swarm.Address
+	for i := 0; i < 6; i++ {
+		peers = append(peers, subBinAddress(base, 0, 2, 0))
+	}
+	for i := 1; i < 4; i++ {
+		peers = append(peers, subBinAddress(base, 0, 2, i))
+	}
+	// deeper bins for the depth of 3
+	for i := 1; i < 4; i++ {
+		for j := 0; j < 2; j++ {
+			peers = append(peers, test.RandomAddressAt(base, i))
+		}
+	}
+	for _, p := range peers {
+		putOne(t, signer, ab, p)
+	}
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	// 4 peers in the saturated bin 0 and all peers in deeper bins
+	waitCounter(t, &conns, 10)
+
+	subBins := make(map[int]struct{})
+	if err := kad.EachPeer(func(addr swarm.Address, po uint8) (bool, bool, error) {
+		if po == 0 {
+			subBins[kademlia.SubBin(addr, po, 2)] = struct{}{}
+		}
+		return false, false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(subBins) != 4 {
+		t.Fatalf("got connected peers in %d sub-bins, want 4", len(subBins))
+	}
+}
+
+// TestBinSaturationUncovered tests that a bin with enough connected peers is
+// not saturated until its sub-bins with known peers are covered, and that no
+// more peers are dialed in the covered sub-bins.
+func TestBinSaturationUncovered(t *testing.T) {
+	var (
+		conns  int32 // how many connect calls were made to the p2p mock
+		base   = test.RandomAddress()
+		ab     = addressbook.New(mockstate.NewStateStore())
+		logger = logging.New(ioutil.Discard, 0)
+		kad    = kademlia.New(base, ab, mock.NewDiscovery(), p2pMock(ab, &conns, nil), logger, kademlia.Options{
+			SaturationPeers: 2, // 2 sub-bins in a bin
+		})
+		pk, _  = crypto.GenerateSecp256k1Key()
+		signer = beeCrypto.NewDefaultSigner(pk)
+	)
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	// deeper bins for the depth of 3
+	for i := 1; i < 4; i++ {
+		for j := 0; j < 2; j++ {
+			connectOne(t, signer, kad, ab, test.RandomAddressAt(base, i))
+		}
+	}
+	// bin 0 is filled with peers from a single sub-bin
+	for i := 0; i < 2; i++ {
+		connectOne(t, signer, kad, ab, subBinAddress(base, 0, 1, 0))
+	}
+
+	// a peer in the covered sub-bin is not dialed
+	addOne(t, signer, kad, ab, subBinAddress(base, 0, 1, 0))
+	waitCounter(t, &conns, 0)
+
+	// a peer in the uncovered sub-bin is dialed
+	addOne(t, signer, kad, ab, subBinAddress(base, 0, 1, 1))
+	waitCounter(t, &conns, 1)
+
+	// the bin is saturated
+	addOne(t, signer, kad, ab, subBinAddress(base, 0, 1, 1))
+	waitCounter(t, &conns, 0)
+}
+
+// TestPruneOversaturatedBins tests that surplus peers of a bin shallower than
+// depth are disconnected from the most populated sub-bins.
+func TestPruneOversaturatedBins(t *testing.T) {
+	var (
+		mtx          sync.Mutex
+		disconnected []swarm.Address
+		kad          *kademlia.Kad
+
+		base   = test.RandomAddress()
+		ab     = addressbook.New(mockstate.NewStateStore())
+		logger = logging.New(ioutil.Discard, 0)
+		p2ps   = p2pmock.New(p2pmock.WithDisconnectFunc(func(addr swarm.Address) error {
+			mtx.Lock()
+			disconnected = append(disconnected, addr)
+			mtx.Unlock()
+			kad.Disconnected(addr)
+			return nil
+		}))
+		pk, _  = crypto.GenerateSecp256k1Key()
+		signer = beeCrypto.NewDefaultSigner(pk)
+	)
+
+	pinned := subBinAddress(base, 0, 1, 0)
+	kad = kademlia.New(base, ab, mock.NewDiscovery(), p2ps, logger, kademlia.Options{
+		SaturationPeers:     2, // 2 sub-bins in a bin
+		OverSaturationPeers: 3,
+		PinnedPeers:         []swarm.Address{pinned},
+	})
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	// deeper bins for the depth of 2
+	for i := 1; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			connectOne(t, signer, kad, ab, test.RandomAddressAt(base, i))
+		}
+	}
+	connectOne(t, signer, kad, ab, subBinAddress(base, 0, 1, 1))
+	connectOne(t, signer, kad, ab, pinned)
+	for i := 0; i < 5; i++ {
+		connectOne(t, signer, kad, ab, subBinAddress(base, 0, 1, 0))
+	}
+
+	for i := 0; i < 50; i++ {
+		mtx.Lock()
+		n := len(disconnected)
+		mtx.Unlock()
+		if n >= 4 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	// give some time for unwanted disconnects to happen
+	time.Sleep(50 * time.Millisecond)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	// the pinned peer is counted in the bin, but it is never pruned
+	if len(disconnected) != 4 {
+		t.Fatalf("got %d pruned peers, want 4", len(disconnected))
+	}
+	for _, addr := range disconnected {
+		if po := swarm.Proximity(base.Bytes(), addr.Bytes()); po != 0 {
+			t.Fatalf("pruned peer %s in bin %d, want bin 0", addr, po)
+		}
+		if sb := kademlia.SubBin(addr, 0, 1); sb != 0 {
+			t.Fatalf("pruned peer %s in sub-bin %d, want sub-bin 0", addr, sb)
+		}
+		if addr.Equal(pinned) {
+			t.Fatalf("pruned pinned peer %s", addr)
+		}
+	}
+}
+
 // TestNotifierHooks tests that the Connected/Disconnected hooks
 // result in the correct behavior once called.
 func TestNotifierHooks(t *testing.T) {
@@ -459,6 +641,87 @@ func TestAddressBookPrune(t *testing.T) {
 	}
 }
 
+// TestAddressBookBlocklisted tests that blocklisted peers are not pruned from
+// the addressbook after failed connect attempts.
+func TestAddressBookBlocklisted(t *testing.T) {
+	defer func(t time.Duration) {
+		*kademlia.TimeToRetry = t
+	}(*kademlia.TimeToRetry)
+
+	*kademlia.TimeToRetry = 50 * time.Millisecond
+
+	var (
+		conns, failedConns       int32 // how many connect calls were made to the p2p mock
+		base, kad, ab, _, signer = newTestKademlia(&conns, &failedConns, nil, nil)
+	)
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	blockedPeer, err := bzz.NewAddress(signer, blocklistedAddress, test.RandomAddressAt(base, 1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.Put(blockedPeer.Overlay, *blockedPeer); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = kad.AddPeers(context.Background(), blockedPeer.Overlay)
+	waitCounter(t, &failedConns, 1)
+
+	// retry more times than the connection attempts limit
+	for i := 0; i < 4; i++ {
+		time.Sleep(50 * time.Millisecond)
+		addOne(t, signer, kad, ab, test.RandomAddressAt(base, 1))
+		waitCounter(t, &failedConns, 1)
+	}
+
+	if _, err := ab.Get(blockedPeer.Overlay); err != nil {
+		t.Fatalf("blocklisted peer pruned from addressbook: %v", err)
+	}
+}
+
+// TestBlocklistedNotDialed tests that peers which are blocklisted are not
+// dialed and that the blocklist is not checked again for them on every
+// manage iteration.
+func TestBlocklistedNotDialed(t *testing.T) {
+	var (
+		conns   int32 // how many connect calls were made to the p2p mock
+		checks  int32 // how many times the blocked peer was checked
+		base    = test.RandomAddress()
+		blocked = test.RandomAddressAt(base, 1)
+		ab      = addressbook.New(mockstate.NewStateStore())
+		p2ps    = p2pMock(ab, &conns, nil, p2pmock.WithBlocklistedFunc(func(overlay swarm.Address) (bool, error) {
+			if overlay.Equal(blocked) {
+				atomic.AddInt32(&checks, 1)
+				return true, nil
+			}
+			return false, nil
+		}))
+		kad = kademlia.New(base, ab, mock.NewDiscovery(), p2ps, logging.New(ioutil.Discard, 0), kademlia.Options{})
+	)
+
+	if err := kad.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer kad.Close()
+
+	pk, _ := crypto.GenerateSecp256k1Key()
+	signer := beeCrypto.NewDefaultSigner(pk)
+
+	addOne(t, signer, kad, ab, blocked)
+	waitCounter(t, &conns, 0)
+
+	addOne(t, signer, kad, ab, test.RandomAddressAt(base, 1))
+	waitCounter(t, &conns, 1)
+
+	if c := atomic.LoadInt32(&checks); c != 1 {
+		t.Fatalf("got %d blocklist checks of the blocked peer, want 1", c)
+	}
+}
+
 // TestClosestPeer tests that ClosestPeer method returns closest connected peer to a given address.
 func TestClosestPeer(t *testing.T) {
 	logger := logging.New(ioutil.Discard, 0)
@@ -540,6 +803,257 @@ func TestClosestPeer(t *testing.T) {
 	}
 }
 
+// TestClosestPeerReputation checks that peers with negative scores are
+// deprioritised and that skipped peers are not selected.
+func TestClosestPeerReputation(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000") // base is 0000
+	var (
+		peer0 = swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000") // binary 1000 -> po 0 to base
+		peer1 = swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000") // binary 0100 -> po 1 to base
+		peer2 = swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1 to base
+	)
+
+	for _, tc := range []struct {
+		name         string
+		reputation   []reputationmock.Option
+		chunkAddress swarm.Address
+		expectedPeer swarm.Address // zero address means self
+	}{
+		{
+			name:         "deprioritised",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer2, -10)},
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants peer 1 over peer 2
+			expectedPeer: peer1,
+		},
+		{
+			name:         "deprioritised much closer",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer2, -10)},
+			chunkAddress: swarm.MustParseHexAddress("6800000000000000000000000000000000000000000000000000000000000000"), // 01101, wants peer 2 as it is still closer
+			expectedPeer: peer2,
+		},
+		{
+			name:         "low score not deprioritised",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer2, -1)},
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants peer 2
+			expectedPeer: peer2,
+		},
+		{
+			name:         "deprioritised fallback",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer2, -10), reputationmock.WithSkipped(peer1)},
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants peer 2
+			expectedPeer: peer2,
+		},
+		{
+			name:         "skipped",
+			reputation:   []reputationmock.Option{reputationmock.WithSkipped(peer0)},
+			chunkAddress: swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000"), // 1100, wants peer 1 as peer 0 is skipped
+			expectedPeer: peer1,
+		},
+		{
+			name:         "all skipped",
+			reputation:   []reputationmock.Option{reputationmock.WithSkipped(peer0, peer1, peer2)},
+			chunkAddress: swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000"), // 1100, wants self
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			disc := mock.NewDiscovery()
+			ab := addressbook.New(mockstate.NewStateStore())
+			var conns int32
+
+			kad := kademlia.New(base, ab, disc, p2pMock(ab, &conns, nil), logger, kademlia.Options{
+				Reputation: reputationmock.NewReputation(tc.reputation...),
+			})
+			if err := kad.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer kad.Close()
+
+			pk, _ := crypto.GenerateSecp256k1Key()
+			for _, p := range []swarm.Address{peer0, peer1, peer2} {
+				connectOne(t, beeCrypto.NewDefaultSigner(pk), kad, ab, p)
+			}
+
+			peer, err := kad.ClosestPeer(tc.chunkAddress)
+			if tc.expectedPeer.IsZero() {
+				if !errors.Is(err, topology.ErrWantSelf) {
+					t.Fatalf("got error %v, want %v", err, topology.ErrWantSelf)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !peer.Equal(tc.expectedPeer) {
+				t.Fatalf("got peer %s, want %s", peer, tc.expectedPeer)
+			}
+		})
+	}
+}
+
+// TestClosestPeerLatency checks that among peers with the same proximity order
+// to a chunk outside of the neighborhood, the one with the lower latency is
+// chosen.
+func TestClosestPeerLatency(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000") // base is 0000
+	var (
+		peer0 = swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000") // binary 1000 -> po 0 to base
+		peer1 = swarm.MustParseHexAddress("a000000000000000000000000000000000000000000000000000000000000000") // binary 1010 -> po 0 to base
+		peers = []swarm.Address{
+			peer0,
+			peer1,
+			swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000"), // binary 0100 -> po 1 to base
+			swarm.MustParseHexAddress("2000000000000000000000000000000000000000000000000000000000000000"), // binary 0010 -> po 2 to base
+			swarm.MustParseHexAddress("3000000000000000000000000000000000000000000000000000000000000000"), // binary 0011 -> po 2 to base
+			swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000"), // binary 0001 -> po 3 to base
+		}
+	)
+
+	for _, tc := range []struct {
+		name         string
+		latencies    map[string]time.Duration
+		chunkAddress swarm.Address
+		expectedPeer swarm.Address // zero address means self
+	}{
+		{
+			name:         "closest",
+			chunkAddress: swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000"), // 1100, po 1 to both peers, peer 0 is closer
+			expectedPeer: peer0,
+		},
+		{
+			name:         "lower latency",
+			latencies:    map[string]time.Duration{peer0.String(): 80 * time.Millisecond, peer1.String(): 20 * time.Millisecond},
+			chunkAddress: swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000"), // 1100, wants faster peer 1
+			expectedPeer: peer1,
+		},
+		{
+			name:         "unknown latency",
+			latencies:    map[string]time.Duration{peer1.String(): 20 * time.Millisecond},
+			chunkAddress: swarm.MustParseHexAddress("c000000000000000000000000000000000000000000000000000000000000000"), // 1100, wants closer peer 0
+			expectedPeer: peer0,
+		},
+		{
+			name:         "different proximity",
+			latencies:    map[string]time.Duration{peer0.String(): 80 * time.Millisecond, peer1.String(): 20 * time.Millisecond},
+			chunkAddress: swarm.MustParseHexAddress("9000000000000000000000000000000000000000000000000000000000000000"), // 1001, po 3 to peer 0 and po 2 to peer 1
+			expectedPeer: peer0,
+		},
+		{
+			name:         "neighborhood",
+			latencies:    map[string]time.Duration{peers[3].String(): 80 * time.Millisecond, peers[4].String(): 20 * time.Millisecond},
+			chunkAddress: swarm.MustParseHexAddress("0800000000000000000000000000000000000000000000000000000000000000"), // 0000 1000, wants self
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			disc := mock.NewDiscovery()
+			ab := addressbook.New(mockstate.NewStateStore())
+			var conns int32
+
+			latency := pingpongmock.New(nil)
+			for k, v := range tc.latencies {
+				latency.SetLatency(swarm.MustParseHexAddress(k), v)
+			}
+
+			kad := kademlia.New(base, ab, disc, p2pMock(ab, &conns, nil), logger, kademlia.Options{
+				Latency: latency,
+			})
+			if err := kad.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer kad.Close()
+
+			pk, _ := crypto.GenerateSecp256k1Key()
+			for _, p := range peers {
+				connectOne(t, beeCrypto.NewDefaultSigner(pk), kad, ab, p)
+			}
+			if d := kad.NeighborhoodDepth(); d != 2 {
+				t.Fatalf("got depth %d, want 2", d)
+			}
+
+			peer, err := kad.ClosestPeer(tc.chunkAddress)
+			if tc.expectedPeer.IsZero() {
+				if !errors.Is(err, topology.ErrWantSelf) {
+					t.Fatalf("got error %v, want %v", err, topology.ErrWantSelf)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !peer.Equal(tc.expectedPeer) {
+				t.Fatalf("got peer %s, want %s", peer, tc.expectedPeer)
+			}
+		})
+	}
+}
+
+// TestClosestPeerLightNode tests that a light node never chooses itself as
+// the closest node to a chunk.
+func TestClosestPeerLightNode(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	base := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000") // base is 0000
+	var (
+		peer0 = swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000") // binary 1000 -> po 0 to base
+		peer1 = swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000") // binary 0100 -> po 1 to base
+	)
+
+	for _, tc := range []struct {
+		name         string
+		reputation   []reputationmock.Option
+		expectedPeer swarm.Address // zero address means not found
+	}{
+		{
+			name:         "closest peer",
+			expectedPeer: peer1,
+		},
+		{
+			name:         "deprioritised",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer1, -10)},
+			expectedPeer: peer0,
+		},
+		{
+			name:       "all skipped",
+			reputation: []reputationmock.Option{reputationmock.WithSkipped(peer0, peer1)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			disc := mock.NewDiscovery()
+			ab := addressbook.New(mockstate.NewStateStore())
+			var conns int32
+
+			kad := kademlia.New(base, ab, disc, p2pMock(ab, &conns, nil), logger, kademlia.Options{
+				Reputation: reputationmock.NewReputation(tc.reputation...),
+				LightNode:  true,
+			})
+			if err := kad.Start(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer kad.Close()
+
+			pk, _ := crypto.GenerateSecp256k1Key()
+			for _, p := range []swarm.Address{peer0, peer1} {
+				connectOne(t, beeCrypto.NewDefaultSigner(pk), kad, ab, p)
+			}
+
+			// the chunk is closest to the base, a full node would want self
+			peer, err := kad.ClosestPeer(swarm.MustParseHexAddress("1000000000000000000000000000000000000000000000000000000000000000"))
+			if tc.expectedPeer.IsZero() {
+				if !errors.Is(err, topology.ErrNotFound) {
+					t.Fatalf("got error %v, want %v", err, topology.ErrNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !peer.Equal(tc.expectedPeer) {
+				t.Fatalf("got peer %s, want %s", peer, tc.expectedPeer)
+			}
+		})
+	}
+}
+
 func TestKademlia_SubscribePeersChange(t *testing.T) {
 	testSignal := func(t *testing.T, k *kademlia.Kad, c <-chan struct{}) {
 		t.Helper()
@@ -740,12 +1254,16 @@ func newTestKademlia(connCounter, failedConnCounter *int32, f func(bin uint8, pe
 	return base, kad, ab, disc, beeCrypto.NewDefaultSigner(pk)
 }
 
-func p2pMock(ab addressbook.Interface, counter, failedCounter *int32) p2p.Service {
-	p2ps := p2pmock.New(p2pmock.WithConnectFunc(func(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+func p2pMock(ab addressbook.Interface, counter, failedCounter *int32, opts ...p2pmock.Option) p2p.Service {
+	p2ps := p2pmock.New(append(opts, p2pmock.WithConnectFunc(func(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
 		if addr.Equal(nonConnectableAddress) {
 			_ = atomic.AddInt32(failedCounter, 1)
 			return nil, errors.New("non reachable node")
 		}
+		if addr.Equal(blocklistedAddress) {
+			_ = atomic.AddInt32(failedCounter, 1)
+			return nil, p2p.ErrPeerBlocklisted
+		}
 		if counter != nil {
 			_ = atomic.AddInt32(counter, 1)
 		}
@@ -762,7 +1280,7 @@ func p2pMock(ab addressbook.Interface, counter, failedCounter *int32) p2p.Servic
 		}
 
 		return nil, nil
-	}))
+	}))...)
 
 	return p2ps
 }
@@ -791,6 +1309,12 @@ func connectOne(t *testing.T, signer beeCrypto.Signer, k *kademlia.Kad, ab addre
 }
 
 func addOne(t *testing.T, signer beeCrypto.Signer, k *kademlia.Kad, ab addressbook.Putter, peer swarm.Address) {
+	t.Helper()
+	putOne(t, signer, ab, peer)
+	_ = k.AddPeers(context.Background(), peer)
+}
+
+func putOne(t *testing.T, signer beeCrypto.Signer, ab addressbook.Putter, peer swarm.Address) {
 	t.Helper()
 	multiaddr, err := ma.NewMultiaddr(underlayBase + peer.String())
 	if err != nil {
@@ -803,7 +1327,22 @@ func addOne(t *testing.T, signer beeCrypto.Signer, k *kademlia.Kad, ab addressbo
 	if err := ab.Put(peer, *bzzAddr); err != nil {
 		t.Fatal(err)
 	}
-	_ = k.AddPeers(context.Background(), peer)
+}
+
+// subBinAddress returns a random address in the bin with the bits that follow
+// the bin prefix set to the sub-bin index.
+func subBinAddress(base swarm.Address, bin uint8, bits, subBin int) swarm.Address {
+	b := test.RandomAddressAt(base, int(bin)).Bytes()
+	for i := 0; i < bits; i++ {
+		pos := int(bin) + 1 + i
+		mask := byte(1) << (7 - uint(pos%8))
+		if subBin>>(bits-1-i)&1 == 1 {
+			b[pos/8] |= mask
+		} else {
+			b[pos/8] &^= mask
+		}
+	}
+	return swarm.NewAddress(b)
 }
 
 func add(t *testing.T, signer beeCrypto.Signer, k *kademlia.Kad, ab addressbook.Putter, peers []swarm.Address, offset, number int) {
@@ -46,7 +46,20 @@ continuous iterations over Chunks that should be provided to
 Push and Pull syncing.
 
 DB implements an internal garbage collector that removes only synced
-Chunks from the database based on their most recent access time.
+Chunks from the database in the order defined by a GCPolicy, by default
+based on their most recent access time. Garbage collection can be
+triggered or paused and the capacity can be changed while DB is open.
+
+If a Validator is provided, DB periodically validates all stored Chunks
+and removes the corrupt ones. Corrupt pinned Chunks are quarantined until
+they are retrieved from the network.
+
+The database of a stopped node can be opened with the Maintenance option
+to validate and repair indexes derived from the retrieval data index.
+
+A DB opened with the ReadOnly option serves stored Chunks without any
+changes, and a DB with the CacheOnly option stores only Chunks retrieved
+from the network, which are not pull synced.
 
 Internally, DB stores Chunk data and any required information, such as
 store and access timestamps in different shed indexes that can be
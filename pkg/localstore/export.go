@@ -20,10 +20,10 @@ import (
 	"archive/tar"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"sync"
 
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -38,12 +38,22 @@ const (
 	currentExportVersion = "1"
 )
 
+// importBatchSize is the maximal number of chunks
+// that are stored in a single put on import.
+var importBatchSize = 100
+
 // Export writes a tar structured data to the writer of
-// all chunks in the retrieval data index. It returns the
-// number of chunks exported.
-func (db *DB) Export(w io.Writer) (count int64, err error) {
+// chunks in the retrieval data index that are selected by
+// the filter. It returns the number of chunks exported.
+func (db *DB) Export(w io.Writer, f storage.ExportFilter) (count uint64, err error) {
 	tw := tar.NewWriter(w)
-	defer tw.Close()
+	// the tar trailer is not written after an error,
+	// so that a partial export is not a valid archive
+	defer func() {
+		if err == nil {
+			err = tw.Close()
+		}
+	}()
 
 	if err := tw.WriteHeader(&tar.Header{
 		Name: exportVersionFilename,
@@ -56,7 +66,42 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		return 0, err
 	}
 
-	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+	var bins map[uint8]bool
+	if len(f.Bins) > 0 {
+		bins = make(map[uint8]bool, len(f.Bins))
+		for _, bin := range f.Bins {
+			bins[bin] = true
+		}
+	}
+	var since, until int64
+	if !f.Since.IsZero() {
+		since = f.Since.UnixNano()
+	}
+	if !f.Until.IsZero() {
+		until = f.Until.UnixNano()
+	}
+
+	// pinned chunks are found in the smaller index
+	index := db.retrievalDataIndex
+	if f.Pinned {
+		index = db.pinIndex
+	}
+	err = index.Iterate(func(item shed.Item) (stop bool, err error) {
+		if bins != nil && !bins[db.po(swarm.NewAddress(item.Address))] {
+			return false, nil
+		}
+		// read the data of the chunk that is not
+		// removed since the iteration started
+		item, err = db.getData(item)
+		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				return false, nil
+			}
+			return true, err
+		}
+		if item.StoreTimestamp < since || until != 0 && item.StoreTimestamp >= until {
+			return false, nil
+		}
 
 		hdr := &tar.Header{
 			Name: hex.EncodeToString(item.Address),
@@ -65,10 +110,10 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		}
 
 		if err := tw.WriteHeader(hdr); err != nil {
-			return false, err
+			return true, err
 		}
 		if _, err := tw.Write(item.Data); err != nil {
-			return false, err
+			return true, err
 		}
 		count++
 		return false, nil
@@ -78,123 +123,87 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 }
 
 // Import reads a tar structured data from the reader and
-// stores chunks in the database. It returns the number of
-// chunks imported.
-func (db *DB) Import(r io.Reader, legacy bool) (count int64, err error) {
+// stores chunks in the database. Chunks are stored as
+// uploaded if they should be push synced, and as synced
+// from other nodes otherwise, so that they are pull synced.
+// Invalid chunks are skipped if the validator is set.
+func (db *DB) Import(r io.Reader, o storage.ImportOptions) (result storage.ImportResult, err error) {
 	tr := tar.NewReader(r)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errC := make(chan error)
-	doneC := make(chan struct{})
-	tokenPool := make(chan struct{}, 100)
-	var wg sync.WaitGroup
-	go func() {
-		var (
-			firstFile = true
-
-			// if exportVersionFilename file is not present
-			// assume current version
-			version = currentExportVersion
-		)
-		for {
-			hdr, err := tr.Next()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
-			}
-			if firstFile {
-				firstFile = false
-				if hdr.Name == exportVersionFilename {
-					data, err := ioutil.ReadAll(tr)
-					if err != nil {
-						select {
-						case errC <- err:
-						case <-ctx.Done():
-						}
-					}
-					version = string(data)
-					continue
-				}
-			}
+	mode := storage.ModePutSync
+	if o.PushSync {
+		mode = storage.ModePutUpload
+	}
+	var chunks []swarm.Chunk
+	put := func() error {
+		if len(chunks) == 0 {
+			return nil
+		}
+		if _, err := db.Put(context.Background(), mode, chunks...); err != nil {
+			return err
+		}
+		result.Imported += uint64(len(chunks))
+		chunks = chunks[:0]
+		return nil
+	}
 
-			if len(hdr.Name) != 64 {
-				db.logger.Warningf("localstore export: ignoring non-chunk file: %s", hdr.Name)
-				continue
+	// if exportVersionFilename file is not
+	// present assume current version
+	version := currentExportVersion
+	for first := true; ; first = false {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
 			}
-
-			keybytes, err := hex.DecodeString(hdr.Name)
+			return result, err
+		}
+		// entries are read in full, their size is limited
+		// before they are read, so that a large entry does
+		// not exhaust the memory
+		if hdr.Size > slotSize {
+			return result, fmt.Errorf("file %s of %d bytes exceeds %d bytes: %w", hdr.Name, hdr.Size, slotSize, storage.ErrInvalidImport)
+		}
+		if first && hdr.Name == exportVersionFilename {
+			data, err := ioutil.ReadAll(io.LimitReader(tr, slotSize))
 			if err != nil {
-				db.logger.Warningf("localstore export: ignoring invalid chunk file %s: %v", hdr.Name, err)
-				continue
+				return result, err
 			}
+			version = string(data)
+			continue
+		}
+		if version != currentExportVersion {
+			return result, fmt.Errorf("unsupported export data version %q", version)
+		}
 
-			data, err := ioutil.ReadAll(tr)
-			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
-			}
-			key := swarm.NewAddress(keybytes)
-
-			var ch swarm.Chunk
-			switch version {
-			case currentExportVersion:
-				ch = swarm.NewChunk(key, data)
-			default:
-				select {
-				case errC <- fmt.Errorf("unsupported export data version %q", version):
-				case <-ctx.Done():
-				}
-			}
-			tokenPool <- struct{}{}
-			wg.Add(1)
-
-			go func() {
-				_, err := db.Put(ctx, storage.ModePutUpload, ch)
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-					wg.Done()
-					<-tokenPool
-				default:
-					_, err := db.Put(ctx, storage.ModePutUpload, ch)
-					if err != nil {
-						errC <- err
-					}
-					wg.Done()
-					<-tokenPool
-				}
-			}()
-
-			count++
-		}
-		wg.Wait()
-		close(doneC)
-	}()
+		if len(hdr.Name) != 64 {
+			db.logger.Warningf("localstore export: ignoring non-chunk file: %s", hdr.Name)
+			continue
+		}
 
-	// wait for all chunks to be stored
-	for {
-		select {
-		case err := <-errC:
-			if err != nil {
-				return count, err
-			}
-		case <-ctx.Done():
-			return count, ctx.Err()
-		default:
-			select {
-			case <-doneC:
-				return count, nil
-			default:
+		keybytes, err := hex.DecodeString(hdr.Name)
+		if err != nil {
+			db.logger.Warningf("localstore export: ignoring invalid chunk file %s: %v", hdr.Name, err)
+			continue
+		}
+
+		data, err := ioutil.ReadAll(io.LimitReader(tr, slotSize))
+		if err != nil {
+			return result, err
+		}
+		ch := swarm.NewChunk(swarm.NewAddress(keybytes), data)
+		if o.Validator != nil && !o.Validator.Validate(ch) {
+			db.logger.Debugf("localstore import: ignoring invalid chunk %s", ch.Address())
+			result.Invalid++
+			continue
+		}
+
+		chunks = append(chunks, ch)
+		if len(chunks) >= importBatchSize {
+			if err := put(); err != nil {
+				return result, err
 			}
 		}
 	}
+	return result, put()
 }
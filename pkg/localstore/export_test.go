@@ -17,10 +17,15 @@
 package localstore
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/content"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -46,23 +51,23 @@ func TestExportImport(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	c, err := db1.Export(&buf)
+	c, err := db1.Export(&buf, storage.ExportFilter{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	wantChunksCount := int64(len(chunks))
+	wantChunksCount := uint64(len(chunks))
 	if c != wantChunksCount {
 		t.Errorf("got export count %v, want %v", c, wantChunksCount)
 	}
 
 	db2 := newTestDB(t, nil)
 
-	c, err = db2.Import(&buf, false)
+	r, err := db2.Import(&buf, storage.ImportOptions{PushSync: true})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if c != wantChunksCount {
-		t.Errorf("got import count %v, want %v", c, wantChunksCount)
+	if r.Imported != wantChunksCount {
+		t.Errorf("got import count %v, want %v", r.Imported, wantChunksCount)
 	}
 
 	for a, want := range chunks {
@@ -77,3 +82,195 @@ func TestExportImport(t *testing.T) {
 		}
 	}
 }
+
+// TestExportFilter validates that only the chunks selected by the
+// filter are exported.
+func TestExportFilter(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	chunks := generateTestRandomChunks(50)
+	defer setNow(func() int64 { return 1000 })()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks[:25]...); err != nil {
+		t.Fatal(err)
+	}
+	setNow(func() int64 { return 2000 })
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks[25:]...); err != nil {
+		t.Fatal(err)
+	}
+	pinned := chunkAddresses(chunks[20:30])
+	if err := db.Set(context.Background(), storage.ModeSetPin, pinned...); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := db.po(chunks[0].Address())
+	var inBin []swarm.Address
+	for _, ch := range chunks {
+		if db.po(ch.Address()) == bin {
+			inBin = append(inBin, ch.Address())
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter storage.ExportFilter
+		want   []swarm.Address
+	}{
+		{
+			name: "none",
+			want: chunkAddresses(chunks),
+		},
+		{
+			name:   "bins",
+			filter: storage.ExportFilter{Bins: []uint8{bin}},
+			want:   inBin,
+		},
+		{
+			name:   "pinned",
+			filter: storage.ExportFilter{Pinned: true},
+			want:   pinned,
+		},
+		{
+			name:   "since",
+			filter: storage.ExportFilter{Since: time.Unix(0, 1500)},
+			want:   chunkAddresses(chunks[25:]),
+		},
+		{
+			name:   "until",
+			filter: storage.ExportFilter{Until: time.Unix(0, 2000)},
+			want:   chunkAddresses(chunks[:25]),
+		},
+		{
+			name: "pinned in time range",
+			filter: storage.ExportFilter{
+				Pinned: true,
+				Since:  time.Unix(0, 2000),
+				Until:  time.Unix(0, 2001),
+			},
+			want: pinned[5:],
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c, err := db.Export(&buf, tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c != uint64(len(tc.want)) {
+				t.Errorf("got export count %v, want %v", c, len(tc.want))
+			}
+
+			got := newTestDB(t, nil)
+			if _, err := got.Import(&buf, storage.ImportOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			for _, addr := range tc.want {
+				if has, _ := got.Has(context.Background(), addr); !has {
+					t.Errorf("chunk %s not exported", addr)
+				}
+			}
+		})
+	}
+}
+
+// TestImportOptions validates that invalid chunks are skipped and that
+// imported chunks are stored in the mode defined by the options.
+func TestImportOptions(t *testing.T) {
+	db1 := newTestDB(t, nil)
+
+	valid := generateTestContentChunks(t, 5)
+	invalid := generateTestRandomChunks(3)
+	if _, err := db1.Put(context.Background(), storage.ModePutUpload, append(valid, invalid...)...); err != nil {
+		t.Fatal(err)
+	}
+	var export bytes.Buffer
+	if _, err := db1.Export(&export, storage.ExportFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		pushSync  bool
+		pushCount int
+	}{
+		{
+			name:      "push sync",
+			pushSync:  true,
+			pushCount: len(valid),
+		},
+		{
+			name: "no push sync",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db2 := newTestDB(t, nil)
+
+			r, err := db2.Import(bytes.NewReader(export.Bytes()), storage.ImportOptions{
+				Validator: content.NewValidator(),
+				PushSync:  tc.pushSync,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := storage.ImportResult{
+				Imported: uint64(len(valid)),
+				Invalid:  uint64(len(invalid)),
+			}
+			if r != want {
+				t.Errorf("got import result %+v, want %+v", r, want)
+			}
+			t.Run("retrieval data index count", newItemsCountTest(db2.retrievalDataIndex, len(valid)))
+			t.Run("push index count", newItemsCountTest(db2.pushIndex, tc.pushCount))
+			t.Run("pull index count", newItemsCountTest(db2.pullIndex, len(valid)))
+			t.Run("gc index count", newItemsCountTest(db2.gcIndex, 0))
+			t.Run("gc size", newIndexGCSizeTest(db2))
+		})
+	}
+}
+
+// TestImportSizeLimit validates that files in the import archive which are
+// larger than a chunk are rejected.
+func TestImportSizeLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		file string
+	}{
+		{
+			name: "chunk",
+			file: strings.Repeat("a", 64),
+		},
+		{
+			name: "version",
+			file: exportVersionFilename,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			data := make([]byte, slotSize+1)
+			if err := tw.WriteHeader(&tar.Header{
+				Name: tc.file,
+				Mode: 0644,
+				Size: int64(len(data)),
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			db := newTestDB(t, nil)
+
+			r, err := db.Import(&buf, storage.ImportOptions{})
+			if !errors.Is(err, storage.ErrInvalidImport) {
+				t.Fatalf("got error %v, want %v", err, storage.ErrInvalidImport)
+			}
+			if r.Imported != 0 {
+				t.Errorf("got %v imported chunks, want none", r.Imported)
+			}
+			t.Run("retrieval data index count", newItemsCountTest(db.retrievalDataIndex, 0))
+		})
+	}
+}
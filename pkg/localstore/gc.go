@@ -19,10 +19,11 @@ package localstore
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/shed"
-	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/ethersphere/bee/pkg/slotstore"
 )
 
 var (
@@ -47,19 +48,60 @@ var (
 func (db *DB) collectGarbageWorker() {
 	defer close(db.collectGarbageWorkerDone)
 
+	// number of chunks collected in all batches
+	// of the current garbage collection run
+	var runCollectedCount uint64
+	// the last error of the current garbage collection run
+	var runErr error
+	// a garbage collection run is in progress
+	var running bool
+
+	endRun := func() {
+		db.endGCRun(runCollectedCount, runErr)
+		runCollectedCount = 0
+		runErr = nil
+		running = false
+	}
+
 	for {
 		select {
 		case <-db.collectGarbageTrigger:
+			// skip runs while garbage collection is paused,
+			// it is triggered again when it is resumed
+			if db.isGCPaused() {
+				if running {
+					endRun()
+					db.gcRunInProgress = false
+				}
+				continue
+			}
+			if !running {
+				db.startGCRun()
+				running = true
+			}
+
 			// run a single collect garbage run and
 			// if done is false, gcBatchSize is reached and
 			// another collect garbage run is needed
 			collectedCount, done, err := db.collectGarbage()
 			if err != nil {
 				db.logger.Errorf("localstore: collect garbage: %v", err)
+				runErr = err
 			}
+			runCollectedCount += collectedCount
 			// check if another gc run is needed
 			if !done {
 				db.triggerGarbageCollection()
+			} else {
+				if runCollectedCount > 0 && db.capacityBytes > 0 {
+					// the slots of removed chunks are free, so the
+					// usage is measured without compacting the
+					// indexes, which leveldb does in the background
+					if err := db.updateDiskUsage(); err != nil {
+						db.logger.Errorf("localstore: collect garbage: %v", err)
+					}
+				}
+				endRun()
 			}
 
 			if testHookCollectGarbage != nil {
@@ -86,8 +128,7 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		}
 	}()
 
-	batch := new(leveldb.Batch)
-	target := db.gcTarget()
+	batch := db.shed.NewBatch()
 
 	// protect database from changing idexes and gcSize
 	db.batchMu.Lock()
@@ -100,13 +141,37 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		return 0, true, fmt.Errorf("remove chunks in exclude index: %v", err)
 	}
 
+	// move chunks out of the storage radius to the gc index
+	// if the reserve is full, before collecting them, releasing
+	// the lock between the batches
+	evicted, err := db.evictReserve()
+	if err != nil {
+		return 0, true, fmt.Errorf("evict reserve: %w", err)
+	}
+	if !evicted {
+		return 0, false, nil
+	}
+
 	gcSize, err := db.gcSize.Get()
 	if err != nil {
 		return 0, true, err
 	}
 	db.metrics.GCSize.Inc()
 
+	target := db.gcTarget()
+	if db.capacityBytes > 0 {
+		// the target is fixed for the whole run, as the disk
+		// usage is updated only after the run is done
+		if !db.gcRunInProgress {
+			db.gcRunTarget = db.gcBytesTarget(gcSize)
+		}
+		if db.gcRunTarget < target {
+			target = db.gcRunTarget
+		}
+	}
+
 	done = true
+	var released []slotstore.Location
 	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
 		if gcSize-collectedCount <= target {
 			return true, nil
@@ -115,6 +180,17 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		db.metrics.GCStoreTimeStamps.Set(float64(item.StoreTimestamp))
 		db.metrics.GCStoreAccessTimeStamps.Set(float64(item.AccessTimestamp))
 
+		// get the slot of chunk data to release
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			return true, nil
+		}
+		loc, err := location(i)
+		if err != nil {
+			return true, nil
+		}
+		released = append(released, loc)
+
 		// delete from retrieve, pull, gc
 		err = db.retrievalDataIndex.DeleteInBatch(batch, item)
 		if err != nil {
@@ -147,11 +223,12 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	db.metrics.GCCollectedCounter.Inc()
 
 	db.gcSize.PutInBatch(batch, gcSize-collectedCount)
-	err = db.shed.WriteBatch(batch)
+	err = db.writeBatchAndRelease(batch, released)
 	if err != nil {
 		db.metrics.GCExcludeWriteBatchError.Inc()
 		return 0, false, err
 	}
+	db.gcRunInProgress = !done
 	return collectedCount, done, nil
 }
 
@@ -165,9 +242,9 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 		}
 	}()
 
-	batch := new(leveldb.Batch)
+	batch := db.shed.NewBatch()
 	excludedCount := 0
-	var gcSizeChange int64
+	var gcSizeChange, reserveSizeChange int64
 	err = db.gcExcludeIndex.Iterate(func(item shed.Item) (stop bool, err error) {
 		// Get access timestamp
 		retrievalAccessIndexItem, err := db.retrievalAccessIndex.Get(item)
@@ -175,6 +252,7 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 			return false, err
 		}
 		item.AccessTimestamp = retrievalAccessIndexItem.AccessTimestamp
+		item.AccessCount = retrievalAccessIndexItem.AccessCount
 
 		// Get the binId
 		retrievalDataIndexItem, err := db.retrievalDataIndex.Get(item)
@@ -183,19 +261,14 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 		}
 		item.BinID = retrievalDataIndexItem.BinID
 
-		// Check if this item is in gcIndex and remove it
-		ok, err := db.gcIndex.Has(item)
+		// Check if this item is in gcIndex or reserveIndex and remove it
+		gcChange, reserveChange, err := db.deleteFromGCOrReserve(batch, item)
 		if err != nil {
 			return false, nil
 		}
-		if ok {
-			err = db.gcIndex.DeleteInBatch(batch, item)
-			if err != nil {
-				return false, nil
-			}
-			if _, err := db.gcIndex.Get(item); err == nil {
-				gcSizeChange--
-			}
+		if gcChange != 0 || reserveChange != 0 {
+			gcSizeChange += gcChange
+			reserveSizeChange += reserveChange
 			excludedCount++
 			err = db.gcExcludeIndex.DeleteInBatch(batch, item)
 			if err != nil {
@@ -209,11 +282,15 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 		return err
 	}
 
-	// update the gc size based on the no of entries deleted in gcIndex
+	// update the gc and reserve sizes based on the no of entries deleted
 	err = db.incGCSizeInBatch(batch, gcSizeChange)
 	if err != nil {
 		return err
 	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return err
+	}
 
 	db.metrics.GCExcludeCounter.Inc()
 	err = db.shed.WriteBatch(batch)
@@ -226,9 +303,40 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 }
 
 // gcTrigger retruns the absolute value for garbage collection
-// target value, calculated from db.capacity and gcTargetRatio.
+// target value, calculated from db.cacheCapacity and gcTargetRatio.
+// This function must be called under batchMu lock.
 func (db *DB) gcTarget() (target uint64) {
-	return uint64(float64(db.capacity) * gcTargetRatio)
+	return uint64(float64(db.cacheCapacity()) * gcTargetRatio)
+}
+
+// cacheCapacity returns the maximal number of items in gc index,
+// the part of db.capacity that is not reserved. This function
+// must be called under batchMu lock.
+func (db *DB) cacheCapacity() uint64 {
+	return db.capacity - db.reserveCapacity
+}
+
+// gcBytesTarget returns the number of items in gc index that
+// should remain after garbage collection to reduce the database
+// size on disk to gcTargetRatio of db.capacityBytes. The disk usage
+// is attributed to gc index items evenly, which overestimates their
+// size if there are pinned or unsynced chunks, so that the target
+// may not be reached in a single run.
+func (db *DB) gcBytesTarget(gcSize uint64) (target uint64) {
+	usage := atomic.LoadUint64(&db.diskUsage)
+	limit := uint64(float64(db.capacityBytes) * gcTargetRatio)
+	if usage <= limit || gcSize == 0 {
+		return gcSize
+	}
+	itemSize := usage / gcSize
+	if itemSize == 0 {
+		return gcSize
+	}
+	remove := (usage - limit + itemSize - 1) / itemSize
+	if remove >= gcSize {
+		return 0
+	}
+	return gcSize - remove
 }
 
 // triggerGarbageCollection signals collectGarbageWorker
@@ -244,12 +352,12 @@ func (db *DB) triggerGarbageCollection() {
 // incGCSizeInBatch changes gcSize field value
 // by change which can be negative. This function
 // must be called under batchMu lock.
-func (db *DB) incGCSizeInBatch(batch *leveldb.Batch, change int64) (err error) {
+func (db *DB) incGCSizeInBatch(batch shed.Batch, change int64) (err error) {
 	if change == 0 {
 		return nil
 	}
 	gcSize, err := db.gcSize.Get()
-	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
 		return err
 	}
 
@@ -269,7 +377,7 @@ func (db *DB) incGCSizeInBatch(batch *leveldb.Batch, change int64) (err error) {
 	db.gcSize.PutInBatch(batch, newSize)
 
 	// trigger garbage collection if we reached the capacity
-	if newSize >= db.capacity {
+	if newSize >= db.cacheCapacity() || db.capacityBytesReached() {
 		db.triggerGarbageCollection()
 	}
 	return nil
@@ -0,0 +1,218 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// GCPolicy defines the order in which chunks are garbage collected.
+type GCPolicy interface {
+	// Name identifies the policy. The gc index is rebuilt when the
+	// database is opened with a policy of a different name than the
+	// one that built the index.
+	Name() string
+	// Key returns the part of the gc index key that orders the chunk
+	// for garbage collection, chunks with lower keys are collected
+	// first. Item fields Address, AccessTimestamp and AccessCount are
+	// set and po is the proximity order of the chunk address to the
+	// base key. Keys of all chunks must have the same length.
+	Key(item shed.Item, po uint8) []byte
+}
+
+var (
+	// LRUGCPolicy collects the least recently accessed chunks first.
+	LRUGCPolicy GCPolicy = lruGCPolicy{}
+	// LFUGCPolicy collects the least frequently accessed chunks first,
+	// and the least recently accessed ones among chunks accessed the
+	// same number of times.
+	LFUGCPolicy GCPolicy = lfuGCPolicy{}
+	// ProximityGCPolicy collects the chunks that are the most distant
+	// from the base key first, and the least recently accessed ones
+	// within the same proximity order bin.
+	ProximityGCPolicy GCPolicy = proximityGCPolicy{}
+)
+
+// gcPolicies are the policies that the gc index can be rebuilt from.
+var gcPolicies = []GCPolicy{
+	LRUGCPolicy,
+	LFUGCPolicy,
+	ProximityGCPolicy,
+}
+
+// ErrUnknownGCPolicy is returned if there is no gc policy with a
+// provided name.
+var ErrUnknownGCPolicy = errors.New("unknown gc policy")
+
+// GCPolicyByName returns one of the gc policies provided by this
+// package by its name.
+func GCPolicyByName(name string) (GCPolicy, error) {
+	for _, p := range gcPolicies {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownGCPolicy, name)
+}
+
+type lruGCPolicy struct{}
+
+func (lruGCPolicy) Name() string { return "lru" }
+
+func (lruGCPolicy) Key(item shed.Item, _ uint8) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(item.AccessTimestamp))
+	return key
+}
+
+type lfuGCPolicy struct{}
+
+func (lfuGCPolicy) Name() string { return "lfu" }
+
+func (lfuGCPolicy) Key(item shed.Item, _ uint8) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], item.AccessCount)
+	binary.BigEndian.PutUint64(key[8:], uint64(item.AccessTimestamp))
+	return key
+}
+
+type proximityGCPolicy struct{}
+
+func (proximityGCPolicy) Name() string { return "proximity" }
+
+func (proximityGCPolicy) Key(item shed.Item, po uint8) []byte {
+	key := make([]byte, 9)
+	key[0] = po
+	binary.BigEndian.PutUint64(key[1:], uint64(item.AccessTimestamp))
+	return key
+}
+
+// gcIndexName is the name of the gc index for all policies as its
+// items are decoded in the same way regardless of the policy.
+const gcIndexName = "GCPolicyKey|BinID|Hash->AccessTimestamp|AccessCount"
+
+// newGCIndex returns the gc index with keys ordered by the provided
+// policy. Address and BinID are the suffix of every key so that the
+// items can be decoded without knowing the policy.
+func (db *DB) newGCIndex(policy GCPolicy) (shed.Index, error) {
+	return db.shed.NewIndex(gcIndexName, shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			key = policy.Key(fields, db.po(swarm.NewAddress(fields.Address)))
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint64(b, fields.BinID)
+			key = append(key, b...)
+			key = append(key, fields.Address...)
+			return key, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			if len(key) < swarm.HashSize+8 {
+				return e, fmt.Errorf("invalid gc index key length %d", len(key))
+			}
+			e.Address = key[len(key)-swarm.HashSize:]
+			e.BinID = binary.BigEndian.Uint64(key[len(key)-swarm.HashSize-8 : len(key)-swarm.HashSize])
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return encodeAccess(fields), nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			return decodeAccess(value), nil
+		},
+	})
+}
+
+// encodeAccess encodes the access timestamp and the access count of
+// the item.
+func encodeAccess(item shed.Item) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], uint64(item.AccessTimestamp))
+	binary.BigEndian.PutUint64(b[8:], item.AccessCount)
+	return b
+}
+
+// decodeAccess decodes the access timestamp and the access count
+// encoded by encodeAccess. The access count is zero for values that
+// hold only the access timestamp.
+func decodeAccess(value []byte) (e shed.Item) {
+	if len(value) >= 8 {
+		e.AccessTimestamp = int64(binary.BigEndian.Uint64(value[:8]))
+	}
+	if len(value) >= 16 {
+		e.AccessCount = binary.BigEndian.Uint64(value[8:16])
+	}
+	return e
+}
+
+// gcPolicyRebuildBatchSize is the maximal number of gc index items that
+// are rekeyed in a single batch when the gc index is rebuilt.
+var gcPolicyRebuildBatchSize = 10000
+
+// rebuildGCIndex rekeys all items in the gc index from the policy that
+// built it to the current one. The iterator reads from a snapshot, so
+// that the rekeyed items are not visited again. Rebuilding is idempotent
+// and an interrupted rebuild is completed on the next start. This
+// function must be called before the database is used.
+func (db *DB) rebuildGCIndex() (err error) {
+	name, err := db.gcPolicyName.Get()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		// gc index of the migrated or new databases
+		name = LRUGCPolicy.Name()
+	}
+	if name == db.gcPolicy.Name() {
+		return db.gcPolicyName.Put(name)
+	}
+
+	var from GCPolicy
+	for _, p := range append(gcPolicies, db.gcPolicy) {
+		if p.Name() == name {
+			from = p
+			break
+		}
+	}
+	if from == nil {
+		return fmt.Errorf("rebuild gc index: %w: %q", ErrUnknownGCPolicy, name)
+	}
+	fromIndex, err := db.newGCIndex(from)
+	if err != nil {
+		return err
+	}
+
+	db.logger.Infof("localstore: rebuilding gc index from %s to %s gc policy", name, db.gcPolicy.Name())
+	batch := db.shed.NewBatch()
+	var count int
+	err = fromIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := fromIndex.DeleteInBatch(batch, item); err != nil {
+			return true, err
+		}
+		if err := db.gcIndex.PutInBatch(batch, item); err != nil {
+			return true, err
+		}
+		count++
+		if batch.Len() >= 2*gcPolicyRebuildBatchSize {
+			if err := db.shed.WriteBatch(batch); err != nil {
+				return true, err
+			}
+			batch.Reset()
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("rebuild gc index: %w", err)
+	}
+	db.gcPolicyName.PutInBatch(batch, db.gcPolicy.Name())
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return fmt.Errorf("rebuild gc index: %w", err)
+	}
+	db.logger.Infof("localstore: rebuilt gc index with %d chunks", count)
+	return nil
+}
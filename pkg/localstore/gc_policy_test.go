@@ -0,0 +1,301 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestGCPolicyByName validates that all gc policies provided by the
+// package can be found by their names.
+func TestGCPolicyByName(t *testing.T) {
+	for _, want := range []GCPolicy{LRUGCPolicy, LFUGCPolicy, ProximityGCPolicy} {
+		got, err := GCPolicyByName(want.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got policy %q, want %q", got.Name(), want.Name())
+		}
+	}
+
+	if _, err := GCPolicyByName("random"); !errors.Is(err, ErrUnknownGCPolicy) {
+		t.Errorf("got error %v, want %v", err, ErrUnknownGCPolicy)
+	}
+}
+
+// TestDB_gcPolicy validates that the gc index is ordered by
+// the configured gc policy.
+func TestDB_gcPolicy(t *testing.T) {
+	for _, policy := range []GCPolicy{LRUGCPolicy, LFUGCPolicy, ProximityGCPolicy} {
+		t.Run(policy.Name(), func(t *testing.T) {
+			db := newTestDB(t, &Options{
+				GCPolicy: policy,
+			})
+
+			addrs := addSyncedChunks(t, db, 20)
+			// access some chunks a different number of times
+			for i, addr := range addrs[:10] {
+				for j := 0; j <= i%3; j++ {
+					if err := db.Set(context.Background(), storage.ModeSetAccess, addr); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			t.Run("gc index count", newItemsCountTest(db.gcIndex, len(addrs)))
+			t.Run("gc size", newIndexGCSizeTest(db))
+			t.Run("gc index order", newGCIndexOrderTest(db, policy))
+		})
+	}
+}
+
+// TestDB_gcPolicy_accessCount validates that the access count is
+// incremented on chunk access and kept in the gc index.
+func TestDB_gcPolicy_accessCount(t *testing.T) {
+	db := newTestDB(t, &Options{
+		GCPolicy: LFUGCPolicy,
+	})
+
+	addr := addSyncedChunks(t, db, 1)[0]
+	for i := 0; i < 3; i++ {
+		if err := db.Set(context.Background(), storage.ModeSetAccess, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item, err := db.retrievalAccessIndex.Get(addressToItem(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.AccessCount != 3 {
+		t.Errorf("got access count %v, want %v", item.AccessCount, 3)
+	}
+	// the gc index item is found only if it is
+	// encoded with the current access count
+	item.BinID, err = db.binIDs.Get(uint64(db.po(addr)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Address = addr.Bytes()
+	has, err := db.gcIndex.Has(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !has {
+		t.Error("chunk not found in gc index")
+	}
+}
+
+// TestDB_gcPolicy_rebuild validates that the gc index is rebuilt
+// when the database is opened with a different gc policy.
+func TestDB_gcPolicy_rebuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-gc-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, baseKey, &Options{GCPolicy: LRUGCPolicy}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := addSyncedChunks(t, db, 50)
+	for _, addr := range addrs[:10] {
+		if err := db.Set(context.Background(), storage.ModeSetAccess, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, policy := range []GCPolicy{LFUGCPolicy, ProximityGCPolicy, LRUGCPolicy} {
+		t.Run(policy.Name(), func(t *testing.T) {
+			db, err := New(dir, baseKey, &Options{GCPolicy: policy}, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			name, err := db.gcPolicyName.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != policy.Name() {
+				t.Errorf("got gc policy name %q, want %q", name, policy.Name())
+			}
+
+			t.Run("gc index count", newItemsCountTest(db.gcIndex, len(addrs)))
+			t.Run("gc size", newIndexGCSizeTest(db))
+			t.Run("gc index order", newGCIndexOrderTest(db, policy))
+		})
+	}
+}
+
+// TestMigrateGCPolicy validates that the gc index of the code schema
+// is migrated to the gc index ordered by the lru gc policy.
+func TestMigrateGCPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-gc-policy-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := addSyncedChunks(t, db, 20)
+
+	// the gc index of the code schema
+	newOldIndex := func(db *DB) (shed.Index, error) {
+		return db.shed.NewIndex("AccessTimestamp|BinID|Hash->nil", shed.IndexFuncs{
+			EncodeKey: func(fields shed.Item) (key []byte, err error) {
+				b := make([]byte, 16, 16+len(fields.Address))
+				binary.BigEndian.PutUint64(b[:8], uint64(fields.AccessTimestamp))
+				binary.BigEndian.PutUint64(b[8:16], fields.BinID)
+				key = append(b, fields.Address...)
+				return key, nil
+			},
+			DecodeKey: func(key []byte) (e shed.Item, err error) {
+				return e, nil
+			},
+			EncodeValue: func(fields shed.Item) (value []byte, err error) {
+				return nil, nil
+			},
+			DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+				return e, nil
+			},
+		})
+	}
+
+	// move the gc index items to the gc index of the code schema
+	oldIndex, err := newOldIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []shed.Item
+	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		items = append(items, item)
+		return false, nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if err := db.gcIndex.Delete(item); err != nil {
+			t.Fatal(err)
+		}
+		if err := oldIndex.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.schemaName.Put(DbSchemaCode); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.gcPolicyName.Put(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	oldIndex, err = newOldIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaName, err := db.schemaName.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaName != DbSchemaCurrent {
+		t.Errorf("got schema name %q, want %q", schemaName, DbSchemaCurrent)
+	}
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, len(addrs)))
+	t.Run("old gc index count", newItemsCountTest(oldIndex, 0))
+	t.Run("gc size", newIndexGCSizeTest(db))
+	t.Run("gc index order", newGCIndexOrderTest(db, LRUGCPolicy))
+}
+
+// addSyncedChunks uploads and syncs count random chunks
+// so that they are added to the gc index.
+func addSyncedChunks(t *testing.T, db *DB, count int) (addrs []swarm.Address) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, ch.Address())
+	}
+	return addrs
+}
+
+// newGCIndexOrderTest returns a test function that validates if the
+// gc index items are ordered by the provided gc policy and hold
+// the access values of the retrieval access index.
+func newGCIndexOrderTest(db *DB, policy GCPolicy) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		var prev []byte
+		err := db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			access, err := db.retrievalAccessIndex.Get(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.AccessTimestamp != access.AccessTimestamp {
+				t.Errorf("got access timestamp %v, want %v", item.AccessTimestamp, access.AccessTimestamp)
+			}
+			if item.AccessCount != access.AccessCount {
+				t.Errorf("got access count %v, want %v", item.AccessCount, access.AccessCount)
+			}
+			key := policy.Key(item, db.po(swarm.NewAddress(item.Address)))
+			if bytes.Compare(prev, key) > 0 {
+				t.Errorf("gc index key %x is lower than the previous key %x", key, prev)
+			}
+			prev = key
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
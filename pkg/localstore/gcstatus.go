@@ -0,0 +1,139 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// gcStatusNextEvictionCount is the number of gc index items which
+// access times are reported by GCStatus. The items are in the order of
+// the gc policy, so the access times are the oldest ones only under the
+// lru policy.
+var gcStatusNextEvictionCount = 10
+
+// GCStatus returns the state of garbage collection, the number of
+// garbage collectable chunks against the capacity and the outcome of
+// the last garbage collection run.
+func (db *DB) GCStatus() (s storage.GCStatus, err error) {
+	db.gcMu.Lock()
+	s = db.gcStatus
+	s.Paused = db.gcPaused
+	db.gcMu.Unlock()
+
+	db.batchMu.Lock()
+	s.Capacity = db.cacheCapacity()
+	s.Target = db.gcTarget()
+	s.Size, err = db.gcSize.Get()
+	db.batchMu.Unlock()
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
+		return s, err
+	}
+	if db.capacityBytes > 0 {
+		if t := db.gcBytesTarget(s.Size); t < s.Target {
+			s.Target = t
+		}
+	}
+
+	count, err := db.gcExcludeIndex.Count()
+	if err != nil {
+		return s, err
+	}
+	s.Excluded = uint64(count)
+
+	s.NextEvictionAccess = make([]time.Time, 0, gcStatusNextEvictionCount)
+	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		s.NextEvictionAccess = append(s.NextEvictionAccess, time.Unix(0, item.AccessTimestamp).UTC())
+		return len(s.NextEvictionAccess) >= gcStatusNextEvictionCount, nil
+	}, nil)
+	if err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// TriggerGC starts a garbage collection run, unless one is already in
+// progress or garbage collection is paused. The run removes chunks only
+// if their number is above the garbage collection target.
+func (db *DB) TriggerGC() {
+	db.triggerGarbageCollection()
+}
+
+// SetGCPaused pauses or resumes garbage collection. A run in progress is
+// stopped after the current batch. Garbage collection is triggered when
+// it is resumed.
+func (db *DB) SetGCPaused(paused bool) {
+	db.gcMu.Lock()
+	db.gcPaused = paused
+	db.gcMu.Unlock()
+
+	if paused {
+		db.logger.Info("localstore: garbage collection paused")
+		return
+	}
+	db.logger.Info("localstore: garbage collection resumed")
+	db.triggerGarbageCollection()
+}
+
+// SetCapacity changes the capacity of the database until it is closed.
+// Garbage collection is triggered if the number of garbage collectable
+// chunks reached the new capacity. The capacity must be greater than the
+// reserve capacity.
+func (db *DB) SetCapacity(capacity uint64) (err error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	if db.reserveCapacity >= capacity {
+		return fmt.Errorf("%w: %v", storage.ErrInvalidCapacity, ErrInvalidReserveCapacity)
+	}
+	db.capacity = capacity
+	db.logger.Infof("localstore: database capacity set to %d chunks", capacity)
+
+	gcSize, err := db.gcSize.Get()
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
+		return err
+	}
+	if gcSize >= db.cacheCapacity() {
+		db.triggerGarbageCollection()
+	}
+	return nil
+}
+
+// isGCPaused returns true if garbage collection is paused.
+func (db *DB) isGCPaused() bool {
+	db.gcMu.Lock()
+	defer db.gcMu.Unlock()
+
+	return db.gcPaused
+}
+
+// startGCRun records the start of a garbage collection run.
+func (db *DB) startGCRun() {
+	db.gcMu.Lock()
+	defer db.gcMu.Unlock()
+
+	db.gcStatus.Running = true
+	db.gcStatus.LastRunStart = time.Now()
+}
+
+// endGCRun records the outcome of a garbage collection run.
+func (db *DB) endGCRun(collected uint64, err error) {
+	db.gcMu.Lock()
+	defer db.gcMu.Unlock()
+
+	db.gcStatus.Running = false
+	db.gcStatus.Runs++
+	db.gcStatus.LastRunEnd = time.Now()
+	db.gcStatus.LastRunCollected = collected
+	db.gcStatus.LastRunError = ""
+	if err != nil {
+		db.gcStatus.LastRunError = err.Error()
+	}
+}
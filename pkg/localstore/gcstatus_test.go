@@ -0,0 +1,142 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// TestDB_GCStatus validates that garbage collection is not run while it
+// is paused and that its status is reported when it is resumed.
+func TestDB_GCStatus(t *testing.T) {
+	collected := make(chan uint64)
+	var closed chan struct{}
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case collected <- collectedCount:
+		case <-closed:
+		}
+	}))
+	db := newTestDB(t, &Options{
+		Capacity: 100,
+	})
+	closed = db.close
+
+	db.SetGCPaused(true)
+	addSyncedChunks(t, db, 150)
+
+	s, err := db.GCStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Paused {
+		t.Error("got garbage collection not paused")
+	}
+	if s.Size != 150 {
+		t.Errorf("got gc size %v, want %v", s.Size, 150)
+	}
+	if s.Capacity != 100 {
+		t.Errorf("got capacity %v, want %v", s.Capacity, 100)
+	}
+	if s.Target != 90 {
+		t.Errorf("got target %v, want %v", s.Target, 90)
+	}
+	if s.Runs != 0 {
+		t.Errorf("got %v runs, want none", s.Runs)
+	}
+	if len(s.NextEvictionAccess) != gcStatusNextEvictionCount {
+		t.Errorf("got %v next eviction access times, want %v", len(s.NextEvictionAccess), gcStatusNextEvictionCount)
+	}
+	// chunks are collected in the access order with the default lru policy
+	for i := 1; i < len(s.NextEvictionAccess); i++ {
+		if s.NextEvictionAccess[i].Before(s.NextEvictionAccess[i-1]) {
+			t.Errorf("got access time %v before the previous one %v", s.NextEvictionAccess[i], s.NextEvictionAccess[i-1])
+		}
+	}
+
+	db.SetGCPaused(false)
+	waitCollectedGarbage(t, collected)
+
+	s, err = db.GCStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Paused {
+		t.Error("got garbage collection paused")
+	}
+	if s.Size != s.Target {
+		t.Errorf("got gc size %v, want %v", s.Size, s.Target)
+	}
+	if s.Runs == 0 {
+		t.Error("got no completed runs")
+	}
+	if s.LastRunEnd.Before(s.LastRunStart) {
+		t.Errorf("got last run end %v before its start %v", s.LastRunEnd, s.LastRunStart)
+	}
+	if s.LastRunError != "" {
+		t.Errorf("got last run error %q", s.LastRunError)
+	}
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 90))
+	t.Run("gc size", newIndexGCSizeTest(db))
+}
+
+// TestDB_SetCapacity validates that garbage collection is triggered
+// when the capacity is lowered below the number of collectable chunks.
+func TestDB_SetCapacity(t *testing.T) {
+	collected := make(chan uint64)
+	var closed chan struct{}
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case collected <- collectedCount:
+		case <-closed:
+		}
+	}))
+	db := newTestDB(t, &Options{
+		Capacity:        100,
+		ReserveCapacity: 10,
+	})
+	closed = db.close
+
+	addSyncedChunks(t, db, 50)
+
+	if err := db.SetCapacity(10); !errors.Is(err, storage.ErrInvalidCapacity) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrInvalidCapacity)
+	}
+	if err := db.SetCapacity(50); err != nil {
+		t.Fatal(err)
+	}
+	waitCollectedGarbage(t, collected)
+
+	u, err := db.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ChunkCapacity != 50 {
+		t.Errorf("got chunk capacity %v, want %v", u.ChunkCapacity, 50)
+	}
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 36))
+	t.Run("gc size", newIndexGCSizeTest(db))
+}
+
+// waitCollectedGarbage waits for a garbage collection run
+// that removes chunks.
+func waitCollectedGarbage(t *testing.T, collected <-chan uint64) {
+	t.Helper()
+
+	for {
+		select {
+		case c := <-collected:
+			if c > 0 {
+				return
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+	}
+}
@@ -19,18 +19,20 @@ package localstore
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
 	"sync"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 var _ storage.Storer = &DB{}
@@ -39,6 +41,15 @@ var (
 	// ErrInvalidMode is retuned when an unknown Mode
 	// is provided to the function.
 	ErrInvalidMode = errors.New("invalid mode")
+	// ErrInvalidReserveCapacity is returned when the reserve
+	// capacity is not lower than the capacity.
+	ErrInvalidReserveCapacity = errors.New("reserve capacity must be lower than capacity")
+	// ErrBaseKeyUnknown is returned when the database is opened
+	// without the base key, and the base key is not stored in it.
+	ErrBaseKeyUnknown = errors.New("base key unknown")
+	// ErrCacheOnly is returned when chunks are stored to a cache-only
+	// database with a mode other than ModePutRequest.
+	ErrCacheOnly = errors.New("cache-only database stores only requested chunks")
 )
 
 var (
@@ -52,9 +63,20 @@ var (
 // DB is the local store implementation and holds
 // database related objects.
 type DB struct {
+	// last measured size of the database on disk; accessed
+	// atomically, so it must be 64-bit aligned
+	diskUsage uint64
+
 	shed *shed.DB
 	tags *tags.Tags
 
+	// chunk data referenced by the retrieval data index
+	slots *slotstore.Store
+	// protects reading data from slots referenced by
+	// retrieval data index items against the concurrent
+	// release of slots of removed chunks
+	slotsMu sync.RWMutex
+
 	// schema name of loaded data
 	schemaName shed.StringField
 
@@ -80,6 +102,13 @@ type DB struct {
 	// garbage collection index
 	gcIndex shed.Index
 
+	// order of items in gc index
+	gcPolicy GCPolicy
+
+	// field that stores the name of the policy
+	// that ordered items in gc index
+	gcPolicyName shed.StringField
+
 	// garbage collection exclude index for pinned contents
 	gcExcludeIndex shed.Index
 
@@ -89,12 +118,68 @@ type DB struct {
 	// field that stores number of intems in gc index
 	gcSize shed.Uint64Field
 
+	// reserve index for chunks within the storage radius
+	// that are protected from garbage collection
+	reserveIndex shed.Index
+
+	// field that stores number of items in reserve index
+	reserveSize shed.Uint64Field
+
+	// quarantine index for corrupt pinned chunks that
+	// are removed until they are retrieved again
+	quarantineIndex shed.Index
+
+	// field that stores the storage radius, the minimal proximity
+	// order of chunks in reserve index
+	reserveRadius shed.Uint64Field
+
+	// storage radius value, changed only under batchMu lock
+	radius uint8
+
+	// field that stores the reserve capacity of the last start,
+	// which is used when the database is opened for maintenance
+	storedReserveCapacity shed.Uint64Field
+
+	// storage radius grows when reserveSize exceeds the
+	// reserveCapacity value, zero value disables the reserve
+	reserveCapacity uint64
+
 	// garbage collection is triggered when gcSize exceeds
-	// the capacity value
+	// the capacity value reduced by reserveCapacity, changed
+	// only under batchMu lock
 	capacity uint64
 
+	// garbage collection is also triggered when the size of
+	// the database on disk exceeds the capacityBytes value,
+	// if it is set
+	capacityBytes uint64
+
+	// number of items in gc index to keep in the garbage
+	// collection run that is in progress, if capacityBytes
+	// is set; accessed only by collectGarbageWorker
+	gcRunTarget     uint64
+	gcRunInProgress bool
+
 	// triggers garbage collection event loop
 	collectGarbageTrigger chan struct{}
+	// garbage collection runs are skipped while it is set
+	gcPaused bool
+	// state of garbage collection runs
+	gcStatus storage.GCStatus
+	// protects gcPaused and gcStatus
+	gcMu sync.Mutex
+
+	// validates stored chunks in scrub passes,
+	// scrubbing is disabled if it is not set
+	validator swarm.Validator
+	// maximal number of chunks validated per second
+	scrubRate int
+	// retrieves corrupt pinned chunks from the network
+	retriever Retriever
+	// progress and findings of scrub passes
+	scrubStatus storage.ScrubStatus
+	// protects retriever and scrubStatus
+	scrubMu sync.Mutex
 
 	// a buffered channel acting as a semaphore
 	// to limit the maximal number of goroutines
@@ -107,6 +192,12 @@ type DB struct {
 	// baseKey is the overlay address
 	baseKey []byte
 
+	// database is not changed and syncing
+	// subscriptions provide no chunks
+	readOnly bool
+	// only chunks retrieved from the network are stored
+	cacheOnly bool
+
 	batchMu sync.Mutex
 
 	// this channel is closed when close function is called
@@ -117,6 +208,12 @@ type DB struct {
 	// garbage collection and gc size write workers
 	// are done
 	collectGarbageWorkerDone chan struct{}
+	// closed when the disk usage worker is done, or
+	// immediately if capacityBytes is not set
+	diskUsageWorkerDone chan struct{}
+	// closed when the scrub worker is done, or
+	// immediately if validator is not set
+	scrubWorkerDone chan struct{}
 
 	// wait for all subscriptions to finish before closing
 	// underlaying BadgerDB to prevent possible panics from
@@ -131,8 +228,42 @@ type DB struct {
 // Options struct holds optional parameters for configuring DB.
 type Options struct {
 	// Capacity is a limit that triggers garbage collection when
-	// number of items in gcIndex equals or exceeds it.
+	// number of items in gcIndex equals or exceeds it, reduced
+	// by the ReserveCapacity.
 	Capacity uint64
+	// ReserveCapacity is the part of Capacity for chunks within
+	// the storage radius, which are not garbage collected. The
+	// storage radius grows when the reserve is full. Zero value
+	// disables the reserve.
+	ReserveCapacity uint64
+	// CapacityBytes is a limit of the database size on disk,
+	// including indexes, that triggers garbage collection when
+	// it is reached. Zero value disables the limit.
+	CapacityBytes uint64
+	// GCPolicy defines the order in which chunks are garbage
+	// collected. The default is LRUGCPolicy.
+	GCPolicy GCPolicy
+	// Validator enables periodic validation of all stored chunks,
+	// which removes the corrupt ones. Corrupt pinned chunks are
+	// retrieved again by the Retriever set with SetRetriever.
+	Validator swarm.Validator
+	// ScrubRate limits the number of chunks validated per second.
+	// Zero value uses the default rate.
+	ScrubRate int
+	// Maintenance opens the database of a stopped node for offline
+	// maintenance. The stored gc policy is used if GCPolicy is not set,
+	// the stored reserve capacity is used, the reserve is not evicted
+	// and garbage collection is not started.
+	Maintenance bool
+	// ReadOnly opens an existing database without changing it. Chunks
+	// can only be retrieved and changes return storage.ErrReadOnly. The
+	// stored gc policy is used, garbage collection and scrubbing are not
+	// started and syncing subscriptions provide no chunks.
+	ReadOnly bool
+	// CacheOnly stores only chunks retrieved from the network, with
+	// ModePutRequest, which are not pull synced. The reserve is
+	// disabled, so that all chunks are garbage collected.
+	CacheOnly bool
 	// MetricsPrefix defines a prefix for metrics names.
 	MetricsPrefix string
 	Tags          *tags.Tags
@@ -140,7 +271,8 @@ type Options struct {
 
 // New returns a new DB.  All fields and indexes are initialized
 // and possible conflicts with schema from existing database is checked.
-// One goroutine for writing batches is created.
+// One goroutine for writing batches is created. The base key is stored
+// in the database and the stored one is used if it is not provided.
 func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB, err error) {
 	if o == nil {
 		// default options
@@ -150,9 +282,16 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	}
 
 	db = &DB{
-		capacity: o.Capacity,
-		baseKey:  baseKey,
-		tags:     o.Tags,
+		capacity:        o.Capacity,
+		capacityBytes:   o.CapacityBytes,
+		reserveCapacity: o.ReserveCapacity,
+		gcPolicy:        o.GCPolicy,
+		validator:       o.Validator,
+		scrubRate:       o.ScrubRate,
+		baseKey:         baseKey,
+		readOnly:        o.ReadOnly,
+		cacheOnly:       o.CacheOnly,
+		tags:            o.Tags,
 		// channel collectGarbageTrigger
 		// needs to be buffered with the size of 1
 		// to signal another event if it
@@ -160,12 +299,32 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		collectGarbageTrigger:    make(chan struct{}, 1),
 		close:                    make(chan struct{}),
 		collectGarbageWorkerDone: make(chan struct{}),
+		diskUsageWorkerDone:      make(chan struct{}),
+		scrubWorkerDone:          make(chan struct{}),
 		metrics:                  newMetrics(),
 		logger:                   logger,
 	}
 	if db.capacity == 0 {
 		db.capacity = defaultCapacity
 	}
+	if o.ReadOnly {
+		// keep the stored gc index order
+		// and do not remove corrupt chunks
+		db.gcPolicy = nil
+		db.validator = nil
+	}
+	if db.gcPolicy == nil && !o.Maintenance && !o.ReadOnly {
+		db.gcPolicy = LRUGCPolicy
+	}
+	if db.cacheOnly {
+		db.reserveCapacity = 0
+	}
+	if db.scrubRate <= 0 {
+		db.scrubRate = defaultScrubRate
+	}
+	if db.reserveCapacity >= db.capacity {
+		return nil, ErrInvalidReserveCapacity
+	}
 
 	capacityMB := float64(db.capacity*swarm.ChunkSize) * 9.5367431640625e-7
 
@@ -174,12 +333,45 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	} else {
 		db.logger.Infof("database capacity: %d chunks (approximately %0.1fGB)", db.capacity, capacityMB/1000)
 	}
+	if db.reserveCapacity > 0 {
+		db.logger.Infof("database reserve capacity: %d chunks", db.reserveCapacity)
+	}
+	if db.gcPolicy != nil {
+		db.logger.Infof("database gc policy: %s", db.gcPolicy.Name())
+	}
+	if db.capacityBytes > 0 {
+		db.logger.Infof("database capacity on disk: %0.1fMB", float64(db.capacityBytes)*9.5367431640625e-7)
+	}
+	if db.validator != nil {
+		db.logger.Infof("database scrub rate: %d chunks per second", db.scrubRate)
+	}
+	if db.readOnly {
+		db.logger.Info("database is read-only")
+	} else if db.cacheOnly {
+		db.logger.Info("database is cache-only")
+	}
 
 	if maxParallelUpdateGC > 0 {
 		db.updateGCSem = make(chan struct{}, maxParallelUpdateGC)
 	}
 
-	db.shed, err = shed.NewDB(path)
+	var slotsPath string
+	if path != "" {
+		slotsPath = filepath.Join(path, "slots")
+	}
+	if db.readOnly {
+		db.shed, err = shed.NewReadOnlyDB(path)
+		if err != nil {
+			return nil, err
+		}
+		db.slots, err = slotstore.NewReadOnly(slotsPath, slotsShardCount, slotSize)
+	} else {
+		db.shed, err = shed.NewDB(path)
+		if err != nil {
+			return nil, err
+		}
+		db.slots, err = slotstore.New(slotsPath, slotsShardCount, slotSize)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -190,9 +382,12 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 	schemaName, err := db.schemaName.Get()
-	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
 		return nil, err
 	}
+	if db.readOnly && schemaName != DbSchemaCurrent {
+		return nil, fmt.Errorf("%w: schema %q must be migrated", storage.ErrReadOnly, schemaName)
+	}
 	if schemaName == "" {
 		// initial new localstore run
 		err := db.schemaName.Put(DbSchemaCurrent)
@@ -207,40 +402,48 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		}
 	}
 
+	// Persist the base key, so that the database can
+	// be maintained without the keys of the node.
+	baseKeyField, err := db.shed.NewStringField("base-key")
+	if err != nil {
+		return nil, err
+	}
+	if db.baseKey == nil {
+		v, err := baseKeyField.Get()
+		if err != nil {
+			return nil, err
+		}
+		if v == "" {
+			return nil, ErrBaseKeyUnknown
+		}
+		db.baseKey = []byte(v)
+	} else if !db.readOnly {
+		if err := baseKeyField.Put(string(db.baseKey)); err != nil {
+			return nil, err
+		}
+	}
+
 	// Persist gc size.
 	db.gcSize, err = db.shed.NewUint64Field("gc-size")
 	if err != nil {
 		return nil, err
 	}
 
-	// Index storing actual chunk address, data and bin id.
-	db.retrievalDataIndex, err = db.shed.NewIndex("Address->StoreTimestamp|BinID|Data", shed.IndexFuncs{
-		EncodeKey: func(fields shed.Item) (key []byte, err error) {
-			return fields.Address, nil
-		},
-		DecodeKey: func(key []byte) (e shed.Item, err error) {
-			e.Address = key
-			return e, nil
-		},
-		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			b := make([]byte, 16)
-			binary.BigEndian.PutUint64(b[:8], fields.BinID)
-			binary.BigEndian.PutUint64(b[8:16], uint64(fields.StoreTimestamp))
-			value = append(b, fields.Data...)
-			return value, nil
-		},
-		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
-			e.StoreTimestamp = int64(binary.BigEndian.Uint64(value[8:16]))
-			e.BinID = binary.BigEndian.Uint64(value[:8])
-			e.Data = value[16:]
-			return e, nil
-		},
-	})
+	// Index storing actual chunk address, bin id and the location of
+	// chunk data in slots.
+	db.retrievalDataIndex, err = db.newRetrievalDataIndex()
 	if err != nil {
 		return nil, err
 	}
+	// free slots are not known if slots are not closed cleanly
+	if !db.slots.Clean() {
+		if err := db.recoverSlots(); err != nil {
+			return nil, err
+		}
+	}
 	// Index storing access timestamp for a particular address.
 	// It is needed in order to update gc index keys for iteration order.
+	// Values stored before access counting hold only the access timestamp.
 	db.retrievalAccessIndex, err = db.shed.NewIndex("Address->AccessTimestamp", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
 			return fields.Address, nil
@@ -250,13 +453,10 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 			return e, nil
 		},
 		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			b := make([]byte, 8)
-			binary.BigEndian.PutUint64(b, uint64(fields.AccessTimestamp))
-			return b, nil
+			return encodeAccess(fields), nil
 		},
 		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
-			e.AccessTimestamp = int64(binary.BigEndian.Uint64(value))
-			return e, nil
+			return decodeAccess(value), nil
 		},
 	})
 	if err != nil {
@@ -332,25 +532,52 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	}
 	// create a push syncing triggers used by SubscribePush function
 	db.pushTriggers = make([]chan struct{}, 0)
-	// gc index for removable chunk ordered by ascending last access time
-	db.gcIndex, err = db.shed.NewIndex("AccessTimestamp|BinID|Hash->nil", shed.IndexFuncs{
+	db.gcPolicyName, err = db.shed.NewStringField("gc-policy")
+	if err != nil {
+		return nil, err
+	}
+	if db.gcPolicy == nil {
+		// keep the gc index order in maintenance and read-only mode
+		name, err := db.gcPolicyName.Get()
+		if err != nil {
+			return nil, err
+		}
+		db.gcPolicy = LRUGCPolicy
+		if name != "" {
+			db.gcPolicy, err = GCPolicyByName(name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	// gc index for removable chunks ordered by the gc policy
+	db.gcIndex, err = db.newGCIndex(db.gcPolicy)
+	if err != nil {
+		return nil, err
+	}
+	// rekey the gc index if the gc policy is changed
+	if !db.readOnly {
+		if err := db.rebuildGCIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create a index structure for storing pinned chunks and their pin counts
+	db.pinIndex, err = db.shed.NewIndex("Hash->PinCounter", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
-			b := make([]byte, 16, 16+len(fields.Address))
-			binary.BigEndian.PutUint64(b[:8], uint64(fields.AccessTimestamp))
-			binary.BigEndian.PutUint64(b[8:16], fields.BinID)
-			key = append(b, fields.Address...)
-			return key, nil
+			return fields.Address, nil
 		},
 		DecodeKey: func(key []byte) (e shed.Item, err error) {
-			e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[:8]))
-			e.BinID = binary.BigEndian.Uint64(key[8:16])
-			e.Address = key[16:]
+			e.Address = key
 			return e, nil
 		},
 		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			return nil, nil
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint64(b[:8], fields.PinCounter)
+			return b, nil
 		},
 		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.PinCounter = binary.BigEndian.Uint64(value[:8])
 			return e, nil
 		},
 	})
@@ -358,8 +585,8 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 
-	// Create a index structure for storing pinned chunks and their pin counts
-	db.pinIndex, err = db.shed.NewIndex("Hash->PinCounter", shed.IndexFuncs{
+	// Create a index structure for excluding pinned chunks from gcIndex
+	db.gcExcludeIndex, err = db.shed.NewIndex("Hash->nil", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
 			return fields.Address, nil
 		},
@@ -368,12 +595,9 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 			return e, nil
 		},
 		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			b := make([]byte, 8)
-			binary.BigEndian.PutUint64(b[:8], fields.PinCounter)
-			return b, nil
+			return nil, nil
 		},
 		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
-			e.PinCounter = binary.BigEndian.Uint64(value[:8])
 			return e, nil
 		},
 	})
@@ -381,8 +605,38 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 
-	// Create a index structure for excluding pinned chunks from gcIndex
-	db.gcExcludeIndex, err = db.shed.NewIndex("Hash->nil", shed.IndexFuncs{
+	// Index storing chunks within the storage radius, ordered by
+	// proximity order so that the closest bins are kept the longest.
+	db.reserveIndex, err = db.shed.NewIndex("PO|BinID|Hash->AccessTimestamp|AccessCount", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			key = make([]byte, 9, 9+len(fields.Address))
+			key[0] = db.po(swarm.NewAddress(fields.Address))
+			binary.BigEndian.PutUint64(key[1:9], fields.BinID)
+			key = append(key, fields.Address...)
+			return key, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.BinID = binary.BigEndian.Uint64(key[1:9])
+			e.Address = key[9:]
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return encodeAccess(fields), nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			return decodeAccess(value), nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.reserveSize, err = db.shed.NewUint64Field("reserve-size")
+	if err != nil {
+		return nil, err
+	}
+	// Index storing addresses of corrupt pinned chunks, found by
+	// scrubbing, with the time when they are quarantined.
+	db.quarantineIndex, err = db.shed.NewIndex("Hash->QuarantineTimestamp", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
 			return fields.Address, nil
 		},
@@ -391,15 +645,75 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 			return e, nil
 		},
 		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			return nil, nil
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint64(b, uint64(fields.StoreTimestamp))
+			return b, nil
 		},
 		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.StoreTimestamp = int64(binary.BigEndian.Uint64(value[:8]))
 			return e, nil
 		},
 	})
 	if err != nil {
 		return nil, err
 	}
+	db.reserveRadius, err = db.shed.NewUint64Field("reserve-radius")
+	if err != nil {
+		return nil, err
+	}
+	radius, err := db.reserveRadius.Get()
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
+		return nil, err
+	}
+	db.radius = uint8(radius)
+	db.metrics.StorageRadius.Set(float64(db.radius))
+	db.storedReserveCapacity, err = db.shed.NewUint64Field("reserve-capacity")
+	if err != nil {
+		return nil, err
+	}
+	if o.Maintenance {
+		// repaired chunks are put to the reserve as
+		// the node does with its reserve capacity
+		db.reserveCapacity, err = db.storedReserveCapacity.Get()
+		if err != nil {
+			return nil, err
+		}
+	} else if !db.readOnly {
+		if err := db.storedReserveCapacity.Put(db.reserveCapacity); err != nil {
+			return nil, err
+		}
+	}
+	if o.Maintenance || db.readOnly {
+		// no background work on the database of
+		// a stopped node or a read-only database
+		close(db.diskUsageWorkerDone)
+		close(db.scrubWorkerDone)
+		close(db.collectGarbageWorkerDone)
+		return db, nil
+	}
+
+	// move chunks to the gc index if the reserve
+	// capacity is lowered or the reserve is disabled
+	for done := false; !done; {
+		if done, err = db.evictReserve(); err != nil {
+			return nil, err
+		}
+	}
+
+	if db.capacityBytes > 0 {
+		if err := db.updateDiskUsage(); err != nil {
+			return nil, err
+		}
+		go db.diskUsageWorker()
+	} else {
+		close(db.diskUsageWorkerDone)
+	}
+
+	if db.validator != nil {
+		go db.scrubWorker()
+	} else {
+		close(db.scrubWorkerDone)
+	}
 
 	// start garbage collection worker
 	go db.collectGarbageWorker()
@@ -418,6 +732,8 @@ func (db *DB) Close() (err error) {
 		// wait for gc worker to
 		// return before closing the shed
 		<-db.collectGarbageWorkerDone
+		<-db.diskUsageWorkerDone
+		<-db.scrubWorkerDone
 		close(done)
 	}()
 	select {
@@ -432,6 +748,9 @@ func (db *DB) Close() (err error) {
 			return err
 		}
 	}
+	if err := db.slots.Close(); err != nil {
+		db.logger.Errorf("localstore: close slots: %v", err)
+	}
 	return db.shed.Close()
 }
 
@@ -453,6 +772,8 @@ func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 		"gcIndex":              db.gcIndex,
 		"gcExcludeIndex":       db.gcExcludeIndex,
 		"pinIndex":             db.pinIndex,
+		"reserveIndex":         db.reserveIndex,
+		"quarantineIndex":      db.quarantineIndex,
 	} {
 		indexSize, err := v.Count()
 		if err != nil {
@@ -465,6 +786,11 @@ func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 		return indexInfo, err
 	}
 	indexInfo["gcSize"] = int(val)
+	val, err = db.reserveSize.Get()
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
+		return indexInfo, err
+	}
+	indexInfo["reserveSize"] = int(val)
 
 	return indexInfo, err
 }
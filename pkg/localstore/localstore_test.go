@@ -23,6 +23,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
 	"sort"
 	"sync"
@@ -34,7 +37,6 @@ import (
 	"github.com/ethersphere/bee/pkg/storage"
 	chunktesting "github.com/ethersphere/bee/pkg/storage/testing"
 	"github.com/ethersphere/bee/pkg/swarm"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 func init() {
@@ -146,6 +148,140 @@ func TestDB_updateGCSem(t *testing.T) {
 	}
 }
 
+// TestDB_ReadOnly validates that chunks are retrieved from a read-only
+// database, that it is not changed and that syncing subscriptions
+// provide no chunks.
+func TestDB_ReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-read-only")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logger := logging.New(ioutil.Discard, 0)
+
+	if _, err := New(filepath.Join(dir, "missing"), nil, &Options{ReadOnly: true}, logger); err == nil {
+		t.Fatal("opened read-only database that does not exist")
+	}
+
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	db, err := New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := addSyncedChunks(t, db, 10)
+	uploaded := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, uploaded); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := dirFiles(t, dir)
+
+	db, err = New(dir, nil, &Options{ReadOnly: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, addr := range addrs {
+		if _, err := db.Get(context.Background(), storage.ModeGetRequest, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Put(context.Background(), storage.ModePutRequest, generateTestRandomChunk()); !errors.Is(err, storage.ErrReadOnly) {
+		t.Errorf("got put error %v, want %v", err, storage.ErrReadOnly)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPush, uploaded.Address()); !errors.Is(err, storage.ErrReadOnly) {
+		t.Errorf("got set error %v, want %v", err, storage.ErrReadOnly)
+	}
+	chunks, stop := db.SubscribePush(context.Background())
+	if _, ok := <-chunks; ok {
+		t.Error("got chunk from push subscription")
+	}
+	stop()
+	bin := db.po(addrs[0])
+	descriptors, _, stop := db.SubscribePull(context.Background(), bin, 0, 0)
+	if _, ok := <-descriptors; ok {
+		t.Error("got chunk from pull subscription")
+	}
+	stop()
+	id, err := db.LastPullSubscriptionBinID(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 0 {
+		t.Errorf("got last pull subscription bin id %v, want 0", id)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := dirFiles(t, dir); !reflect.DeepEqual(got, files) {
+		t.Errorf("got database files %v, want %v", got, files)
+	}
+}
+
+// TestDB_CacheOnly validates that only requested chunks are stored in
+// a cache-only database, that they are not pull synced and that the
+// reserve is disabled.
+func TestDB_CacheOnly(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity:        100,
+		ReserveCapacity: 10,
+		CacheOnly:       true,
+	})
+
+	if db.reserveCapacity != 0 {
+		t.Errorf("got reserve capacity %v, want 0", db.reserveCapacity)
+	}
+	for _, mode := range []storage.ModePut{storage.ModePutUpload, storage.ModePutUploadPin, storage.ModePutSync} {
+		if _, err := db.Put(context.Background(), mode, generateTestRandomChunk()); !errors.Is(err, ErrCacheOnly) {
+			t.Errorf("got %v put error %v, want %v", mode, err, ErrCacheOnly)
+		}
+	}
+	chunks := generateTestRandomChunks(10)
+	if _, err := db.Put(context.Background(), storage.ModePutRequest, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("retrieval data index count", newItemsCountTest(db.retrievalDataIndex, 10))
+	t.Run("pull index count", newItemsCountTest(db.pullIndex, 0))
+	t.Run("push index count", newItemsCountTest(db.pushIndex, 0))
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 10))
+	t.Run("gc size", newIndexGCSizeTest(db))
+	for _, ch := range chunks {
+		id, err := db.LastPullSubscriptionBinID(db.po(ch.Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != 0 {
+			t.Errorf("got last pull subscription bin id %v, want 0", id)
+		}
+	}
+}
+
+// dirFiles returns the sizes and modification times
+// of all files in the directory tree.
+func dirFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files[path] = fmt.Sprintf("%d %s", info.Size(), info.ModTime())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
 // newTestDB is a helper function that constructs a
 // temporary database and returns a cleanup function that must
 // be called to remove the data.
@@ -247,14 +383,14 @@ func newRetrieveIndexesTest(db *DB, chunk swarm.Chunk, storeTimestamp, accessTim
 	return func(t *testing.T) {
 		t.Helper()
 
-		item, err := db.retrievalDataIndex.Get(addressToItem(chunk.Address()))
+		item, err := db.getData(addressToItem(chunk.Address()))
 		if err != nil {
 			t.Fatal(err)
 		}
 		validateItem(t, item, chunk.Address().Bytes(), chunk.Data(), storeTimestamp, 0)
 
 		// access index should not be set
-		wantErr := leveldb.ErrNotFound
+		wantErr := shed.ErrNotFound
 		_, err = db.retrievalAccessIndex.Get(addressToItem(chunk.Address()))
 		if !errors.Is(err, wantErr) {
 			t.Errorf("got error %v, want %v", err, wantErr)
@@ -268,7 +404,7 @@ func newRetrieveIndexesTestWithAccess(db *DB, ch swarm.Chunk, storeTimestamp, ac
 	return func(t *testing.T) {
 		t.Helper()
 
-		item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+		item, err := db.getData(addressToItem(ch.Address()))
 		if err != nil {
 			t.Fatal(err)
 		}
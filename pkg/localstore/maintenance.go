@@ -0,0 +1,343 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// repairBatchSize is the maximal number of changes that are
+// written in a single batch when indexes are repaired.
+var repairBatchSize = 10000
+
+// IndexReport holds the number of inconsistent items of an index
+// in relation to the retrieval data index.
+type IndexReport struct {
+	// Missing is the number of chunks without an expected item.
+	Missing int
+	// Dangling is the number of items that do not match any chunk.
+	Dangling int
+}
+
+// ValidationReport holds the inconsistencies of indexes that are found
+// by Validate or fixed by Repair.
+type ValidationReport struct {
+	// Chunks is the number of chunks in the retrieval data index.
+	Chunks int
+	// Indexes maps index names, as returned by DebugIndices, to
+	// their inconsistencies.
+	Indexes map[string]IndexReport
+	// BinIDs is the number of proximity order bins which latest
+	// bin ID is lower than the bin ID of a stored chunk.
+	BinIDs int
+	// GCSize is true if the stored gc size is different from
+	// the number of gc index items.
+	GCSize bool
+	// ReserveSize is true if the stored reserve size is different
+	// from the number of reserve index items.
+	ReserveSize bool
+}
+
+// Consistent returns true if no inconsistencies are reported.
+func (r *ValidationReport) Consistent() bool {
+	for _, i := range r.Indexes {
+		if i.Missing > 0 || i.Dangling > 0 {
+			return false
+		}
+	}
+	return r.BinIDs == 0 && !r.GCSize && !r.ReserveSize
+}
+
+// Validate checks the consistency of pull, push, gc, reserve, gc exclude
+// and retrieval access indexes with the retrieval data index. Writes are
+// blocked while indexes are validated.
+func (db *DB) Validate() (r *ValidationReport, err error) {
+	return db.validate(false)
+}
+
+// Repair rebuilds the indexes checked by Validate from the retrieval data
+// index, which is the source of truth. Dangling items are removed, missing
+// pull index items of uploaded and synced chunks are added and accessed
+// chunks which are not pinned are added to the gc or reserve index. Stored
+// gc and reserve sizes are recounted. It returns the inconsistencies that are fixed. Writes are
+// blocked while indexes are repaired.
+func (db *DB) Repair() (r *ValidationReport, err error) {
+	return db.validate(true)
+}
+
+// Compact compacts the underlying database, releasing the space of the
+// deleted index items.
+func (db *DB) Compact() (err error) {
+	return db.shed.Compact()
+}
+
+// validate finds the inconsistencies of indexes and fixes them if repair
+// is true.
+func (db *DB) validate(repair bool) (r *ValidationReport, err error) {
+	// protect parallel updates
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	r = &ValidationReport{
+		Indexes: make(map[string]IndexReport),
+	}
+	batch := db.shed.NewBatch()
+	write := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		if err := db.shed.WriteBatch(batch); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+	fix := func(f func(batch shed.Batch) error) error {
+		if !repair {
+			return nil
+		}
+		if err := f(batch); err != nil {
+			return err
+		}
+		if batch.Len() >= repairBatchSize {
+			return write()
+		}
+		return nil
+	}
+	getChunk := func(addr []byte) (item shed.Item, found bool, err error) {
+		item, err = db.retrievalDataIndex.Get(shed.Item{Address: addr})
+		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				return item, false, nil
+			}
+			return item, false, err
+		}
+		return item, true, nil
+	}
+	// dangling gc and reserve index items are keyed by a different
+	// bin id or access than the chunk
+	danglingAccessed := func(item shed.Item) (bool, error) {
+		chunk, found, err := getChunk(item.Address)
+		if err != nil || !found {
+			return !found, err
+		}
+		if chunk.BinID != item.BinID {
+			return true, nil
+		}
+		a, err := db.retrievalAccessIndex.Get(item)
+		if err != nil {
+			if errors.Is(err, shed.ErrNotFound) {
+				return true, nil
+			}
+			return false, err
+		}
+		return a.AccessTimestamp != item.AccessTimestamp || a.AccessCount != item.AccessCount, nil
+	}
+
+	// remove dangling items first, as missing ones may have the same keys
+	for _, d := range []struct {
+		name     string
+		index    shed.Index
+		dangling func(item shed.Item) (bool, error)
+	}{
+		{
+			name:  "pullIndex",
+			index: db.pullIndex,
+			dangling: func(item shed.Item) (bool, error) {
+				chunk, found, err := getChunk(item.Address)
+				return !found || chunk.BinID != item.BinID, err
+			},
+		},
+		{
+			name:  "pushIndex",
+			index: db.pushIndex,
+			dangling: func(item shed.Item) (bool, error) {
+				chunk, found, err := getChunk(item.Address)
+				return !found || chunk.StoreTimestamp != item.StoreTimestamp, err
+			},
+		},
+		{
+			name:  "retrievalAccessIndex",
+			index: db.retrievalAccessIndex,
+			dangling: func(item shed.Item) (bool, error) {
+				_, found, err := getChunk(item.Address)
+				return !found, err
+			},
+		},
+		{
+			name:  "gcExcludeIndex",
+			index: db.gcExcludeIndex,
+			dangling: func(item shed.Item) (bool, error) {
+				_, found, err := getChunk(item.Address)
+				return !found, err
+			},
+		},
+		{
+			name:     "reserveIndex",
+			index:    db.reserveIndex,
+			dangling: danglingAccessed,
+		},
+		{
+			name:  "gcIndex",
+			index: db.gcIndex,
+			dangling: func(item shed.Item) (bool, error) {
+				dangling, err := danglingAccessed(item)
+				if err != nil || dangling {
+					return dangling, err
+				}
+				// chunks in reserve must not be garbage collected
+				return db.reserveIndex.Has(item)
+			},
+		},
+	} {
+		var count int
+		err = d.index.Iterate(func(item shed.Item) (stop bool, err error) {
+			dangling, err := d.dangling(item)
+			if err != nil {
+				return true, err
+			}
+			if !dangling {
+				return false, nil
+			}
+			count++
+			return false, fix(func(batch shed.Batch) error {
+				return d.index.DeleteInBatch(batch, item)
+			})
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		// next indexes are checked against the repaired ones
+		if err := write(); err != nil {
+			return nil, err
+		}
+		r.Indexes[d.name] = IndexReport{Dangling: count}
+	}
+
+	maxBinIDs := make(map[uint8]uint64)
+	var missingPull, missingGC int
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		r.Chunks++
+		po := db.po(swarm.NewAddress(item.Address))
+		if item.BinID > maxBinIDs[po] {
+			maxBinIDs[po] = item.BinID
+		}
+
+		// chunks stored on retrieval requests are not in pull index,
+		// so only chunks that are not yet synced or accessed are
+		// expected to be there
+		i, err := db.retrievalAccessIndex.Get(item)
+		accessed := err == nil
+		if err != nil && !errors.Is(err, shed.ErrNotFound) {
+			return true, err
+		}
+		pushed, err := db.pushIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		if !accessed || pushed {
+			p, err := db.pullIndex.Get(item)
+			if err != nil && !errors.Is(err, shed.ErrNotFound) {
+				return true, err
+			}
+			if err != nil || !bytes.Equal(p.Address, item.Address) {
+				missingPull++
+				err = fix(func(batch shed.Batch) error {
+					return db.pullIndex.PutInBatch(batch, item)
+				})
+				if err != nil {
+					return true, err
+				}
+			}
+		}
+
+		// accessed chunks are garbage collected
+		// if they are not pinned
+		if !accessed {
+			return false, nil
+		}
+		item.AccessTimestamp = i.AccessTimestamp
+		item.AccessCount = i.AccessCount
+		for _, index := range []shed.Index{db.pinIndex, db.gcIndex, db.reserveIndex} {
+			has, err := index.Has(item)
+			if err != nil {
+				return true, err
+			}
+			if has {
+				return false, nil
+			}
+		}
+		missingGC++
+		return false, fix(func(batch shed.Batch) error {
+			_, _, err := db.putToGCOrReserve(batch, item)
+			return err
+		})
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.Indexes["pullIndex"] = IndexReport{
+		Missing:  missingPull,
+		Dangling: r.Indexes["pullIndex"].Dangling,
+	}
+	r.Indexes["gcIndex"] = IndexReport{
+		Missing:  missingGC,
+		Dangling: r.Indexes["gcIndex"].Dangling,
+	}
+
+	for po, max := range maxBinIDs {
+		binID, err := db.binIDs.Get(uint64(po))
+		if err != nil {
+			return nil, err
+		}
+		if binID < max {
+			r.BinIDs++
+			if err := fix(func(batch shed.Batch) error {
+				db.binIDs.PutInBatch(batch, uint64(po), max)
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := write(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range []struct {
+		index    shed.Index
+		size     shed.Uint64Field
+		mismatch *bool
+	}{
+		{index: db.gcIndex, size: db.gcSize, mismatch: &r.GCSize},
+		{index: db.reserveIndex, size: db.reserveSize, mismatch: &r.ReserveSize},
+	} {
+		count, err := s.index.Count()
+		if err != nil {
+			return nil, err
+		}
+		size, err := s.size.Get()
+		if err != nil && !errors.Is(err, shed.ErrNotFound) {
+			return nil, err
+		}
+		if size != uint64(count) {
+			*s.mismatch = true
+			if err := fix(func(batch shed.Batch) error {
+				s.size.PutInBatch(batch, uint64(count))
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := write(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,256 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_Validate validates that indexes updated by all put and set
+// modes are consistent.
+func TestDB_Validate(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	addSyncedChunks(t, db, 10)
+	uploaded := generateTestRandomChunks(5)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, uploaded...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(context.Background(), storage.ModePutSync, generateTestRandomChunks(5)...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Put(context.Background(), storage.ModePutRequest, generateTestRandomChunks(5)...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetPin, uploaded[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPush, uploaded[0].Address(), uploaded[1].Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := db.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Consistent() {
+		t.Errorf("got inconsistent indexes %+v", r)
+	}
+	if r.Chunks != 25 {
+		t.Errorf("got %v chunks, want %v", r.Chunks, 25)
+	}
+}
+
+// TestDB_Repair validates that inconsistent indexes are reported
+// by Validate and rebuilt by Repair.
+func TestDB_Repair(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	addrs := addSyncedChunks(t, db, 10)
+	chunk, err := db.retrievalDataIndex.Get(addressToItem(addrs[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := db.retrievalAccessIndex.Get(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk.AccessTimestamp = access.AccessTimestamp
+	chunk.AccessCount = access.AccessCount
+	if err := db.gcIndex.Delete(chunk); err != nil {
+		t.Fatal(err)
+	}
+	unsynced := generateTestRandomChunk()
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, unsynced); err != nil {
+		t.Fatal(err)
+	}
+	item, err := db.retrievalDataIndex.Get(addressToItem(unsynced.Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.pullIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+	dangling := shed.Item{
+		Address:         swarm.MustParseHexAddress("0101010101010101010101010101010101010101010101010101010101010101").Bytes(),
+		StoreTimestamp:  now(),
+		AccessTimestamp: now(),
+		BinID:           1000,
+	}
+	if err := db.pushIndex.Put(dangling); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.gcIndex.Put(dangling); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.binIDs.Put(uint64(db.po(swarm.NewAddress(chunk.Address))), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.gcSize.Put(100); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ValidationReport{
+		Chunks: 11,
+		Indexes: map[string]IndexReport{
+			"pullIndex":            {Missing: 1},
+			"pushIndex":            {Dangling: 1},
+			"retrievalAccessIndex": {},
+			"gcExcludeIndex":       {},
+			"reserveIndex":         {},
+			"gcIndex":              {Missing: 1, Dangling: 1},
+		},
+		BinIDs: 1,
+		GCSize: true,
+	}
+	r, err := db.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newValidationReportTest(r, want)(t)
+
+	r, err = db.Repair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newValidationReportTest(r, want)(t)
+
+	r, err = db.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Consistent() {
+		t.Errorf("got inconsistent indexes after repair %+v", r)
+	}
+	t.Run("pull index count", newItemsCountTest(db.pullIndex, 11))
+	t.Run("push index count", newItemsCountTest(db.pushIndex, 11))
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 10))
+	t.Run("gc size", newIndexGCSizeTest(db))
+}
+
+// TestDB_Maintenance validates that the database is opened for maintenance
+// with the stored base key, gc policy and reserve capacity.
+func TestDB_Maintenance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-maintenance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+
+	if _, err := New("", nil, nil, logger); !errors.Is(err, ErrBaseKeyUnknown) {
+		t.Fatalf("got error %v, want %v", err, ErrBaseKeyUnknown)
+	}
+
+	db, err := New(dir, baseKey, &Options{GCPolicy: LFUGCPolicy}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrs := addSyncedChunks(t, db, 10)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// chunks within the storage radius are in the reserve
+	db, err = New(dir, baseKey, &Options{
+		Capacity:        100,
+		ReserveCapacity: 10,
+		GCPolicy:        LFUGCPolicy,
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reserved := addSyncedChunks(t, db, 5)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, nil, &Options{Maintenance: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if string(db.baseKey) != string(baseKey) {
+		t.Errorf("got base key %x, want %x", db.baseKey, baseKey)
+	}
+	if db.gcPolicy != LFUGCPolicy {
+		t.Errorf("got gc policy %q, want %q", db.gcPolicy.Name(), LFUGCPolicy.Name())
+	}
+	if db.reserveCapacity != 10 {
+		t.Errorf("got reserve capacity %v, want %v", db.reserveCapacity, 10)
+	}
+	r, err := db.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Consistent() {
+		t.Errorf("got inconsistent indexes %+v", r)
+	}
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, len(addrs)))
+	t.Run("reserve index count", newItemsCountTest(db.reserveIndex, len(reserved)))
+
+	// the repaired reserve chunk is not put to the gc index
+	item, err := db.retrievalDataIndex.Get(addressToItem(reserved[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := db.retrievalAccessIndex.Get(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.AccessTimestamp = access.AccessTimestamp
+	if err := db.reserveIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Repair(); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("repaired gc index count", newItemsCountTest(db.gcIndex, len(addrs)))
+	t.Run("repaired reserve index count", newItemsCountTest(db.reserveIndex, len(reserved)))
+}
+
+// newValidationReportTest returns a test function that validates
+// the validation report against the expected one.
+func newValidationReportTest(got, want *ValidationReport) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		if got.Chunks != want.Chunks {
+			t.Errorf("got %v chunks, want %v", got.Chunks, want.Chunks)
+		}
+		if len(got.Indexes) != len(want.Indexes) {
+			t.Errorf("got %v reported indexes, want %v", len(got.Indexes), len(want.Indexes))
+		}
+		for name, w := range want.Indexes {
+			if g := got.Indexes[name]; g != w {
+				t.Errorf("got %s report %+v, want %+v", name, g, w)
+			}
+		}
+		if got.BinIDs != want.BinIDs {
+			t.Errorf("got %v inconsistent bin ids, want %v", got.BinIDs, want.BinIDs)
+		}
+		if got.GCSize != want.GCSize {
+			t.Errorf("got gc size mismatch %v, want %v", got.GCSize, want.GCSize)
+		}
+		if got.ReserveSize != want.ReserveSize {
+			t.Errorf("got reserve size mismatch %v, want %v", got.ReserveSize, want.ReserveSize)
+		}
+	}
+}
@@ -59,9 +59,19 @@ type metrics struct {
 	SubscribePushIterationDone    prometheus.Counter
 	SubscribePushIterationFailure prometheus.Counter
 
+	ScrubCounter            prometheus.Counter
+	ScrubErrorCounter       prometheus.Counter
+	ScrubCheckedCounter     prometheus.Counter
+	ScrubCorruptCounter     prometheus.Counter
+	ScrubRepairedCounter    prometheus.Counter
+	ScrubRepairErrorCounter prometheus.Counter
+
 	GCSize                  prometheus.Gauge
 	GCStoreTimeStamps       prometheus.Gauge
 	GCStoreAccessTimeStamps prometheus.Gauge
+	DiskUsage               prometheus.Gauge
+	StorageRadius           prometheus.Gauge
+	ReserveEvictedCounter   prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -216,8 +226,8 @@ func newMetrics() metrics {
 		ModeGetMultiFailure: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
-			Name:      "mode_get_failure_count",
-			Help:      "Number of times MODE_GET invocation failed.",
+			Name:      "mode_get_multi_failure_count",
+			Help:      "Number of times MODE_MULTI_GET invocation failed.",
 		}),
 		ModePut: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
@@ -252,7 +262,7 @@ func newMetrics() metrics {
 		ModeHasFailure: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
-			Name:      "mode_has_count",
+			Name:      "mode_has_failure_count",
 			Help:      "Number of times MODE_HAS invocation failed.",
 		}),
 		ModeHasMulti: prometheus.NewCounter(prometheus.CounterOpts{
@@ -322,6 +332,43 @@ func newMetrics() metrics {
 			Help:      "Number of times SUBSCRIBE_PUSH_ITERATION_FAILURE is invoked.",
 		}),
 
+		ScrubCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_count",
+			Help:      "Number of times the scrub pass is started.",
+		}),
+		ScrubErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_error_count",
+			Help:      "Number of times the scrub pass failed.",
+		}),
+		ScrubCheckedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_checked_count",
+			Help:      "Number of chunks validated by scrubbing.",
+		}),
+		ScrubCorruptCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_corrupt_count",
+			Help:      "Number of corrupt chunks removed by scrubbing.",
+		}),
+		ScrubRepairedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_repaired_count",
+			Help:      "Number of corrupt pinned chunks retrieved from the network.",
+		}),
+		ScrubRepairErrorCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "scrub_repair_error_count",
+			Help:      "Number of times a corrupt pinned chunk could not be retrieved.",
+		}),
+
 		GCSize: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -340,6 +387,24 @@ func newMetrics() metrics {
 			Name:      "gc_access_time_stamp",
 			Help:      "Access timestamp in Garbage collection iteration.",
 		}),
+		DiskUsage: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disk_usage",
+			Help:      "Size of the database on disk in bytes.",
+		}),
+		StorageRadius: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "storage_radius",
+			Help:      "Minimal proximity order of chunks in the reserve.",
+		}),
+		ReserveEvictedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reserve_evicted_count",
+			Help:      "Number of chunks moved from the reserve to the cache.",
+		}),
 	}
 }
 
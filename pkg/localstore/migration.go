@@ -17,8 +17,12 @@
 package localstore
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
 )
 
 var errMissingCurrentSchema = errors.New("could not find current db schema")
@@ -33,6 +37,8 @@ type migration struct {
 // in order to run data migrations in the correct sequence
 var schemaMigrations = []migration{
 	{name: DbSchemaCode, fn: func(db *DB) error { return nil }},
+	{name: DbSchemaGCPolicy, fn: migrateGCPolicy},
+	{name: DbSchemaSlots, fn: migrateSlots},
 }
 
 func (db *DB) migrate(schemaName string) error {
@@ -98,3 +104,95 @@ func getMigrations(currentSchema, targetSchema string, allSchemeMigrations []mig
 	}
 	return migrations, nil
 }
+
+// migrateSlots moves chunk data from the values of the retrieval data
+// index to slots, keeping only their locations in the new retrieval data
+// index, and compacts the database to release the space of moved data.
+func migrateSlots(db *DB) error {
+	db.logger.Info("localstore migration: starting slots migration")
+
+	// the retrieval data index as it was defined in the gc-policy schema
+	oldIndex, err := db.shed.NewIndex("Address->StoreTimestamp|BinID|Data", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			b := make([]byte, 16)
+			binary.BigEndian.PutUint64(b[:8], fields.BinID)
+			binary.BigEndian.PutUint64(b[8:16], uint64(fields.StoreTimestamp))
+			value = append(b, fields.Data...)
+			return value, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.StoreTimestamp = int64(binary.BigEndian.Uint64(value[8:16]))
+			e.BinID = binary.BigEndian.Uint64(value[:8])
+			e.Data = value[16:]
+			return e, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	newIndex, err := db.newRetrievalDataIndex()
+	if err != nil {
+		return err
+	}
+
+	batch := db.shed.NewBatch()
+	var written []slotstore.Location
+	// slots of data in the batch that is not written
+	// are released if the migration fails
+	defer func() {
+		if err != nil {
+			db.slots.Release(written...)
+		}
+	}()
+	var count int
+	err = oldIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		item, err = db.writeData(item, &written)
+		if err != nil {
+			return true, err
+		}
+		if err := newIndex.PutInBatch(batch, item); err != nil {
+			return true, err
+		}
+		if err := oldIndex.DeleteInBatch(batch, item); err != nil {
+			return true, err
+		}
+		count++
+		if batch.Len() >= 2*slotsMigrationBatchSize {
+			if err := db.slots.Sync(written...); err != nil {
+				return true, err
+			}
+			if err := db.shed.WriteBatch(batch); err != nil {
+				return true, err
+			}
+			batch.Reset()
+			written = written[:0]
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("slots migration: %w", err)
+	}
+	if err = db.slots.Sync(written...); err != nil {
+		return fmt.Errorf("slots migration: %w", err)
+	}
+	if err = db.shed.WriteBatch(batch); err != nil {
+		return fmt.Errorf("slots migration: %w", err)
+	}
+	written = nil
+	db.logger.Infof("localstore migration: slots migration moved %d chunks, compacting", count)
+	if err = db.shed.Compact(); err != nil {
+		return fmt.Errorf("slots migration: compact: %w", err)
+	}
+	return nil
+}
+
+// slotsMigrationBatchSize is the maximal number of chunks moved to slots
+// in a single batch.
+var slotsMigrationBatchSize = 1000
@@ -0,0 +1,76 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+)
+
+// migrateGCPolicy moves the items of the gc index ordered by access
+// timestamp to the gc index ordered by the lru gc policy, that also
+// holds access timestamps and access counts in its values.
+func migrateGCPolicy(db *DB) error {
+	db.logger.Info("localstore migration: starting gc policy migration")
+
+	// the gc index as it was defined in the code schema
+	oldIndex, err := db.shed.NewIndex("AccessTimestamp|BinID|Hash->nil", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			b := make([]byte, 16, 16+len(fields.Address))
+			binary.BigEndian.PutUint64(b[:8], uint64(fields.AccessTimestamp))
+			binary.BigEndian.PutUint64(b[8:16], fields.BinID)
+			key = append(b, fields.Address...)
+			return key, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[:8]))
+			e.BinID = binary.BigEndian.Uint64(key[8:16])
+			e.Address = key[16:]
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return nil, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			return e, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	newIndex, err := db.newGCIndex(LRUGCPolicy)
+	if err != nil {
+		return err
+	}
+
+	batch := db.shed.NewBatch()
+	var count int
+	err = oldIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		if err := oldIndex.DeleteInBatch(batch, item); err != nil {
+			return true, err
+		}
+		if err := newIndex.PutInBatch(batch, item); err != nil {
+			return true, err
+		}
+		count++
+		if batch.Len() >= 2*gcPolicyRebuildBatchSize {
+			if err := db.shed.WriteBatch(batch); err != nil {
+				return true, err
+			}
+			batch.Reset()
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("gc policy migration: %w", err)
+	}
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return fmt.Errorf("gc policy migration: %w", err)
+	}
+	db.logger.Infof("localstore migration: gc policy migration moved %d chunks", count)
+	return nil
+}
@@ -21,8 +21,6 @@ import (
 	"errors"
 	"time"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -45,7 +43,7 @@ func (db *DB) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 
 	out, err := db.get(mode, addr)
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, shed.ErrNotFound) {
 			return nil, storage.ErrNotFound
 		}
 		return nil, err
@@ -58,7 +56,7 @@ func (db *DB) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 func (db *DB) get(mode storage.ModeGet, addr swarm.Address) (out shed.Item, err error) {
 	item := addressToItem(addr)
 
-	out, err = db.retrievalDataIndex.Get(item)
+	out, err = db.getData(item)
 	if err != nil {
 		return out, err
 	}
@@ -85,8 +83,12 @@ func (db *DB) get(mode storage.ModeGet, addr swarm.Address) (out shed.Item, err
 
 // updateGCItems is called when ModeGetRequest is used
 // for Get or GetMulti to update access time and gc indexes
-// for all returned chunks.
+// for all returned chunks. Access of chunks in a read-only
+// database is not recorded.
 func (db *DB) updateGCItems(items ...shed.Item) {
+	if db.readOnly {
+		return
+	}
 	if db.updateGCSem != nil {
 		// wait before creating new goroutines
 		// if updateGCSem buffer id full
@@ -126,7 +128,7 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
-	batch := new(leveldb.Batch)
+	batch := db.shed.NewBatch()
 
 	// update accessTimeStamp in retrieve, gc
 
@@ -134,7 +136,8 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-	case errors.Is(err, leveldb.ErrNotFound):
+		item.AccessCount = i.AccessCount
+	case errors.Is(err, shed.ErrNotFound):
 		// no chunk accesses
 	default:
 		return err
@@ -144,26 +147,28 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 		// do not add it to the gc index
 		return nil
 	}
-	// delete current entry from the gc index
-	err = db.gcIndex.DeleteInBatch(batch, item)
+	// delete current entry from the gc or reserve index,
+	// the sizes of indexes are not changed by the update
+	_, _, err = db.deleteFromGCOrReserve(batch, item)
 	if err != nil {
 		return err
 	}
-	// update access timestamp
+	// update access timestamp and count
 	item.AccessTimestamp = now()
+	item.AccessCount++
 	// update retrieve access index
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
 		return err
 	}
 
-	// add new entry to gc index ONLY if it is not present in pinIndex
+	// add new entry to gc or reserve index ONLY if it is not present in pinIndex
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
 		return err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		_, _, err = db.putToGCOrReserve(batch, item)
 		if err != nil {
 			return err
 		}
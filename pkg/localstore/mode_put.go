@@ -22,9 +22,9 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // Put stores Chunks to database and depending
@@ -52,18 +52,34 @@ func (db *DB) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk)
 // slice. This is the same behaviour as if the same chunks are passed one by one
 // in multiple put method calls.
 func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	if db.readOnly {
+		return nil, storage.ErrReadOnly
+	}
+	if db.cacheOnly && mode != storage.ModePutRequest {
+		return nil, ErrCacheOnly
+	}
+
 	// protect parallel updates
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
-	batch := new(leveldb.Batch)
+	batch := db.shed.NewBatch()
 
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
 	var gcSizeChange int64                      // number to add or subtract from gcSize
+	var reserveSizeChange int64                 // number to add or subtract from reserveSize
 	var triggerPushFeed bool                    // signal push feed subscriptions to iterate
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
+	// slots of chunk data that are released if the batch is not written
+	var written []slotstore.Location
+	defer func() {
+		if err != nil {
+			db.releaseData(written...)
+		}
+	}()
+
 	exist = make([]bool, len(chs))
 
 	// A lazy populated map of bin ids to properly set
@@ -79,12 +95,13 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putRequest(batch, binIDs, chunkToItem(ch))
+			exists, c, r, err := db.putRequest(batch, binIDs, chunkToItem(ch), &written)
 			if err != nil {
 				return nil, err
 			}
 			exist[i] = exists
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
 	case storage.ModePutUpload, storage.ModePutUploadPin:
@@ -93,7 +110,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putUpload(batch, binIDs, chunkToItem(ch))
+			exists, c, err := db.putUpload(batch, binIDs, chunkToItem(ch), &written)
 			if err != nil {
 				return nil, err
 			}
@@ -119,7 +136,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putSync(batch, binIDs, chunkToItem(ch))
+			exists, c, r, err := db.putSync(batch, binIDs, chunkToItem(ch), &written)
 			if err != nil {
 				return nil, err
 			}
@@ -130,6 +147,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				triggerPullFeed[db.po(ch.Address())] = struct{}{}
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
 	default:
@@ -144,7 +162,17 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	if err != nil {
 		return nil, err
 	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return nil, err
+	}
 
+	// chunk data must be on disk before
+	// the indexes that reference it
+	err = db.slots.Sync(written...)
+	if err != nil {
+		return nil, err
+	}
 	err = db.shed.WriteBatch(batch)
 	if err != nil {
 		return nil, err
@@ -160,22 +188,27 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 }
 
 // putRequest adds an Item to the batch by updating required indexes:
-//  - put to indexes: retrieve, gc
+//  - put to indexes: retrieve, gc or reserve
 //  - it does not enter the syncpool
 // The batch can be written to the database.
-// Provided batch and binID map are updated.
-func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+// Provided batch, binID map and written slots are updated.
+func (db *DB) putRequest(batch shed.Batch, binIDs map[uint8]uint64, item shed.Item, written *[]slotstore.Location) (exists bool, gcSizeChange, reserveSizeChange int64, err error) {
 	i, err := db.retrievalDataIndex.Get(item)
 	switch {
 	case err == nil:
 		exists = true
 		item.StoreTimestamp = i.StoreTimestamp
 		item.BinID = i.BinID
-	case errors.Is(err, leveldb.ErrNotFound):
+		item.Location = i.Location
+	case errors.Is(err, shed.ErrNotFound):
 		// no chunk accesses
 		exists = false
+		item, err = db.writeData(item, written)
+		if err != nil {
+			return false, 0, 0, err
+		}
 	default:
-		return false, 0, err
+		return false, 0, 0, err
 	}
 	if item.StoreTimestamp == 0 {
 		item.StoreTimestamp = now()
@@ -183,28 +216,28 @@ func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item she
 	if item.BinID == 0 {
 		item.BinID, err = db.incBinID(binIDs, db.po(swarm.NewAddress(item.Address)))
 		if err != nil {
-			return false, 0, err
+			return false, 0, 0, err
 		}
 	}
 
-	gcSizeChange, err = db.setGC(batch, item)
+	gcSizeChange, reserveSizeChange, err = db.setGC(batch, item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 
 	err = db.retrievalDataIndex.PutInBatch(batch, item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 
-	return exists, gcSizeChange, nil
+	return exists, gcSizeChange, reserveSizeChange, nil
 }
 
 // putUpload adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, push, pull
 // The batch can be written to the database.
-// Provided batch and binID map are updated.
-func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+// Provided batch, binID map and written slots are updated.
+func (db *DB) putUpload(batch shed.Batch, binIDs map[uint8]uint64, item shed.Item, written *[]slotstore.Location) (exists bool, gcSizeChange int64, err error) {
 	exists, err = db.retrievalDataIndex.Has(item)
 	if err != nil {
 		return false, 0, err
@@ -226,6 +259,10 @@ func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed
 	if err != nil {
 		return false, 0, err
 	}
+	item, err = db.writeData(item, written)
+	if err != nil {
+		return false, 0, err
+	}
 	err = db.retrievalDataIndex.PutInBatch(batch, item)
 	if err != nil {
 		return false, 0, err
@@ -246,45 +283,66 @@ func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed
 
 // putSync adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, pull
+//  - put to indexes: retrieval access, reserve if it is within the
+//    storage radius
 // The batch can be written to the database.
-// Provided batch and binID map are updated.
-func (db *DB) putSync(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+// Provided batch, binID map and written slots are updated.
+func (db *DB) putSync(batch shed.Batch, binIDs map[uint8]uint64, item shed.Item, written *[]slotstore.Location) (exists bool, gcSizeChange, reserveSizeChange int64, err error) {
 	exists, err = db.retrievalDataIndex.Has(item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 	if exists {
-		return true, 0, nil
+		return true, 0, 0, nil
 	}
 
 	item.StoreTimestamp = now()
 	item.BinID, err = db.incBinID(binIDs, db.po(swarm.NewAddress(item.Address)))
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
+	}
+	item, err = db.writeData(item, written)
+	if err != nil {
+		return false, 0, 0, err
 	}
 	err = db.retrievalDataIndex.PutInBatch(batch, item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 
-	return false, gcSizeChange, nil
+	// synced chunks of the neighbourhood are protected by the reserve,
+	// other synced chunks enter the gc index when they are accessed
+	if db.inReserve(item.Address) {
+		item.AccessTimestamp = now()
+		err = db.retrievalAccessIndex.PutInBatch(batch, item)
+		if err != nil {
+			return false, 0, 0, err
+		}
+		err = db.reserveIndex.PutInBatch(batch, item)
+		if err != nil {
+			return false, 0, 0, err
+		}
+		reserveSizeChange = 1
+	}
+
+	return false, gcSizeChange, reserveSizeChange, nil
 }
 
 // setGC is a helper function used to add chunks to the retrieval access
-// index and the gc index in the cases that the putToGCCheck condition
-// warrants a gc set. this is to mitigate index leakage in edge cases where
-// a chunk is added to a node's localstore and given that the chunk is
-// already within that node's NN (thus, it can be added to the gc index
-// safely)
-func (db *DB) setGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, err error) {
+// index and the gc or reserve index in the cases that the putToGCCheck
+// condition warrants a gc set. this is to mitigate index leakage in edge
+// cases where a chunk is added to a node's localstore and given that the
+// chunk is already within that node's NN (thus, it can be added to the gc
+// index safely)
+func (db *DB) setGC(batch shed.Batch, item shed.Item) (gcSizeChange, reserveSizeChange int64, err error) {
 	if item.BinID == 0 {
 		i, err := db.retrievalDataIndex.Get(item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 		item.BinID = i.BinID
 	}
@@ -292,36 +350,37 @@ func (db *DB) setGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, e
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		item.AccessCount = i.AccessCount
+		gcSizeChange, reserveSizeChange, err = db.deleteFromGCOrReserve(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange--
-	case errors.Is(err, leveldb.ErrNotFound):
+	case errors.Is(err, shed.ErrNotFound):
 		// the chunk is not accessed before
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	item.AccessTimestamp = now()
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
-	// add new entry to gc index ONLY if it is not present in pinIndex
+	// add new entry to gc or reserve index ONLY if it is not present in pinIndex
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putToGCOrReserve(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange++
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
-	return gcSizeChange, nil
+	return gcSizeChange, reserveSizeChange, nil
 }
 
 // incBinID is a helper function for db.put* methods that increments bin id
@@ -21,8 +21,8 @@ import (
 	"errors"
 	"time"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -47,16 +47,22 @@ func (db *DB) Set(ctx context.Context, mode storage.ModeSet, addrs ...swarm.Addr
 // It acquires lockAddr to protect two calls
 // of this function for the same address in parallel.
 func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
+	if db.readOnly {
+		return storage.ErrReadOnly
+	}
+
 	// protect parallel updates
 	db.batchMu.Lock()
 	defer db.batchMu.Unlock()
 
-	batch := new(leveldb.Batch)
+	batch := db.shed.NewBatch()
 
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
 	var gcSizeChange int64                      // number to add or subtract from gcSize
+	var reserveSizeChange int64                 // number to add or subtract from reserveSize
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
+	var released []slotstore.Location           // slots of removed chunks data to release
 
 	switch mode {
 	case storage.ModeSetAccess:
@@ -66,11 +72,12 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 		binIDs := make(map[uint8]uint64)
 		for _, addr := range addrs {
 			po := db.po(addr)
-			c, err := db.setAccess(batch, binIDs, addr, po)
+			c, r, err := db.setAccess(batch, binIDs, addr, po)
 			if err != nil {
 				return err
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 			triggerPullFeed[po] = struct{}{}
 		}
 		for po, id := range binIDs {
@@ -79,20 +86,22 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 
 	case storage.ModeSetSyncPush, storage.ModeSetSyncPull:
 		for _, addr := range addrs {
-			c, err := db.setSync(batch, addr, mode)
+			c, r, err := db.setSync(batch, addr, mode)
 			if err != nil {
 				return err
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
 	case storage.ModeSetRemove:
 		for _, addr := range addrs {
-			c, err := db.setRemove(batch, addr)
+			c, r, err := db.setRemove(batch, addr, &released)
 			if err != nil {
 				return err
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
 	case storage.ModeSetPin:
@@ -117,8 +126,12 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	if err != nil {
 		return err
 	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return err
+	}
 
-	err = db.shed.WriteBatch(batch)
+	err = db.writeBatchAndRelease(batch, released)
 	if err != nil {
 		return err
 	}
@@ -129,9 +142,9 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 }
 
 // setAccess sets the chunk access time by updating required indexes:
-//  - add to pull, insert to gc
+//  - add to pull, insert to gc or reserve
 // Provided batch and binID map are updated.
-func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swarm.Address, po uint8) (gcSizeChange int64, err error) {
+func (db *DB) setAccess(batch shed.Batch, binIDs map[uint8]uint64, addr swarm.Address, po uint8) (gcSizeChange, reserveSizeChange int64, err error) {
 
 	item := addressToItem(addr)
 
@@ -143,57 +156,59 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swar
 	case err == nil:
 		item.StoreTimestamp = i.StoreTimestamp
 		item.BinID = i.BinID
-	case errors.Is(err, leveldb.ErrNotFound):
+	case errors.Is(err, shed.ErrNotFound):
 		err = db.pushIndex.DeleteInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 		item.StoreTimestamp = now()
 		item.BinID, err = db.incBinID(binIDs, po)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 
 	i, err = db.retrievalAccessIndex.Get(item)
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		item.AccessCount = i.AccessCount
+		gcSizeChange, reserveSizeChange, err = db.deleteFromGCOrReserve(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange--
-	case errors.Is(err, leveldb.ErrNotFound):
+	case errors.Is(err, shed.ErrNotFound):
 		// the chunk is not accessed before
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	item.AccessTimestamp = now()
+	item.AccessCount++
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putToGCOrReserve(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange++
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
-	return gcSizeChange, nil
+	return gcSizeChange, reserveSizeChange, nil
 }
 
 // setSync adds the chunk to the garbage collection after syncing by updating indexes
@@ -203,7 +218,7 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swar
 //   from push sync index
 // - update to gc index happens given item does not exist in pin index
 // Provided batch is updated.
-func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.ModeSet) (gcSizeChange int64, err error) {
+func (db *DB) setSync(batch shed.Batch, addr swarm.Address, mode storage.ModeSet) (gcSizeChange, reserveSizeChange int64, err error) {
 	item := addressToItem(addr)
 
 	// need to get access timestamp here as it is not
@@ -212,18 +227,18 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 
 	i, err := db.retrievalDataIndex.Get(item)
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, shed.ErrNotFound) {
 			// chunk is not found,
 			// no need to update gc index
 			// just delete from the push index
 			// if it is there
 			err = db.pushIndex.DeleteInBatch(batch, item)
 			if err != nil {
-				return 0, err
+				return 0, 0, err
 			}
-			return 0, nil
+			return 0, 0, nil
 		}
-		return 0, err
+		return 0, 0, err
 	}
 	item.StoreTimestamp = i.StoreTimestamp
 	item.BinID = i.BinID
@@ -235,7 +250,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 		// this prevents duplicate increments
 		i, err := db.pullIndex.Get(item)
 		if err != nil {
-			if errors.Is(err, leveldb.ErrNotFound) {
+			if errors.Is(err, shed.ErrNotFound) {
 				// we handle this error internally, since this is an internal inconsistency of the indices
 				// if we return the error here - it means that for example, in stream protocol peers which we sync
 				// to would be dropped. this is possible when the chunk is put with ModePutRequest and ModeSetSyncPull is
@@ -243,7 +258,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 				db.logger.Debugf("localstore: chunk with address %s not found in pull index", addr)
 				break
 			}
-			return 0, err
+			return 0, 0, err
 		}
 
 		if db.tags != nil && i.Tag != 0 {
@@ -263,21 +278,21 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 
 				err = db.pullIndex.PutInBatch(batch, item)
 				if err != nil {
-					return 0, err
+					return 0, 0, err
 				}
 			}
 		}
 	case storage.ModeSetSyncPush:
 		i, err := db.pushIndex.Get(item)
 		if err != nil {
-			if errors.Is(err, leveldb.ErrNotFound) {
+			if errors.Is(err, shed.ErrNotFound) {
 				// we handle this error internally, since this is an internal inconsistency of the indices
 				// this error can happen if the chunk is put with ModePutRequest or ModePutSync
 				// but this function is called with ModeSetSyncPush
 				db.logger.Debugf("localstore: chunk with address %s not found in push index", addr)
 				break
 			}
-			return 0, err
+			return 0, 0, err
 		}
 		if db.tags != nil && i.Tag != 0 {
 			t, err := db.tags.Get(i.Tag)
@@ -288,7 +303,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 			} else {
 				// setting a chunk for push sync assumes the tag is not anonymous
 				if t.Anonymous {
-					return 0, errors.New("got an anonymous chunk in push sync index")
+					return 0, 0, errors.New("got an anonymous chunk in push sync index")
 				}
 
 				t.Inc(tags.StateSynced)
@@ -297,7 +312,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 
 		err = db.pushIndex.DeleteInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 	}
 
@@ -305,42 +320,43 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		item.AccessCount = i.AccessCount
+		gcSizeChange, reserveSizeChange, err = db.deleteFromGCOrReserve(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange--
-	case errors.Is(err, leveldb.ErrNotFound):
+	case errors.Is(err, shed.ErrNotFound):
 		// the chunk is not accessed before
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	item.AccessTimestamp = now()
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	// Add in gcIndex only if this chunk is not pinned
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putToGCOrReserve(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange++
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
-	return gcSizeChange, nil
+	return gcSizeChange, reserveSizeChange, nil
 }
 
 // setRemove removes the chunk by updating indexes:
-//  - delete from retrieve, pull, gc
-// Provided batch is updated.
-func (db *DB) setRemove(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange int64, err error) {
+//  - delete from retrieve, pull, gc or reserve
+// Provided batch and released slots are updated.
+func (db *DB) setRemove(batch shed.Batch, addr swarm.Address, released *[]slotstore.Location) (gcSizeChange, reserveSizeChange int64, err error) {
 	item := addressToItem(addr)
 
 	// need to get access timestamp here as it is not
@@ -350,54 +366,52 @@ func (db *DB) setRemove(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-	case errors.Is(err, leveldb.ErrNotFound):
+		item.AccessCount = i.AccessCount
+	case errors.Is(err, shed.ErrNotFound):
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	i, err = db.retrievalDataIndex.Get(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	item.StoreTimestamp = i.StoreTimestamp
 	item.BinID = i.BinID
+	loc, err := location(i)
+	if err != nil {
+		return 0, 0, err
+	}
+	*released = append(*released, loc)
 
 	err = db.retrievalDataIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	err = db.retrievalAccessIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	err = db.pullIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
-	}
-	err = db.gcIndex.DeleteInBatch(batch, item)
-	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	// a check is needed for decrementing gcSize
+	// a check is needed for decrementing gcSize or reserveSize
 	// as delete is not reporting if the key/value pair
 	// is deleted or not
-	if _, err := db.gcIndex.Get(item); err == nil {
-		gcSizeChange = -1
-	}
-
-	return gcSizeChange, nil
+	return db.deleteFromGCOrReserve(batch, item)
 }
 
 // setPin increments pin counter for the chunk by updating
 // pin index and sets the chunk to be excluded from garbage collection.
 // Provided batch is updated.
-func (db *DB) setPin(batch *leveldb.Batch, addr swarm.Address) (err error) {
+func (db *DB) setPin(batch shed.Batch, addr swarm.Address) (err error) {
 	item := addressToItem(addr)
 
 	// Get the existing pin counter of the chunk
 	existingPinCounter := uint64(0)
 	pinnedChunk, err := db.pinIndex.Get(item)
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, shed.ErrNotFound) {
 			// If this Address is not present in DB, then its a new entry
 			existingPinCounter = 0
 
@@ -425,7 +439,7 @@ func (db *DB) setPin(batch *leveldb.Batch, addr swarm.Address) (err error) {
 
 // setUnpin decrements pin counter for the chunk by updating pin index.
 // Provided batch is updated.
-func (db *DB) setUnpin(batch *leveldb.Batch, addr swarm.Address) (err error) {
+func (db *DB) setUnpin(batch shed.Batch, addr swarm.Address) (err error) {
 	item := addressToItem(addr)
 
 	// Get the existing pin counter of the chunk
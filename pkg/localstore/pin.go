@@ -13,7 +13,6 @@ import (
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 const (
@@ -72,7 +71,7 @@ func (db *DB) PinInfo(address swarm.Address) (uint64, error) {
 	})
 
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, shed.ErrNotFound) {
 			return 0, storage.ErrNotFound
 		}
 		return 0, err
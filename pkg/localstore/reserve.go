@@ -0,0 +1,153 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// inReserve returns true if the chunk with the provided address
+// is within the storage radius and belongs to the reserve. This
+// function must be called under batchMu lock.
+func (db *DB) inReserve(addr []byte) bool {
+	return db.reserveCapacity > 0 && db.po(swarm.NewAddress(addr)) >= db.radius
+}
+
+// putToGCOrReserve adds the item to the reserve index if it is within
+// the storage radius and to the gc index otherwise. Item fields Address,
+// BinID and AccessTimestamp must be set. This function must be called
+// under batchMu lock.
+func (db *DB) putToGCOrReserve(batch shed.Batch, item shed.Item) (gcSizeChange, reserveSizeChange int64, err error) {
+	if db.inReserve(item.Address) {
+		return 0, 1, db.reserveIndex.PutInBatch(batch, item)
+	}
+	return 1, 0, db.gcIndex.PutInBatch(batch, item)
+}
+
+// deleteFromGCOrReserve removes the item from the reserve index or from
+// the gc index, whichever contains it. Both indexes are checked as the item
+// may have been indexed under a different storage radius or reserve
+// capacity. Item fields Address, BinID and AccessTimestamp must be set.
+// This function must be called under batchMu lock.
+func (db *DB) deleteFromGCOrReserve(batch shed.Batch, item shed.Item) (gcSizeChange, reserveSizeChange int64, err error) {
+	has, err := db.reserveIndex.Has(item)
+	if err != nil {
+		return 0, 0, err
+	}
+	if has {
+		return 0, -1, db.reserveIndex.DeleteInBatch(batch, item)
+	}
+	has, err = db.gcIndex.Has(item)
+	if err != nil {
+		return 0, 0, err
+	}
+	if has {
+		return -1, 0, db.gcIndex.DeleteInBatch(batch, item)
+	}
+	return 0, 0, nil
+}
+
+// incReserveSizeInBatch changes reserveSize field value by change which can
+// be negative and triggers garbage collection to grow the storage radius if
+// the reserve capacity is exceeded. This function must be called under
+// batchMu lock.
+func (db *DB) incReserveSizeInBatch(batch shed.Batch, change int64) (err error) {
+	if change == 0 {
+		return nil
+	}
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return err
+	}
+
+	var newSize uint64
+	if change > 0 {
+		newSize = reserveSize + uint64(change)
+	} else {
+		c := uint64(-change)
+		if c > reserveSize {
+			// protect uint64 undeflow
+			return nil
+		}
+		newSize = reserveSize - c
+	}
+	db.reserveSize.PutInBatch(batch, newSize)
+
+	if newSize > db.reserveCapacity {
+		db.triggerGarbageCollection()
+	}
+	return nil
+}
+
+// reserveEvictBatchSize is the maximal number of chunks that are moved
+// from the reserve to the gc index in a single batch.
+var reserveEvictBatchSize uint64 = 10000
+
+// evictReserve grows the storage radius while the number of chunks in the
+// reserve exceeds its capacity, moving chunks of every bin that gets out of
+// the radius to the gc index, where they are garbage collected as cache.
+// At most reserveEvictBatchSize chunks are moved in a single call and done
+// is false if more chunks are to be moved. The radius is stored before all
+// chunks of a bin are moved, so that the chunks left out of the radius are
+// moved by the next call, also after the restart. This function must be
+// called under batchMu lock, or before the database is used.
+func (db *DB) evictReserve() (done bool, err error) {
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return false, err
+	}
+
+	batch := db.shed.NewBatch()
+	radius := db.radius
+	var evicted uint64
+	for bin := uint8(0); evicted < reserveEvictBatchSize; bin++ {
+		if bin >= radius {
+			if reserveSize-evicted <= db.reserveCapacity || radius >= swarm.MaxBins {
+				done = true
+				break
+			}
+			radius++
+		}
+		err = db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if evicted >= reserveEvictBatchSize {
+				return true, nil
+			}
+			if err := db.reserveIndex.DeleteInBatch(batch, item); err != nil {
+				return true, err
+			}
+			if err := db.gcIndex.PutInBatch(batch, item); err != nil {
+				return true, err
+			}
+			evicted++
+			return false, nil
+		}, &shed.IterateOptions{
+			Prefix: []byte{bin},
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	if evicted == 0 && radius == db.radius {
+		return done, nil
+	}
+	db.metrics.ReserveEvictedCounter.Add(float64(evicted))
+	db.metrics.StorageRadius.Set(float64(radius))
+
+	db.reserveSize.PutInBatch(batch, reserveSize-evicted)
+	db.reserveRadius.PutInBatch(batch, uint64(radius))
+	if err := db.incGCSizeInBatch(batch, int64(evicted)); err != nil {
+		return false, err
+	}
+	if err := db.shed.WriteBatch(batch); err != nil {
+		return false, err
+	}
+	if radius != db.radius {
+		db.logger.Infof("localstore: storage radius increased to %d", radius)
+	}
+	db.radius = radius
+	db.logger.Debugf("localstore: %d chunks moved from reserve to cache", evicted)
+	return done, nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_reserve validates that chunks within the storage radius are kept
+// in the reserve index and not in the gc index.
+func TestDB_reserve(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity:        100,
+		ReserveCapacity: 50,
+	})
+
+	chunks := generateTestRandomChunks(10)
+	for _, ch := range chunks {
+		if _, err := db.Put(context.Background(), storage.ModePutRequest, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// with the storage radius 0 all chunks are in the reserve
+	t.Run("reserve index count", newItemsCountTest(db.reserveIndex, len(chunks)))
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 0))
+	t.Run("gc size", newIndexGCSizeTest(db))
+	t.Run("reserve size", newIndexReserveSizeTest(db))
+
+	if err := db.Set(context.Background(), storage.ModeSetRemove, chunks[0].Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reserve index count after remove", newItemsCountTest(db.reserveIndex, len(chunks)-1))
+	t.Run("reserve size after remove", newIndexReserveSizeTest(db))
+}
+
+// TestDB_reserve_sync validates that synced chunks within the storage
+// radius are kept in the reserve index.
+func TestDB_reserve_sync(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity:        100,
+		ReserveCapacity: 50,
+	})
+
+	chunks := generateTestRandomChunks(10)
+	if _, err := db.Put(context.Background(), storage.ModePutSync, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	// with the storage radius 0 all chunks are in the reserve
+	t.Run("reserve index count", newItemsCountTest(db.reserveIndex, len(chunks)))
+	t.Run("retrieve access index count", newItemsCountTest(db.retrievalAccessIndex, len(chunks)))
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, 0))
+	t.Run("gc size", newIndexGCSizeTest(db))
+	t.Run("reserve size", newIndexReserveSizeTest(db))
+}
+
+// TestDB_reserve_evict validates that the storage radius grows when the
+// reserve capacity is exceeded and that the chunks out of the radius are
+// moved to the gc index, in multiple batches.
+func TestDB_reserve_evict(t *testing.T) {
+	for _, mode := range []storage.ModePut{
+		storage.ModePutRequest,
+		storage.ModePutSync,
+	} {
+		t.Run(mode.String(), func(t *testing.T) {
+			testDBReserveEvict(t, mode)
+		})
+	}
+}
+
+func testDBReserveEvict(t *testing.T, mode storage.ModePut) {
+	defer func(s uint64) { reserveEvictBatchSize = s }(reserveEvictBatchSize)
+	reserveEvictBatchSize = 7
+
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+
+	db := newTestDB(t, &Options{
+		Capacity:        1000,
+		ReserveCapacity: 10,
+	})
+	closed = db.close
+
+	chunkCount := 100
+	for _, ch := range generateTestRandomChunks(chunkCount) {
+		if _, err := db.Put(context.Background(), mode, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// garbage collection may run multiple times while the chunks are put,
+	// wait until the reserve is within its capacity and all chunks out
+	// of the radius are moved
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		db.batchMu.Lock()
+		reserveSize, err := db.reserveSize.Get()
+		if err != nil {
+			db.batchMu.Unlock()
+			t.Fatal(err)
+		}
+		var outOfRadius bool
+		err = db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			outOfRadius = db.po(swarm.NewAddress(item.Address)) < db.radius
+			return true, nil
+		}, nil)
+		db.batchMu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reserveSize <= db.reserveCapacity && !outOfRadius {
+			break
+		}
+	}
+
+	// the garbage collection worker is blocked on the test hook
+	// and it can not change the indexes
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	radius := db.radius
+	if radius == 0 {
+		t.Fatal("storage radius did not grow")
+	}
+
+	t.Run("reserve size", newIndexReserveSizeTest(db))
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("all chunks kept", func(t *testing.T) {
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := int(gcSize + reserveSize); got != chunkCount {
+			t.Errorf("got %v chunks in gc and reserve, want %v", got, chunkCount)
+		}
+	})
+
+	t.Run("proximity", func(t *testing.T) {
+		err := db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if po := db.po(swarm.NewAddress(item.Address)); po < radius {
+				t.Errorf("reserve chunk with proximity %v out of radius %v", po, radius)
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if po := db.po(swarm.NewAddress(item.Address)); po >= radius {
+				t.Errorf("gc chunk with proximity %v within radius %v", po, radius)
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("radius persisted", func(t *testing.T) {
+		r, err := db.reserveRadius.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != uint64(radius) {
+			t.Errorf("got persisted radius %v, want %v", r, radius)
+		}
+	})
+}
+
+// TestDB_invalidReserveCapacity validates that the reserve capacity must be
+// lower than the capacity.
+func TestDB_invalidReserveCapacity(t *testing.T) {
+	_, err := New("", make([]byte, 32), &Options{
+		Capacity:        100,
+		ReserveCapacity: 100,
+	}, logging.New(ioutil.Discard, 0))
+	if !errors.Is(err, ErrInvalidReserveCapacity) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidReserveCapacity)
+	}
+}
+
+// newIndexReserveSizeTest returns a test function that validates if the
+// reserveSize field value matches the number of items in the reserve index.
+func newIndexReserveSizeTest(db *DB) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		var want uint64
+		err := db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			want++
+			return
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := db.reserveSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got reserve size %v, want %v", got, want)
+		}
+	}
+}
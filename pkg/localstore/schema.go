@@ -18,10 +18,18 @@ package localstore
 
 // The DB schema we want to use. The actual/current DB schema might differ
 // until migrations are run.
-var DbSchemaCurrent = DbSchemaCode
+var DbSchemaCurrent = DbSchemaSlots
 
 // There was a time when we had no schema at all.
 const DbSchemaNone = ""
 
 // DbSchemaCode is the first bee schema identifier
 const DbSchemaCode = "code"
+
+// DbSchemaGCPolicy is the bee schema identifier for the gc index
+// that is ordered by a configurable gc policy
+const DbSchemaGCPolicy = "gc-policy"
+
+// DbSchemaSlots is the bee schema identifier for chunk data
+// stored in slot files instead of the retrieval data index
+const DbSchemaSlots = "slots"
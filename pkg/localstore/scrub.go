@@ -0,0 +1,340 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	// scrubStartDelay is the time after the database is opened
+	// when the first scrub pass starts.
+	scrubStartDelay = 10 * time.Minute
+	// scrubInterval is the time between the end of a scrub
+	// pass and the start of the next one.
+	scrubInterval = 24 * time.Hour
+	// scrubBatchSize is the number of retrieval data index
+	// items that are read at once during a scrub pass.
+	scrubBatchSize = 100
+	// scrubRetrieveTimeout limits the time to retrieve a
+	// corrupt pinned chunk from the network.
+	scrubRetrieveTimeout = time.Minute
+	// Default value for ScrubRate DB option.
+	defaultScrubRate = 100
+)
+
+// Retriever retrieves chunks from the network. It is used to repair
+// corrupt pinned chunks found by the scrubber.
+type Retriever interface {
+	RetrieveChunk(ctx context.Context, addr swarm.Address) (chunk swarm.Chunk, err error)
+}
+
+// SetRetriever sets the retriever of corrupt pinned chunks. Until it is
+// set, they are kept in quarantine.
+func (db *DB) SetRetriever(r Retriever) {
+	db.scrubMu.Lock()
+	defer db.scrubMu.Unlock()
+
+	db.retriever = r
+}
+
+// ScrubStatus returns the progress and findings of the background
+// validation of stored chunks.
+func (db *DB) ScrubStatus() (s storage.ScrubStatus, err error) {
+	db.scrubMu.Lock()
+	s = db.scrubStatus
+	db.scrubMu.Unlock()
+	s.Enabled = db.validator != nil
+
+	count, err := db.quarantineIndex.Count()
+	if err != nil {
+		return s, err
+	}
+	s.Quarantined = uint64(count)
+	return s, nil
+}
+
+// scrubWorker is a long running function that periodically validates
+// all stored chunks.
+func (db *DB) scrubWorker() {
+	defer close(db.scrubWorkerDone)
+
+	timer := time.NewTimer(scrubStartDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := db.scrub(); err != nil {
+				db.metrics.ScrubErrorCounter.Inc()
+				db.logger.Errorf("localstore: scrub: %v", err)
+			}
+			if testHookScrub != nil {
+				testHookScrub()
+			}
+			timer.Reset(scrubInterval)
+		case <-db.close:
+			return
+		}
+	}
+}
+
+// scrub validates chunks in the retrieval data index, limiting the number
+// of validated chunks per second to the scrub rate. Corrupt chunks are
+// removed from all indexes, and the pinned ones are quarantined until they
+// are retrieved from the network. The pass is not completed if the
+// database is closed.
+func (db *DB) scrub() (err error) {
+	db.metrics.ScrubCounter.Inc()
+
+	db.scrubMu.Lock()
+	db.scrubStatus.Running = true
+	db.scrubStatus.Checked = 0
+	db.scrubStatus.LastPassStart = time.Now()
+	db.scrubMu.Unlock()
+
+	var completed bool
+	defer func() {
+		db.scrubMu.Lock()
+		defer db.scrubMu.Unlock()
+
+		db.scrubStatus.Running = false
+		if completed {
+			db.scrubStatus.Passes++
+			db.scrubStatus.LastPassEnd = time.Now()
+		}
+	}()
+
+	var start *shed.Item
+	for {
+		begin := time.Now()
+		var items []shed.Item
+		err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			items = append(items, item)
+			return len(items) >= scrubBatchSize, nil
+		}, &shed.IterateOptions{
+			StartFrom:         start,
+			SkipStartFromItem: true,
+		})
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := db.scrubChunk(swarm.NewAddress(item.Address)); err != nil {
+				return err
+			}
+		}
+		db.scrubMu.Lock()
+		db.scrubStatus.Checked += uint64(len(items))
+		db.scrubMu.Unlock()
+
+		if len(items) < scrubBatchSize {
+			break
+		}
+		start = &items[len(items)-1]
+
+		wait := time.Duration(len(items))*time.Second/time.Duration(db.scrubRate) - time.Since(begin)
+		if wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-db.close:
+				return nil
+			}
+		}
+	}
+
+	db.repairQuarantined()
+	completed = true
+	return nil
+}
+
+// scrubChunk validates the stored chunk and removes it if it is corrupt.
+func (db *DB) scrubChunk(addr swarm.Address) error {
+	item, err := db.getData(addressToItem(addr))
+	if err != nil {
+		if errors.Is(err, shed.ErrNotFound) {
+			// removed in the meantime
+			return nil
+		}
+		return err
+	}
+	db.metrics.ScrubCheckedCounter.Inc()
+	if db.validator.Validate(swarm.NewChunk(addr, item.Data)) {
+		return nil
+	}
+
+	pinned, err := db.removeCorrupt(addr)
+	if err != nil {
+		return err
+	}
+	db.metrics.ScrubCorruptCounter.Inc()
+	db.logger.Warningf("localstore: scrub: removed corrupt chunk %s", addr)
+
+	db.scrubMu.Lock()
+	defer db.scrubMu.Unlock()
+
+	db.scrubStatus.Corrupt++
+	if !pinned {
+		db.scrubStatus.Removed++
+	}
+	return nil
+}
+
+// removeCorrupt removes the chunk from all indexes if its data is still
+// invalid. Pinned chunks keep their pin counters and are added to the
+// quarantine index.
+func (db *DB) removeCorrupt(addr swarm.Address) (pinned bool, err error) {
+	// protect parallel updates
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	// the chunk may be removed and stored again
+	// after it is validated
+	item, err := db.getData(addressToItem(addr))
+	if err != nil {
+		if errors.Is(err, shed.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if db.validator.Validate(swarm.NewChunk(addr, item.Data)) {
+		return false, nil
+	}
+
+	batch := db.shed.NewBatch()
+	var released []slotstore.Location
+	gcSizeChange, reserveSizeChange, err := db.setRemove(batch, addr, &released)
+	if err != nil {
+		return false, err
+	}
+	err = db.pushIndex.DeleteInBatch(batch, item)
+	if err != nil {
+		return false, err
+	}
+	pinned, err = db.pinIndex.Has(item)
+	if err != nil {
+		return false, err
+	}
+	if pinned {
+		// garbage collection expects the excluded
+		// chunks to be in the retrieval indexes
+		err = db.gcExcludeIndex.DeleteInBatch(batch, item)
+		if err != nil {
+			return false, err
+		}
+		err = db.quarantineIndex.PutInBatch(batch, shed.Item{
+			Address:        item.Address,
+			StoreTimestamp: now(),
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	err = db.incGCSizeInBatch(batch, gcSizeChange)
+	if err != nil {
+		return false, err
+	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return false, err
+	}
+	err = db.writeBatchAndRelease(batch, released)
+	if err != nil {
+		return false, err
+	}
+	return pinned, nil
+}
+
+// repairQuarantined retrieves quarantined chunks from the network and
+// stores them again, if the retriever is set.
+func (db *DB) repairQuarantined() {
+	db.scrubMu.Lock()
+	r := db.retriever
+	db.scrubMu.Unlock()
+	if r == nil {
+		return
+	}
+
+	var addrs []swarm.Address
+	err := db.quarantineIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		addrs = append(addrs, swarm.NewAddress(item.Address))
+		return false, nil
+	}, nil)
+	if err != nil {
+		db.logger.Errorf("localstore: scrub: quarantine: %v", err)
+		return
+	}
+
+	for _, addr := range addrs {
+		select {
+		case <-db.close:
+			return
+		default:
+		}
+		if err := db.repair(r, addr); err != nil {
+			db.metrics.ScrubRepairErrorCounter.Inc()
+			db.logger.Debugf("localstore: scrub: repair chunk %s: %v", addr, err)
+			continue
+		}
+		db.metrics.ScrubRepairedCounter.Inc()
+		db.logger.Infof("localstore: scrub: repaired chunk %s", addr)
+
+		db.scrubMu.Lock()
+		db.scrubStatus.Repaired++
+		db.scrubMu.Unlock()
+	}
+}
+
+// repair retrieves the quarantined chunk, stores it and excludes it from
+// garbage collection as it is pinned.
+func (db *DB) repair(r Retriever, addr swarm.Address) error {
+	ctx, cancel := context.WithTimeout(context.Background(), scrubRetrieveTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-db.close:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ch, err := r.RetrieveChunk(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !ch.Address().Equal(addr) || !db.validator.Validate(ch) {
+		return storage.ErrInvalidChunk
+	}
+	if _, err := db.Put(ctx, storage.ModePutRequest, ch); err != nil {
+		return err
+	}
+
+	// protect parallel updates
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	batch := db.shed.NewBatch()
+	item := addressToItem(addr)
+	err = db.gcExcludeIndex.PutInBatch(batch, item)
+	if err != nil {
+		return err
+	}
+	err = db.quarantineIndex.DeleteInBatch(batch, item)
+	if err != nil {
+		return err
+	}
+	return db.shed.WriteBatch(batch)
+}
+
+// testHookScrub is a hook that can provide
+// information when a scrub pass is done.
+var testHookScrub func()
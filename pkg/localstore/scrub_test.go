@@ -0,0 +1,229 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_scrub validates that corrupt chunks are removed, that corrupt
+// pinned chunks are quarantined and that they are repaired when the
+// retriever is set.
+func TestDB_scrub(t *testing.T) {
+	setScrubStartDelay(t, time.Hour)
+
+	db := newTestDB(t, &Options{
+		Validator: content.NewValidator(),
+	})
+
+	chunks := generateTestContentChunks(t, 10)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPull, chunkAddresses(chunks)...); err != nil {
+		t.Fatal(err)
+	}
+	pinned := chunks[0]
+	if err := db.Set(context.Background(), storage.ModeSetPin, pinned.Address()); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := chunks[:3]
+	for _, ch := range corrupt {
+		corruptChunk(t, db, ch.Address())
+	}
+
+	if err := db.scrub(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("status", newScrubStatusTest(db, storage.ScrubStatus{
+		Enabled:     true,
+		Passes:      1,
+		Checked:     10,
+		Corrupt:     3,
+		Removed:     2,
+		Quarantined: 1,
+	}))
+	t.Run("retrieval data index count", newItemsCountTest(db.retrievalDataIndex, 7))
+	t.Run("pull index count", newItemsCountTest(db.pullIndex, 7))
+	t.Run("gc size", newIndexGCSizeTest(db))
+	for _, ch := range corrupt {
+		if _, err := db.Get(context.Background(), storage.ModeGetLookup, ch.Address()); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("got error %v for corrupt chunk, want %v", err, storage.ErrNotFound)
+		}
+	}
+	t.Run("pin counter", func(t *testing.T) {
+		counter, err := db.PinInfo(pinned.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counter != 1 {
+			t.Errorf("got pin counter %v, want %v", counter, 1)
+		}
+	})
+
+	db.SetRetriever(retrieverFunc(func(_ context.Context, addr swarm.Address) (swarm.Chunk, error) {
+		if !addr.Equal(pinned.Address()) {
+			return nil, storage.ErrNotFound
+		}
+		return pinned, nil
+	}))
+	if err := db.scrub(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("status after repair", newScrubStatusTest(db, storage.ScrubStatus{
+		Enabled:  true,
+		Passes:   2,
+		Checked:  7,
+		Corrupt:  3,
+		Removed:  2,
+		Repaired: 1,
+	}))
+	t.Run("retrieval data index count after repair", newItemsCountTest(db.retrievalDataIndex, 8))
+	t.Run("gc exclude index count after repair", newItemsCountTest(db.gcExcludeIndex, 1))
+	got, err := db.Get(context.Background(), storage.ModeGetLookup, pinned.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !content.NewValidator().Validate(got) {
+		t.Error("repaired chunk is not valid")
+	}
+
+	// repaired chunk is excluded from garbage collection
+	if err := db.removeChunksInExcludeIndexFromGC(); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("gc size after repair", newIndexGCSizeTest(db))
+	t.Run("gc index count after repair", newItemsCountTest(db.gcIndex, 7))
+}
+
+// TestDB_scrubWorker validates that scrubbing is started in the
+// background if the validator is set.
+func TestDB_scrubWorker(t *testing.T) {
+	setScrubStartDelay(t, 0)
+
+	done := make(chan struct{}, 1)
+	// the hook is reset after the database is closed
+	t.Cleanup(func(h func()) func() {
+		return func() { testHookScrub = h }
+	}(testHookScrub))
+	testHookScrub = func() {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+	}
+
+	db := newTestDB(t, &Options{
+		Validator: content.NewValidator(),
+		ScrubRate: 1000,
+	})
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("scrub pass timeout")
+	}
+
+	s, err := db.ScrubStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Passes == 0 {
+		t.Error("got no completed scrub passes")
+	}
+}
+
+// newScrubStatusTest returns a test function that validates the scrub
+// status counters against the expected ones.
+func newScrubStatusTest(db *DB, want storage.ScrubStatus) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		got, err := db.ScrubStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.LastPassEnd.Before(got.LastPassStart) {
+			t.Errorf("got last pass end %v before its start %v", got.LastPassEnd, got.LastPassStart)
+		}
+		got.LastPassStart, got.LastPassEnd = time.Time{}, time.Time{}
+		if got != want {
+			t.Errorf("got scrub status %+v, want %+v", got, want)
+		}
+	}
+}
+
+// corruptChunk replaces the stored chunk data with random bytes.
+func corruptChunk(t *testing.T, db *DB, addr swarm.Address) {
+	t.Helper()
+
+	item, err := db.retrievalDataIndex.Get(addressToItem(addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := location(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, old.Length)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	loc, err := db.slots.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Location, err = loc.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.retrievalDataIndex.Put(item); err != nil {
+		t.Fatal(err)
+	}
+	db.slots.Release(old)
+}
+
+// generateTestContentChunks returns count chunks with random data
+// which addresses are the hashes of their content.
+func generateTestContentChunks(t *testing.T, count int) (chunks []swarm.Chunk) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		data := make([]byte, swarm.ChunkSize)
+		if _, err := rand.Read(data); err != nil {
+			t.Fatal(err)
+		}
+		ch, err := content.NewChunk(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, ch)
+	}
+	return chunks
+}
+
+func setScrubStartDelay(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	old := scrubStartDelay
+	scrubStartDelay = d
+	t.Cleanup(func() { scrubStartDelay = old })
+}
+
+type retrieverFunc func(ctx context.Context, addr swarm.Address) (swarm.Chunk, error)
+
+func (f retrieverFunc) RetrieveChunk(ctx context.Context, addr swarm.Address) (swarm.Chunk, error) {
+	return f(ctx, addr)
+}
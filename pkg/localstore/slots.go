@@ -0,0 +1,172 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// slotsShardCount is the number of files that chunk data is stored in.
+const slotsShardCount = 32
+
+// slotSize is the size of the largest valid chunk, a single owner chunk
+// with the full payload of the wrapped chunk.
+const slotSize = soc.IdSize + soc.SignatureSize + swarm.ChunkWithSpanSize
+
+// newRetrievalDataIndex returns the index of chunk addresses, store
+// timestamps, bin ids and locations of chunk data in slots.
+func (db *DB) newRetrievalDataIndex() (shed.Index, error) {
+	return db.shed.NewIndex("Address->StoreTimestamp|BinID|Location", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			if len(fields.Location) != slotstore.LocationSize {
+				return nil, slotstore.ErrInvalidLocation
+			}
+			b := make([]byte, 16, 16+slotstore.LocationSize)
+			binary.BigEndian.PutUint64(b[:8], fields.BinID)
+			binary.BigEndian.PutUint64(b[8:16], uint64(fields.StoreTimestamp))
+			value = append(b, fields.Location...)
+			return value, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.StoreTimestamp = int64(binary.BigEndian.Uint64(value[8:16]))
+			e.BinID = binary.BigEndian.Uint64(value[:8])
+			e.Location = value[16:]
+			return e, nil
+		},
+	})
+}
+
+// writeData stores the item data in a free slot and sets the item
+// location. The location is appended to written so that the slot can
+// be released if the batch with the item is not written.
+func (db *DB) writeData(item shed.Item, written *[]slotstore.Location) (shed.Item, error) {
+	loc, err := db.slots.Write(item.Data)
+	if err != nil {
+		return item, err
+	}
+	*written = append(*written, loc)
+	item.Location, err = loc.MarshalBinary()
+	if err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// readData sets the item data from the slot on the item location. It must
+// be called under slotsMu read lock, from the item that is retrieved
+// under the same lock.
+func (db *DB) readData(item shed.Item) (shed.Item, error) {
+	loc, err := location(item)
+	if err != nil {
+		return item, err
+	}
+	data, err := db.slots.Read(loc)
+	if err != nil {
+		return item, err
+	}
+	item.Data = data
+	return item, nil
+}
+
+// getData returns the retrieval data index item with the chunk data.
+func (db *DB) getData(item shed.Item) (shed.Item, error) {
+	db.slotsMu.RLock()
+	defer db.slotsMu.RUnlock()
+
+	item, err := db.retrievalDataIndex.Get(item)
+	if err != nil {
+		return item, err
+	}
+	return db.readData(item)
+}
+
+// fillData fills the items from the retrieval data index together with
+// the chunk data.
+func (db *DB) fillData(items []shed.Item) (err error) {
+	db.slotsMu.RLock()
+	defer db.slotsMu.RUnlock()
+
+	if err := db.retrievalDataIndex.Fill(items); err != nil {
+		return err
+	}
+	for i := range items {
+		items[i], err = db.readData(items[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// location returns the decoded location of the item data.
+func location(item shed.Item) (loc slotstore.Location, err error) {
+	err = loc.UnmarshalBinary(item.Location)
+	return loc, err
+}
+
+// writeBatchAndRelease writes the batch that removes chunks from the
+// retrieval data index and releases the slots of their data. The batch is
+// synced to disk before the slots are released, so that the slots are not
+// overwritten by new chunks while the removed ones may still be referenced
+// after a crash.
+func (db *DB) writeBatchAndRelease(batch shed.Batch, released []slotstore.Location) error {
+	if len(released) == 0 {
+		return db.shed.WriteBatch(batch)
+	}
+	if err := db.shed.WriteBatchSync(batch); err != nil {
+		return err
+	}
+	db.releaseData(released...)
+	return nil
+}
+
+// releaseData releases the slots of removed chunks. It must be called only
+// after the batch that removes them from the retrieval data index is written
+// to disk, or for the slots of data in the batch that is not written.
+func (db *DB) releaseData(locs ...slotstore.Location) {
+	if len(locs) == 0 {
+		return
+	}
+	db.slotsMu.Lock()
+	defer db.slotsMu.Unlock()
+
+	db.slots.Release(locs...)
+}
+
+// recoverSlots marks all slots as free except the ones referenced by the
+// retrieval data index. It must be called before the database is used.
+func (db *DB) recoverSlots() error {
+	db.logger.Info("localstore: recovering free slots")
+	var count int
+	err := db.slots.Recover(func(use func(slotstore.Location)) error {
+		return db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			loc, err := location(item)
+			if err != nil {
+				return true, fmt.Errorf("chunk %x: %w", item.Address, err)
+			}
+			use(loc)
+			count++
+			return false, nil
+		}, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("recover slots: %w", err)
+	}
+	db.logger.Infof("localstore: recovered slots of %d chunks", count)
+	return nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/slotstore"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_slots validates that chunk data is stored in slots and that
+// the slots of removed chunks are reused.
+func TestDB_slots(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	chunks := generateTestRandomChunks(100)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("used slots", newUsedSlotsTest(db, len(chunks)))
+	t.Run("data", newChunksDataTest(db, chunks))
+
+	size, _ := db.slots.Size()
+	if err := db.Set(context.Background(), storage.ModeSetRemove, chunkAddresses(chunks[:50])...); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("used slots after remove", newUsedSlotsTest(db, 50))
+
+	newChunks := generateTestRandomChunks(50)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, newChunks...); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("used slots after reuse", newUsedSlotsTest(db, 100))
+	t.Run("data after reuse", newChunksDataTest(db, append(chunks[50:], newChunks...)))
+
+	if newSize, _ := db.slots.Size(); newSize != size {
+		t.Errorf("got slots size %v, want %v", newSize, size)
+	}
+
+	// the largest valid chunk fits into a slot
+	full := generateTestFullSOC(t)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, full); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("full soc data", newChunksDataTest(db, []swarm.Chunk{full}))
+}
+
+// generateTestFullSOC returns a single owner chunk with the full payload of
+// the wrapped chunk, which is the largest valid chunk.
+func generateTestFullSOC(t *testing.T) swarm.Chunk {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, swarm.ChunkSize)
+	if _, err := rand.Read(payload); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := content.NewChunk(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, soc.IdSize)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	sch, err := soc.NewChunk(id, ch, crypto.NewDefaultSigner(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(sch.Data()); l != slotSize {
+		t.Fatalf("got soc size %v, want %v", l, slotSize)
+	}
+	return sch
+}
+
+// TestDB_slots_recover validates that the slots are recovered from the
+// retrieval data index if they are not closed cleanly.
+func TestDB_slots_recover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-slots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := append(generateTestRandomChunks(20), generateTestFullSOC(t))
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// remove the saved free slots as if the database crashed
+	free, err := filepath.Glob(filepath.Join(dir, "slots", "free_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range free {
+		if err := os.Remove(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err = New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if !db.slots.Clean() {
+		t.Error("slots are not recovered")
+	}
+	t.Run("used slots", newUsedSlotsTest(db, len(chunks)))
+	t.Run("data", newChunksDataTest(db, chunks))
+}
+
+// TestMigrateSlots validates that the chunk data of the gc-policy schema
+// is moved to slots.
+func TestMigrateSlots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-slots-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := append(generateTestRandomChunks(20), generateTestFullSOC(t))
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+
+	// the retrieval data index of the gc-policy schema
+	newOldIndex := func(db *DB) (shed.Index, error) {
+		return db.shed.NewIndex("Address->StoreTimestamp|BinID|Data", shed.IndexFuncs{
+			EncodeKey: func(fields shed.Item) (key []byte, err error) {
+				return fields.Address, nil
+			},
+			DecodeKey: func(key []byte) (e shed.Item, err error) {
+				e.Address = key
+				return e, nil
+			},
+			EncodeValue: func(fields shed.Item) (value []byte, err error) {
+				b := make([]byte, 16)
+				binary.BigEndian.PutUint64(b[:8], fields.BinID)
+				binary.BigEndian.PutUint64(b[8:16], uint64(fields.StoreTimestamp))
+				value = append(b, fields.Data...)
+				return value, nil
+			},
+			DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+				return e, nil
+			},
+		})
+	}
+
+	// move the chunk data to the retrieval data index of the gc-policy schema
+	oldIndex, err := newOldIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks {
+		item, err := db.getData(addressToItem(ch.Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := oldIndex.Put(item); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.retrievalDataIndex.Delete(item); err != nil {
+			t.Fatal(err)
+		}
+		loc, err := location(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.releaseData(loc)
+	}
+	if err := db.schemaName.Put(DbSchemaGCPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	oldIndex, err = newOldIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaName, err := db.schemaName.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schemaName != DbSchemaCurrent {
+		t.Errorf("got schema name %q, want %q", schemaName, DbSchemaCurrent)
+	}
+
+	t.Run("old retrieval data index count", newItemsCountTest(oldIndex, 0))
+	t.Run("retrieval data index count", newItemsCountTest(db.retrievalDataIndex, len(chunks)))
+	t.Run("used slots", newUsedSlotsTest(db, len(chunks)))
+	t.Run("data", newChunksDataTest(db, chunks))
+}
+
+// newUsedSlotsTest returns a test function that validates the number of
+// used slots.
+func newUsedSlotsTest(db *DB, want int) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		size, free := db.slots.Size()
+		if got := int((size - free) / slotSize); got != want {
+			t.Errorf("got %v used slots, want %v", got, want)
+		}
+	}
+}
+
+// newChunksDataTest returns a test function that validates that the
+// chunks are retrieved with their data.
+func newChunksDataTest(db *DB, chunks []swarm.Chunk) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		for _, ch := range chunks {
+			got, err := db.Get(context.Background(), storage.ModeGetLookup, ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Data(), ch.Data()) {
+				t.Errorf("got chunk %s data %x, want %x", ch.Address(), got.Data(), ch.Data())
+			}
+			item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(item.Location) != slotstore.LocationSize {
+				t.Errorf("got location length %v, want %v", len(item.Location), slotstore.LocationSize)
+			}
+		}
+	}
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // SubscribePull returns a channel that provides chunk addresses and stored times from pull syncing index.
@@ -39,6 +38,13 @@ import (
 func (db *DB) SubscribePull(ctx context.Context, bin uint8, since, until uint64) (c <-chan storage.Descriptor, closed <-chan struct{}, stop func()) {
 	db.metrics.SubscribePull.Inc()
 
+	// chunks of a read-only database are not synced
+	if db.readOnly {
+		chunkDescriptors := make(chan storage.Descriptor)
+		close(chunkDescriptors)
+		return chunkDescriptors, db.close, func() {}
+	}
+
 	chunkDescriptors := make(chan storage.Descriptor)
 	trigger := make(chan struct{}, 1)
 
@@ -181,13 +187,17 @@ func (db *DB) SubscribePull(ctx context.Context, bin uint8, since, until uint64)
 
 // LastPullSubscriptionBinID returns chunk bin id of the latest Chunk
 // in pull syncing index for a provided bin. If there are no chunks in
-// that bin, 0 value is returned.
+// that bin or the database is read-only, 0 value is returned.
 func (db *DB) LastPullSubscriptionBinID(bin uint8) (id uint64, err error) {
 	db.metrics.LastPullSubscriptionBinID.Inc()
 
+	if db.readOnly {
+		return 0, nil
+	}
+
 	item, err := db.pullIndex.Last([]byte{bin})
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, shed.ErrNotFound) {
 			return 0, nil
 		}
 		return 0, err
@@ -32,6 +32,13 @@ import (
 func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop func()) {
 	db.metrics.SubscribePush.Inc()
 
+	// chunks of a read-only database are not synced
+	if db.readOnly {
+		chunks := make(chan swarm.Chunk)
+		close(chunks)
+		return chunks, func() {}
+	}
+
 	chunks := make(chan swarm.Chunk)
 	trigger := make(chan struct{}, 1)
 
@@ -68,7 +75,7 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop fun
 				var count int
 				err := db.pushIndex.Iterate(func(item shed.Item) (stop bool, err error) {
 					// get chunk data
-					dataItem, err := db.retrievalDataIndex.Get(item)
+					dataItem, err := db.getData(item)
 					if err != nil {
 						return true, err
 					}
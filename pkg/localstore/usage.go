@@ -0,0 +1,108 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+var (
+	// diskUsageInterval is the period of database size measurements
+	// when the capacity in bytes is set.
+	diskUsageInterval = time.Minute
+	// diskSize returns the size of the database on disk, without
+	// the free slots that are reused for new chunks. It is a
+	// variable so that tests can replace it, as the in memory
+	// databases have no size.
+	diskSize = func(db *DB) (uint64, error) {
+		size, err := db.shed.Size()
+		if err != nil {
+			return 0, err
+		}
+		if _, free := db.slots.Size(); free < size {
+			size -= free
+		}
+		return size, nil
+	}
+)
+
+// diskUsageWorker periodically measures the size of the database on disk
+// and triggers garbage collection when it exceeds the capacity in bytes.
+func (db *DB) diskUsageWorker() {
+	defer close(db.diskUsageWorkerDone)
+
+	ticker := time.NewTicker(diskUsageInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := db.updateDiskUsage(); err != nil {
+				db.logger.Errorf("localstore: disk usage: %v", err)
+				continue
+			}
+			if db.capacityBytesReached() {
+				db.triggerGarbageCollection()
+			}
+		case <-db.close:
+			return
+		}
+	}
+}
+
+// updateDiskUsage measures the size of the database on disk.
+func (db *DB) updateDiskUsage() error {
+	size, err := diskSize(db)
+	if err != nil {
+		return err
+	}
+	atomic.StoreUint64(&db.diskUsage, size)
+	db.metrics.DiskUsage.Set(float64(size))
+	return nil
+}
+
+// capacityBytesReached returns true if the capacity in bytes is set and
+// the last measured size of the database on disk is not below it.
+func (db *DB) capacityBytesReached() bool {
+	return db.capacityBytes > 0 && atomic.LoadUint64(&db.diskUsage) >= db.capacityBytes
+}
+
+// Usage returns the current size of the database on disk and the number
+// of garbage collectable chunks against the configured capacities.
+func (db *DB) Usage() (u storage.Usage, err error) {
+	size, err := diskSize(db)
+	if err != nil {
+		return u, err
+	}
+	gcSize, err := db.gcSize.Get()
+	if err != nil && !errors.Is(err, shed.ErrNotFound) {
+		return u, err
+	}
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return u, err
+	}
+	radius, err := db.reserveRadius.Get()
+	if err != nil {
+		return u, err
+	}
+	db.batchMu.Lock()
+	capacity := db.capacity
+	db.batchMu.Unlock()
+	return storage.Usage{
+		Size:            size,
+		Capacity:        db.capacityBytes,
+		Chunks:          gcSize,
+		ChunkCapacity:   capacity,
+		Reserve:         reserveSize,
+		ReserveCapacity: db.reserveCapacity,
+		StorageRadius:   uint8(radius),
+	}, nil
+}
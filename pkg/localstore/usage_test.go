@@ -0,0 +1,130 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_collectGarbageWorker_capacityBytes tests that garbage collection
+// is triggered by the database size on disk and that it removes enough
+// chunks to get under the capacity in bytes.
+func TestDB_collectGarbageWorker_capacityBytes(t *testing.T) {
+	// every chunk takes 1000 bytes on disk
+	const chunkDiskSize = 1000
+	var db *DB
+	t.Cleanup(setDiskSize(func(_ *DB) (uint64, error) {
+		if db == nil {
+			return 0, nil
+		}
+		c, err := db.retrievalDataIndex.Count()
+		return uint64(c) * chunkDiskSize, err
+	}))
+	// measure the disk usage only explicitly
+	interval := diskUsageInterval
+	t.Cleanup(func() { diskUsageInterval = interval })
+	diskUsageInterval = time.Hour
+
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+
+	db = newTestDB(t, &Options{
+		Capacity:      1000,
+		CapacityBytes: 100 * chunkDiskSize,
+	})
+	closed = db.close
+
+	chunkCount := 150
+	addrs := make([]swarm.Address, 0, chunkCount+1)
+	put := func() {
+		ch := generateTestRandomChunk()
+		if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, ch.Address())
+	}
+
+	// the disk usage is not measured yet, so the chunks above
+	// the capacity do not trigger garbage collection
+	for i := 0; i < chunkCount; i++ {
+		put()
+	}
+	if err := db.updateDiskUsage(); err != nil {
+		t.Fatal(err)
+	}
+	// the next synced chunk triggers garbage collection
+	put()
+
+	select {
+	case <-testHookCollectGarbageChan:
+	case <-time.After(10 * time.Second):
+		t.Fatal("collect garbage timeout")
+	}
+
+	// gcTargetRatio of the capacity in bytes
+	wantCount := 90
+
+	t.Run("gc index count", newItemsCountTest(db.gcIndex, wantCount))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("usage", func(t *testing.T) {
+		u, err := db.Usage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := storage.Usage{
+			Size:          uint64(wantCount) * chunkDiskSize,
+			Capacity:      100 * chunkDiskSize,
+			Chunks:        uint64(wantCount),
+			ChunkCapacity: 1000,
+		}
+		if u != want {
+			t.Fatalf("got usage %+v, want %+v", u, want)
+		}
+		if db.capacityBytesReached() {
+			t.Fatal("capacity in bytes still reached after garbage collection")
+		}
+	})
+
+	t.Run("only first inserted chunks should be removed", func(t *testing.T) {
+		for i, addr := range addrs {
+			_, err := db.Get(context.Background(), storage.ModeGetRequest, addr)
+			if i < len(addrs)-wantCount {
+				if !errors.Is(err, storage.ErrNotFound) {
+					t.Errorf("chunk %d: got error %v, want %v", i, err, storage.ErrNotFound)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("chunk %d: %v", i, err)
+			}
+		}
+	})
+}
+
+// setDiskSize replaces the function that measures the database size on
+// disk and returns a function that restores it.
+func setDiskSize(f func(db *DB) (uint64, error)) (reset func()) {
+	current := diskSize
+	reset = func() { diskSize = current }
+	diskSize = f
+	return reset
+}
@@ -59,8 +59,9 @@ func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Addres
 				}()
 				return nil, ErrRecoveryAttempt
 			}
+			// chunks are not cached in a read-only store
 			_, err = s.Storer.Put(ctx, storage.ModePutRequest, ch)
-			if err != nil {
+			if err != nil && !errors.Is(err, storage.ErrReadOnly) {
 				return nil, fmt.Errorf("netstore retrieve put: %w", err)
 			}
 			return ch, nil
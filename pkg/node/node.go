@@ -6,6 +6,8 @@ package node
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -17,9 +19,11 @@ import (
 	"github.com/ethersphere/bee/pkg/accounting"
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/bzz"
 	"github.com/ethersphere/bee/pkg/content"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/discovery/mdns"
 	"github.com/ethersphere/bee/pkg/hive"
 	"github.com/ethersphere/bee/pkg/kademlia"
 	"github.com/ethersphere/bee/pkg/keystore"
@@ -29,6 +33,7 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/metrics"
 	"github.com/ethersphere/bee/pkg/netstore"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
 	"github.com/ethersphere/bee/pkg/pss"
@@ -38,6 +43,7 @@ import (
 	"github.com/ethersphere/bee/pkg/pusher"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/recovery"
+	"github.com/ethersphere/bee/pkg/reputation"
 	"github.com/ethersphere/bee/pkg/retrieval"
 	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
 	"github.com/ethersphere/bee/pkg/soc"
@@ -53,7 +59,14 @@ import (
 )
 
 type Bee struct {
-	p2pService       io.Closer
+	overlay          swarm.Address
+	p2pService       P2PService
+	topology         *kademlia.Kad
+	storer           *localstore.DB
+	netStore         storage.Storer
+	tags             *tags.Tags
+	pingpongCloser   io.Closer
+	mdnsCloser       io.Closer
 	p2pCancel        context.CancelFunc
 	apiServer        *http.Server
 	debugAPIServer   *http.Server
@@ -70,6 +83,12 @@ type Bee struct {
 type Options struct {
 	DataDir              string
 	DBCapacity           uint64
+	DBCapacityBytes      uint64
+	DBReserveCapacity    uint64
+	DBGCPolicy           string
+	DBScrubRate          int
+	DBReadOnly           bool
+	DBCacheOnly          bool
 	Password             string
 	APIAddr              string
 	DebugAPIAddr         string
@@ -77,6 +96,7 @@ type Options struct {
 	NATAddr              string
 	EnableWS             bool
 	EnableQUIC           bool
+	EnableMDNS           bool
 	NetworkID            uint64
 	WelcomeMessage       string
 	Bootnodes            []string
@@ -88,8 +108,37 @@ type Options struct {
 	GlobalPinningEnabled bool
 	PaymentThreshold     uint64
 	PaymentTolerance     uint64
+	PushMaxAttempts      int
+	BandwidthUpload      uint64
+	BandwidthDownload    uint64
+	BandwidthWeights     map[string]float64
+	LightNode            bool
+	P2PMaxInbound        int
+	P2PMaxOutbound       int
+	P2PReservedSlots     int
+	P2PPinnedPeers       []swarm.Address
+	// PrivateKey is the swarm key of the node. The key from the keystore is
+	// used if it is nil.
+	PrivateKey *ecdsa.PrivateKey
+	// P2P constructs the p2p service of the node. A libp2p service is
+	// constructed if it is nil.
+	P2P P2PFunc
 }
 
+// P2PService is the p2p service used by the node.
+type P2PService interface {
+	p2p.Service
+	p2p.Streamer
+	io.Closer
+}
+
+// P2PFunc constructs the p2p service of the node with the overlay address.
+type P2PFunc func(signer crypto.Signer, overlay swarm.Address, addressbook addressbook.Interface) (P2PService, error)
+
+// lightNodeDBCapacity is the maximal localstore capacity in chunks of a light
+// node, which only caches chunks.
+const lightNodeDBCapacity = 50000
+
 func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 	tracer, tracerCloser, err := tracing.NewTracer(&tracing.Options{
 		Enabled:     o.TracingEnabled,
@@ -116,9 +165,12 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 		keyStore = filekeystore.New(filepath.Join(o.DataDir, "keys"))
 	}
 
-	swarmPrivateKey, created, err := keyStore.Key("swarm", o.Password)
-	if err != nil {
-		return nil, fmt.Errorf("swarm key: %w", err)
+	swarmPrivateKey, created := o.PrivateKey, false
+	if swarmPrivateKey == nil {
+		swarmPrivateKey, created, err = keyStore.Key("swarm", o.Password)
+		if err != nil {
+			return nil, fmt.Errorf("swarm key: %w", err)
+		}
 	}
 	address, err := crypto.NewOverlayAddress(swarmPrivateKey.PublicKey, o.NetworkID)
 	if err != nil {
@@ -130,16 +182,12 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 		logger.Infof("using existing swarm network address: %s", address)
 	}
 
-	// Construct P2P service.
-	libp2pPrivateKey, created, err := keyStore.Key("libp2p", o.Password)
-	if err != nil {
-		return nil, fmt.Errorf("libp2p key: %w", err)
-	}
-	if created {
-		logger.Debugf("new libp2p key created")
-	} else {
-		logger.Debugf("using existing libp2p key")
-	}
+	b.overlay = address
+
+	// nodes which do not store synced chunks are advertised as light nodes,
+	// so that peers do not push chunks to them, and they forward the chunks
+	// that they receive
+	lightNode := o.LightNode || o.DBReadOnly || o.DBCacheOnly
 
 	var stateStore storage.StateStorer
 	if o.DataDir == "" {
@@ -155,34 +203,67 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 	addressbook := addressbook.New(stateStore)
 	signer := crypto.NewDefaultSigner(swarmPrivateKey)
 
-	p2ps, err := libp2p.New(p2pCtx, signer, o.NetworkID, address, addr, addressbook, logger, tracer, libp2p.Options{
-		PrivateKey:     libp2pPrivateKey,
-		NATAddr:        o.NATAddr,
-		EnableWS:       o.EnableWS,
-		EnableQUIC:     o.EnableQUIC,
-		WelcomeMessage: o.WelcomeMessage,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("p2p service: %w", err)
-	}
-	b.p2pService = p2ps
+	// Construct P2P service.
+	var (
+		p2ps    P2PService
+		libp2ps *libp2p.Service
+	)
+	if o.P2P != nil {
+		p2ps, err = o.P2P(signer, address, addressbook)
+		if err != nil {
+			return nil, fmt.Errorf("p2p service: %w", err)
+		}
+	} else {
+		libp2pPrivateKey, created, err := keyStore.Key("libp2p", o.Password)
+		if err != nil {
+			return nil, fmt.Errorf("libp2p key: %w", err)
+		}
+		if created {
+			logger.Debugf("new libp2p key created")
+		} else {
+			logger.Debugf("using existing libp2p key")
+		}
 
-	if natManager := p2ps.NATManager(); natManager != nil {
-		// wait for nat manager to init
-		logger.Debug("initializing NAT manager")
-		select {
-		case <-natManager.Ready():
-			// this is magic sleep to give NAT time to sync the mappings
-			// this is a hack, kind of alchemy and should be improved
-			time.Sleep(3 * time.Second)
-			logger.Debug("NAT manager initialized")
-		case <-time.After(10 * time.Second):
-			logger.Warning("NAT manager init timeout")
+		libp2ps, err = libp2p.New(p2pCtx, signer, o.NetworkID, address, addr, addressbook, stateStore, logger, tracer, libp2p.Options{
+			PrivateKey:     libp2pPrivateKey,
+			NATAddr:        o.NATAddr,
+			EnableWS:       o.EnableWS,
+			EnableQUIC:     o.EnableQUIC,
+			LightNode:      lightNode,
+			WelcomeMessage: o.WelcomeMessage,
+			Bandwidth: p2p.BandwidthLimits{
+				Upload:   o.BandwidthUpload,
+				Download: o.BandwidthDownload,
+				Weights:  o.BandwidthWeights,
+			},
+			MaxInbound:    o.P2PMaxInbound,
+			MaxOutbound:   o.P2PMaxOutbound,
+			ReservedSlots: o.P2PReservedSlots,
+			PinnedPeers:   o.P2PPinnedPeers,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("p2p service: %w", err)
 		}
+		if natManager := libp2ps.NATManager(); natManager != nil {
+			// wait for nat manager to init
+			logger.Debug("initializing NAT manager")
+			select {
+			case <-natManager.Ready():
+				// this is magic sleep to give NAT time to sync the mappings
+				// this is a hack, kind of alchemy and should be improved
+				time.Sleep(3 * time.Second)
+				logger.Debug("NAT manager initialized")
+			case <-time.After(10 * time.Second):
+				logger.Warning("NAT manager init timeout")
+			}
+		}
+		p2ps = libp2ps
 	}
+	b.p2pService = p2ps
 
 	// Construct protocols.
 	pingPong := pingpong.New(p2ps, logger, tracer)
+	b.pingpongCloser = pingPong
 
 	if err = p2ps.AddProtocol(pingPong.Protocol()); err != nil {
 		return nil, fmt.Errorf("pingpong service: %w", err)
@@ -205,7 +286,13 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 		bootnodes = append(bootnodes, addr)
 	}
 
-	kad := kademlia.New(address, addressbook, hive, p2ps, logger, kademlia.Options{Bootnodes: bootnodes})
+	peerReputation := reputation.New(p2ps, logger, reputation.Options{})
+	if libp2ps != nil {
+		libp2ps.SetReputation(peerReputation)
+	}
+
+	kad := kademlia.New(address, addressbook, hive, p2ps, logger, kademlia.Options{Bootnodes: bootnodes, Reputation: peerReputation, Latency: pingPong, LightNode: lightNode, PinnedPeers: o.P2PPinnedPeers})
+	b.topology = kad
 	b.topologyCloser = kad
 	hive.SetAddPeersHandler(kad.AddPeers)
 	p2ps.AddNotifier(kad)
@@ -223,13 +310,41 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 	if o.DataDir != "" {
 		path = filepath.Join(o.DataDir, "localstore")
 	}
+	capacity := o.DBCapacity
+	reserveCapacity := o.DBReserveCapacity
+	if o.LightNode {
+		if capacity > lightNodeDBCapacity {
+			capacity = lightNodeDBCapacity
+		}
+		// light nodes do not store the chunks of their neighbourhood
+		reserveCapacity = 0
+	}
+	var gcPolicy localstore.GCPolicy
+	if o.DBGCPolicy != "" {
+		gcPolicy, err = localstore.GCPolicyByName(o.DBGCPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("localstore: %w", err)
+		}
+	}
+	chunkvalidator := swarm.NewChunkValidator(soc.NewValidator(), content.NewValidator())
+
 	lo := &localstore.Options{
-		Capacity: o.DBCapacity,
+		Capacity:        capacity,
+		CapacityBytes:   o.DBCapacityBytes,
+		ReserveCapacity: reserveCapacity,
+		GCPolicy:        gcPolicy,
+		ReadOnly:        o.DBReadOnly,
+		CacheOnly:       o.DBCacheOnly,
+	}
+	if o.DBScrubRate > 0 {
+		lo.Validator = chunkvalidator
+		lo.ScrubRate = o.DBScrubRate
 	}
 	storer, err := localstore.New(path, address.Bytes(), lo, logger)
 	if err != nil {
 		return nil, fmt.Errorf("localstore: %w", err)
 	}
+	b.storer = storer
 	b.localstoreCloser = storer
 
 	settlement := pseudosettle.New(pseudosettle.Options{
@@ -254,10 +369,9 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 
 	settlement.SetPaymentObserver(acc)
 
-	chunkvalidator := swarm.NewChunkValidator(soc.NewValidator(), content.NewValidator())
-
 	retrieve := retrieval.New(p2ps, kad, logger, acc, accounting.NewFixedPricer(address, 10), chunkvalidator)
 	tagg := tags.NewTags()
+	b.tags = tagg
 
 	if err = p2ps.AddProtocol(retrieve.Protocol()); err != nil {
 		return nil, fmt.Errorf("retrieval service: %w", err)
@@ -274,9 +388,15 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 	} else {
 		ns = netstore.New(storer, nil, retrieve, logger, chunkvalidator)
 	}
+	b.netStore = ns
 	retrieve.SetStorer(ns)
+	// repair corrupt pinned chunks found by scrubbing
+	storer.SetRetriever(retrieve)
+	retrieve.SetReputation(peerReputation)
+	retrieve.SetLatency(pingPong, kad)
 
-	pushSyncProtocol := pushsync.New(p2ps, storer, kad, tagg, psss.TryUnwrap, logger)
+	pushSyncProtocol := pushsync.New(address, p2ps, storer, kad, tagg, psss.TryUnwrap, signer, o.NetworkID, logger)
+	pushSyncProtocol.SetReputation(peerReputation)
 
 	// set the pushSyncer in the PSS
 	psss.WithPushSyncer(pushSyncProtocol)
@@ -291,21 +411,43 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 		psss.Register(recovery.RecoveryTopic, chunkRepairHandler)
 	}
 
-	pushSyncPusher := pusher.New(storer, kad, pushSyncProtocol, tagg, logger)
-	b.pusherCloser = pushSyncPusher
-
-	pullStorage := pullstorage.New(storer)
+	// read-only db has no chunks to push
+	var (
+		pushSyncPusher *pusher.Service
+		pusherService  pusher.Interface
+	)
+	if !o.DBReadOnly {
+		pushSyncPusher, err = pusher.New(storer, stateStore, kad, pushSyncProtocol, tagg, logger, pusher.Options{
+			MaxAttempts: o.PushMaxAttempts,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("pusher: %w", err)
+		}
+		b.pusherCloser = pushSyncPusher
+		pusherService = pushSyncPusher
+	}
 
-	pullSync := pullsync.New(p2ps, pullStorage, logger)
-	b.pullSyncCloser = pullSync
+	// light nodes do not keep a storage reserve and therefore do not pull sync,
+	// as well as nodes which do not store synced chunks
+	var pullerService puller.Interface
+	if !o.LightNode && !o.DBReadOnly && !o.DBCacheOnly {
+		pullStorage := pullstorage.New(storer)
 
-	if err = p2ps.AddProtocol(pullSync.Protocol()); err != nil {
-		return nil, fmt.Errorf("pullsync protocol: %w", err)
-	}
+		pullSync := pullsync.New(p2ps, pullStorage, logger)
+		b.pullSyncCloser = pullSync
 
-	puller := puller.New(stateStore, kad, pullSync, logger, puller.Options{})
+		if err = p2ps.AddProtocol(pullSync.Protocol()); err != nil {
+			return nil, fmt.Errorf("pullsync protocol: %w", err)
+		}
 
-	b.pullerCloser = puller
+		puller := puller.New(stateStore, kad, pullSync, logger, puller.Options{})
+		b.pullerCloser = puller
+		pullerService = puller
+	} else if o.LightNode {
+		logger.Info("running in light mode, pull syncing is disabled")
+	} else {
+		logger.Info("running with read-only or cache-only db, pull syncing is disabled")
+	}
 
 	var apiService api.Service
 	if o.APIAddr != "" {
@@ -334,12 +476,23 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 	}
 
 	if o.DebugAPIAddr != "" {
+		debugP2P, ok := p2ps.(p2p.DebugService)
+		if !ok {
+			return nil, errors.New("debug api: p2p service does not support debugging")
+		}
+
 		// Debug API server
-		debugAPIService := debugapi.New(address, p2ps, pingPong, kad, storer, logger, tracer, tagg, acc)
+		debugAPIService := debugapi.New(address, debugP2P, pingPong, kad, storer, logger, tracer, tagg, acc, pusherService, pullerService, peerReputation, addressbook)
 		// register metrics from components
-		debugAPIService.MustRegisterMetrics(p2ps.Metrics()...)
+		if c, ok := p2ps.(metrics.Collector); ok {
+			debugAPIService.MustRegisterMetrics(c.Metrics()...)
+		}
 		debugAPIService.MustRegisterMetrics(pingPong.Metrics()...)
 		debugAPIService.MustRegisterMetrics(acc.Metrics()...)
+		if pushSyncPusher != nil {
+			debugAPIService.MustRegisterMetrics(pushSyncPusher.Metrics()...)
+		}
+		debugAPIService.MustRegisterMetrics(storer.Metrics()...)
 
 		if apiService != nil {
 			debugAPIService.MustRegisterMetrics(apiService.Metrics()...)
@@ -370,13 +523,64 @@ func NewBee(addr string, logger logging.Logger, o Options) (*Bee, error) {
 		b.debugAPIServer = debugAPIServer
 	}
 
+	pingPong.Start(kad)
+
 	if err := kad.Start(p2pCtx); err != nil {
 		return nil, err
 	}
 
+	if o.EnableMDNS {
+		underlay, err := mdns.Underlay(addrs)
+		if err != nil {
+			return nil, fmt.Errorf("mdns: %w", err)
+		}
+		bzzAddress, err := bzz.NewAddress(signer, underlay, address, o.NetworkID)
+		if err != nil {
+			return nil, fmt.Errorf("mdns: %w", err)
+		}
+		mdnsService := mdns.New(*bzzAddress, o.NetworkID, addressbook, logger)
+		mdnsService.SetAddPeersHandler(kad.AddPeers)
+		if err := mdnsService.Start(); err != nil {
+			return nil, fmt.Errorf("mdns: %w", err)
+		}
+		b.mdnsCloser = mdnsService
+		logger.Infof("mdns discovery advertising %s", underlay)
+	}
+
 	return b, nil
 }
 
+// Overlay returns the overlay address of the node.
+func (b *Bee) Overlay() swarm.Address {
+	return b.overlay
+}
+
+// P2P returns the p2p service of the node.
+func (b *Bee) P2P() P2PService {
+	return b.p2pService
+}
+
+// Topology returns the kademlia topology driver of the node.
+func (b *Bee) Topology() *kademlia.Kad {
+	return b.topology
+}
+
+// Storer returns the localstore of the node.
+func (b *Bee) Storer() *localstore.DB {
+	return b.storer
+}
+
+// NetStore returns the storer which retrieves the chunks that are not in the
+// localstore from the network.
+func (b *Bee) NetStore() storage.Storer {
+	return b.netStore
+}
+
+// Tags returns the upload tags of the node.
+func (b *Bee) Tags() *tags.Tags {
+	return b.tags
+}
+
 func (b *Bee) Shutdown(ctx context.Context) error {
 	errs := new(multiError)
 
@@ -401,16 +605,32 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 		errs.add(err)
 	}
 
-	if err := b.pusherCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("pusher: %w", err))
+	if b.mdnsCloser != nil {
+		if err := b.mdnsCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("mdns: %w", err))
+		}
+	}
+
+	if err := b.pingpongCloser.Close(); err != nil {
+		errs.add(fmt.Errorf("pingpong: %w", err))
 	}
 
-	if err := b.pullerCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("puller: %w", err))
+	if b.pusherCloser != nil {
+		if err := b.pusherCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("pusher: %w", err))
+		}
 	}
 
-	if err := b.pullSyncCloser.Close(); err != nil {
-		errs.add(fmt.Errorf("pull sync: %w", err))
+	if b.pullerCloser != nil {
+		if err := b.pullerCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("puller: %w", err))
+		}
+	}
+
+	if b.pullSyncCloser != nil {
+		if err := b.pullSyncCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("pull sync: %w", err))
+		}
 	}
 
 	b.p2pCancel()
@@ -16,6 +16,17 @@ var (
 	ErrPeerNotFound = errors.New("peer not found")
 	// ErrAlreadyConnected is returned if connect was called for already connected node.
 	ErrAlreadyConnected = errors.New("already connected")
+	// ErrInvalidBandwidthWeight is returned if a protocol bandwidth weight is
+	// not in the (0, 1] range.
+	ErrInvalidBandwidthWeight = errors.New("invalid bandwidth weight")
+	// ErrPeerBlocklisted is returned if the peer is on the blocklist.
+	ErrPeerBlocklisted = errors.New("peer blocklisted")
+	// ErrLightPeer is returned if a dialed peer is a light node, as light
+	// nodes are not part of the topology.
+	ErrLightPeer = errors.New("light peer")
+	// ErrConnectionLimit is returned if there are no free connection slots
+	// for the peer.
+	ErrConnectionLimit = errors.New("connection limit reached")
 )
 
 // ConnectionBackoffError indicates that connection calls will not be executed until `tryAfter` timetamp.
@@ -65,6 +76,37 @@ func (e *DisconnectError) Error() string {
 	return e.err.Error()
 }
 
+// BlockPeerError is an error that is specifically handled inside p2p. If
+// returned by specific protocol handler it causes peer to be disconnected and
+// blocklisted for the duration.
+type BlockPeerError struct {
+	duration time.Duration
+	err      error
+}
+
+// NewBlockPeerError wraps error and creates a special error that is treated
+// specially by p2p. It causes peer to be disconnected and blocklisted for the
+// duration.
+func NewBlockPeerError(duration time.Duration, err error) error {
+	return &BlockPeerError{
+		duration: duration,
+		err:      err,
+	}
+}
+
+// Unwrap returns an underlying error.
+func (e *BlockPeerError) Unwrap() error { return e.err }
+
+// Error implements function of the standard go error interface.
+func (e *BlockPeerError) Error() string {
+	return e.err.Error()
+}
+
+// Duration represents the period for which the peer will be blocked.
+func (e *BlockPeerError) Duration() time.Duration {
+	return e.duration
+}
+
 // IncompatibleStreamError is the error that should be returned by p2p service
 // NewStream method when the stream or its version is not supported.
 type IncompatibleStreamError struct {
@@ -60,6 +60,50 @@ func TestConnectDisconnect(t *testing.T) {
 	expectPeersEventually(t, s1)
 }
 
+func TestPeerInfo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{})
+
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{})
+
+	if _, err := s2.PeerInfo(overlay1); !errors.Is(err, p2p.ErrPeerNotFound) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerNotFound)
+	}
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	info, err := s2.PeerInfo(overlay1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Address.Equal(overlay1) {
+		t.Errorf("got address %s, want %s", info.Address, overlay1)
+	}
+	if info.Direction != p2p.DirectionOutbound {
+		t.Errorf("got direction %s, want %s", info.Direction, p2p.DirectionOutbound)
+	}
+	if info.ConnectedAt.IsZero() {
+		t.Error("got zero connection time")
+	}
+
+	info, err = s1.PeerInfo(overlay2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Direction != p2p.DirectionInbound {
+		t.Errorf("got direction %s, want %s", info.Direction, p2p.DirectionInbound)
+	}
+}
+
 func TestDoubleConnect(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -243,6 +287,119 @@ func TestDifferentNetworkIDs(t *testing.T) {
 	expectPeers(t, s2)
 }
 
+func TestInboundConnectionLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		MaxInbound: 1,
+	}})
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{})
+	s3, _ := newService(t, 1, libp2pServiceOpts{})
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	if _, err := s3.Connect(ctx, addr); !errors.Is(err, p2p.ErrConnectionLimit) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrConnectionLimit)
+	}
+	expectPeers(t, s3)
+	expectPeersEventually(t, s1, overlay2)
+
+	slots := s1.ConnectionSlots()
+	if slots.Inbound != 1 || slots.InboundLimit != 1 {
+		t.Fatalf("got inbound slots %v/%v, want 1/1", slots.Inbound, slots.InboundLimit)
+	}
+}
+
+func TestInboundConnectionLimitParallel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const limit = 2
+
+	s1, _ := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		MaxInbound: limit,
+	}})
+	addr := serviceUnderlayAddress(t, s1)
+
+	services := make([]*libp2p.Service, 16)
+	for i := range services {
+		services[i], _ = newService(t, 1, libp2pServiceOpts{})
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for _, s := range services {
+		wg.Add(1)
+		go func(s *libp2p.Service) {
+			defer wg.Done()
+			<-start
+			_, _ = s.Connect(ctx, addr)
+		}(s)
+	}
+	close(start)
+	wg.Wait()
+
+	// peers which were added over the limit would be disconnected
+	// asynchronously, so the count is checked for some time
+	for i := 0; i < 50; i++ {
+		if inbound := s1.ConnectionSlots().Inbound; inbound != limit {
+			t.Fatalf("got %v inbound peers, want %v", inbound, limit)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOutboundConnectionLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, _ := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		MaxOutbound: 1,
+	}})
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{})
+	s3, _ := newService(t, 1, libp2pServiceOpts{})
+
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s3)); !errors.Is(err, p2p.ErrConnectionLimit) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrConnectionLimit)
+	}
+	expectPeers(t, s1, overlay2)
+	expectPeers(t, s3)
+}
+
+func TestReservedConnectionSlots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s2, _ := newService(t, 1, libp2pServiceOpts{})
+	s3, overlay3 := newService(t, 1, libp2pServiceOpts{})
+
+	// all slots are reserved, only the pinned peer can connect
+	s1, _ := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		MaxOutbound:   1,
+		ReservedSlots: 1,
+		PinnedPeers:   []swarm.Address{overlay3},
+	}})
+
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s2)); !errors.Is(err, p2p.ErrConnectionLimit) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrConnectionLimit)
+	}
+	if _, err := s1.Connect(ctx, serviceUnderlayAddress(t, s3)); err != nil {
+		t.Fatal(err)
+	}
+	expectPeers(t, s1, overlay3)
+	expectPeersEventually(t, s2)
+}
+
 func TestConnectWithEnabledQUICAndWSTransports(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -447,6 +604,69 @@ func TestTopologyLocalNotifier(t *testing.T) {
 	waitAddrSet(t, &n2connectedAddr, &mtx, overlay1)
 }
 
+func TestTopologyLightNode(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		notified bool
+
+		n1c = func(_ context.Context, a swarm.Address) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+			notified = true
+			return nil
+		}
+		n1d = func(a swarm.Address) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			notified = true
+		}
+
+		ab1 = addressbook.New(mock.NewStateStore())
+	)
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{Addressbook: ab1})
+	s1.AddNotifier(mockNotifier(n1c, n1d))
+
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{libp2pOpts: libp2p.Options{
+		LightNode: true,
+	}})
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	// light node s2 connects to the full node s1
+	if _, err := s2.Connect(context.Background(), addr); err != nil {
+		t.Fatal(err)
+	}
+
+	expectPeers(t, s2, overlay1)
+	expectPeersEventually(t, s1, overlay2)
+
+	if peers := s1.Peers(); !peers[0].Light {
+		t.Fatal("peer is not marked as a light node")
+	}
+
+	// the light node is neither stored nor reported to the topology
+	if _, err := ab1.Get(overlay2); !errors.Is(err, addressbook.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, addressbook.ErrNotFound)
+	}
+
+	if err := s2.Disconnect(overlay1); err != nil {
+		t.Fatal(err)
+	}
+	expectPeersEventually(t, s1)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if notified {
+		t.Fatal("topology notified about a light node")
+	}
+
+	// the full node does not connect to the light node
+	if _, err := s1.Connect(context.Background(), serviceUnderlayAddress(t, s2)); !errors.Is(err, p2p.ErrLightPeer) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrLightPeer)
+	}
+}
+
 func TestTopologySupportMultipleNotifiers(t *testing.T) {
 	var (
 		mtx              sync.Mutex
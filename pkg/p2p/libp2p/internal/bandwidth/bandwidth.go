@@ -0,0 +1,252 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bandwidth provides node-wide token bucket limits for the upload and
+// download bandwidth of protocol streams.
+//
+// Every protocol has a weight in the (0, 1] range which is the fraction of the
+// bucket that the protocol is allowed to drain. Protocols with the weight of 1
+// may use all available tokens, while protocols with a lower weight only get
+// tokens when the bucket is filled above the part reserved for the others. This
+// makes low weight protocols, such as pull sync, back off first when the
+// bandwidth is saturated, while they are still able to use the whole bandwidth
+// if there is no other traffic.
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+)
+
+const (
+	// minChunk is the minimal number of bytes that a stream waits for if
+	// the bucket is drained, in order to avoid very small reads and writes.
+	minChunk = 1024
+	// defaultWeight is the weight of protocols that have no weight set.
+	defaultWeight = 1
+)
+
+// timeNow is used to deterministically mock time.Now() in tests.
+var timeNow = time.Now
+
+// Limiter enforces upload and download bandwidth limits.
+type Limiter struct {
+	upload   *bucket
+	download *bucket
+	weights  map[string]float64
+	mtx      sync.RWMutex
+}
+
+// NewLimiter creates a new Limiter with the given limits.
+func NewLimiter(l p2p.BandwidthLimits) (*Limiter, error) {
+	limiter := &Limiter{
+		upload:   newBucket(),
+		download: newBucket(),
+	}
+	if err := limiter.SetLimits(l); err != nil {
+		return nil, err
+	}
+	return limiter, nil
+}
+
+// SetLimits changes the limits and weights. Streams that are waiting for
+// bandwidth pick up the new limits on their next attempt.
+func (l *Limiter) SetLimits(limits p2p.BandwidthLimits) error {
+	weights := make(map[string]float64, len(limits.Weights))
+	for protocol, w := range limits.Weights {
+		if w <= 0 || w > 1 {
+			return fmt.Errorf("protocol %s: %w", protocol, p2p.ErrInvalidBandwidthWeight)
+		}
+		weights[protocol] = w
+	}
+
+	l.mtx.Lock()
+	l.weights = weights
+	l.mtx.Unlock()
+
+	l.upload.setRate(limits.Upload)
+	l.download.setRate(limits.Download)
+	return nil
+}
+
+// Limits returns the current limits and weights.
+func (l *Limiter) Limits() p2p.BandwidthLimits {
+	l.mtx.RLock()
+	weights := make(map[string]float64, len(l.weights))
+	for protocol, w := range l.weights {
+		weights[protocol] = w
+	}
+	l.mtx.RUnlock()
+
+	return p2p.BandwidthLimits{
+		Upload:   l.upload.getRate(),
+		Download: l.download.getRate(),
+		Weights:  weights,
+	}
+}
+
+// WaitUpload blocks until the protocol is allowed to write bytes. It returns
+// the number of bytes, at most n, that can be written.
+func (l *Limiter) WaitUpload(ctx context.Context, protocol string, n int) (int, error) {
+	return l.upload.wait(ctx, n, l.weight(protocol))
+}
+
+// ChargeDownload takes n bytes that are already read by the protocol from the
+// download bucket and blocks until the bucket is refilled above the part
+// reserved for higher weight protocols. Reads are charged after they return,
+// so that streams which wait for data do not hold any tokens.
+func (l *Limiter) ChargeDownload(ctx context.Context, protocol string, n int) error {
+	return l.download.charge(ctx, n, l.weight(protocol))
+}
+
+func (l *Limiter) weight(protocol string) float64 {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	if w, ok := l.weights[protocol]; ok {
+		return w
+	}
+	return defaultWeight
+}
+
+// bucket is a token bucket with the capacity of one second worth of tokens.
+type bucket struct {
+	rate   float64 // tokens per second, zero means unlimited
+	tokens float64
+	last   time.Time
+	mtx    sync.Mutex
+}
+
+func newBucket() *bucket {
+	return &bucket{
+		last: timeNow(),
+	}
+}
+
+func (b *bucket) setRate(rate uint64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.refill()
+	b.rate = float64(rate)
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+}
+
+func (b *bucket) getRate() uint64 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return uint64(b.rate)
+}
+
+func (b *bucket) wait(ctx context.Context, n int, weight float64) (int, error) {
+	for {
+		allowed, wait := b.reserve(n, weight)
+		if allowed > 0 {
+			return allowed, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// reserve takes up to n tokens that are available for the weight. If no
+// tokens are available, it returns the duration to wait for them.
+func (b *bucket) reserve(n int, weight float64) (allowed int, wait time.Duration) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.rate == 0 || n == 0 {
+		return n, 0
+	}
+
+	b.refill()
+
+	// tokens below the threshold are reserved for higher weight protocols
+	threshold := (1 - weight) * b.rate
+	available := b.tokens - threshold
+
+	need := float64(minChunk)
+	if max := weight * b.rate; need > max {
+		need = max
+	}
+	if need > float64(n) {
+		need = float64(n)
+	}
+	if need < 1 {
+		need = 1
+	}
+
+	if available < need {
+		return 0, time.Duration((need - available) / b.rate * float64(time.Second))
+	}
+
+	allowed = n
+	if float64(allowed) > available {
+		allowed = int(available)
+	}
+	b.tokens -= float64(allowed)
+	return allowed, 0
+}
+
+// charge takes n tokens, possibly leaving the bucket in debt, and waits
+// until the tokens are available for the weight again.
+func (b *bucket) charge(ctx context.Context, n int, weight float64) error {
+	wait := b.take(n, weight)
+	for wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		wait = b.take(0, weight)
+	}
+	return nil
+}
+
+// take removes n tokens from the bucket and returns the duration until the
+// tokens above the threshold for the weight are refilled.
+func (b *bucket) take(n int, weight float64) (wait time.Duration) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.rate == 0 {
+		return 0
+	}
+
+	b.refill()
+	b.tokens -= float64(n)
+
+	// tokens below the threshold are reserved for higher weight protocols
+	available := b.tokens - (1-weight)*b.rate
+	if available >= 0 {
+		return 0
+	}
+	return time.Duration(-available / b.rate * float64(time.Second))
+}
+
+// refill adds tokens for the time passed since the last refill. It must be
+// called with the lock held.
+func (b *bucket) refill() {
+	now := timeNow()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+}
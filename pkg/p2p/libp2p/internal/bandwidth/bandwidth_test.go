@@ -0,0 +1,142 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bandwidth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/bandwidth"
+)
+
+func TestUnlimited(t *testing.T) {
+	l, err := bandwidth.NewLimiter(p2p.BandwidthLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := l.WaitUpload(context.Background(), "retrieval", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1<<20 {
+		t.Fatalf("got %d allowed bytes, want %d", n, 1<<20)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	now := time.Now()
+	bandwidth.SetTimeNow(func() time.Time { return now })
+	defer bandwidth.SetTimeNow(time.Now)
+
+	l, err := bandwidth.NewLimiter(p2p.BandwidthLimits{
+		Upload:   10000,
+		Download: 10000,
+		Weights:  map[string]float64{"pullsync": 0.5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fill the bucket
+	now = now.Add(time.Second)
+
+	n, err := l.WaitUpload(context.Background(), "retrieval", 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10000 {
+		t.Fatalf("got %d allowed bytes, want %d", n, 10000)
+	}
+
+	// refill the half of the bucket that is reserved for higher weights
+	now = now.Add(500 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.WaitUpload(ctx, "pullsync", 4096); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	n, err = l.WaitUpload(context.Background(), "retrieval", 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4096 {
+		t.Fatalf("got %d allowed bytes, want %d", n, 4096)
+	}
+
+	// background protocol gets the tokens above its threshold
+	now = now.Add(time.Second)
+
+	n, err = l.WaitUpload(context.Background(), "pullsync", 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5000 {
+		t.Fatalf("got %d allowed bytes, want %d", n, 5000)
+	}
+
+	// download bucket is not affected by uploads, reads are charged
+	// after they return
+	if err := l.ChargeDownload(context.Background(), "retrieval", 4000); err != nil {
+		t.Fatal(err)
+	}
+
+	// background protocol waits for the tokens above its threshold
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.ChargeDownload(ctx, "pullsync", 2000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// higher weight protocol waits for the debt to be refilled
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.ChargeDownload(ctx, "retrieval", 5000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	now = now.Add(time.Second)
+
+	if err := l.ChargeDownload(context.Background(), "retrieval", 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetLimits(t *testing.T) {
+	l, err := bandwidth.NewLimiter(p2p.BandwidthLimits{Upload: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, w := range []float64{0, -1, 1.5} {
+		err := l.SetLimits(p2p.BandwidthLimits{Weights: map[string]float64{"pullsync": w}})
+		if !errors.Is(err, p2p.ErrInvalidBandwidthWeight) {
+			t.Fatalf("weight %v: got error %v, want %v", w, err, p2p.ErrInvalidBandwidthWeight)
+		}
+	}
+
+	want := p2p.BandwidthLimits{
+		Upload:   1000,
+		Download: 2000,
+		Weights:  map[string]float64{"pullsync": 0.5},
+	}
+	if err := l.SetLimits(want); err != nil {
+		t.Fatal(err)
+	}
+
+	got := l.Limits()
+	if got.Upload != want.Upload || got.Download != want.Download || got.Weights["pullsync"] != 0.5 || len(got.Weights) != 1 {
+		t.Fatalf("got limits %+v, want %+v", got, want)
+	}
+
+	if _, err := bandwidth.NewLimiter(p2p.BandwidthLimits{Weights: map[string]float64{"pullsync": 2}}); !errors.Is(err, p2p.ErrInvalidBandwidthWeight) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrInvalidBandwidthWeight)
+	}
+}
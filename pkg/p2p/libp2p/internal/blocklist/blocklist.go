@@ -0,0 +1,119 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package blocklist keeps the overlay addresses of peers that are not allowed
+// to connect, persisted in the state store.
+package blocklist
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const keyPrefix = "blocklist-"
+
+// timeNow is used to deterministically mock time.Now() in tests.
+var timeNow = time.Now
+
+// Blocklist is the persisted list of blocked peers.
+type Blocklist struct {
+	store storage.StateStorer
+}
+
+type entry struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration"` // zero means permanent
+}
+
+// NewBlocklist creates a new Blocklist backed by the state store.
+func NewBlocklist(store storage.StateStorer) *Blocklist {
+	return &Blocklist{store: store}
+}
+
+// Exists reports whether the peer is blocked. Expired entries are removed.
+func (b *Blocklist) Exists(overlay swarm.Address) (bool, error) {
+	var e entry
+	if err := b.store.Get(generateKey(overlay), &e); err != nil {
+		if err == storage.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if e.expired(timeNow()) {
+		if err := b.store.Delete(generateKey(overlay)); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// Add blocks the peer for the duration. Zero duration blocks the peer
+// permanently.
+func (b *Blocklist) Add(overlay swarm.Address, duration time.Duration) error {
+	return b.store.Put(generateKey(overlay), &entry{
+		Timestamp: timeNow(),
+		Duration:  duration,
+	})
+}
+
+// Remove unblocks the peer. It returns p2p.ErrPeerNotFound if the peer is not
+// blocked.
+func (b *Blocklist) Remove(overlay swarm.Address) error {
+	if err := b.store.Get(generateKey(overlay), &entry{}); err != nil {
+		if err == storage.ErrNotFound {
+			return p2p.ErrPeerNotFound
+		}
+		return err
+	}
+	return b.store.Delete(generateKey(overlay))
+}
+
+// Peers returns all peers that are currently blocked.
+func (b *Blocklist) Peers() ([]p2p.BlockedPeer, error) {
+	now := timeNow()
+	var peers []p2p.BlockedPeer
+	if err := b.store.Iterate(keyPrefix, func(k, v []byte) (bool, error) {
+		addr, err := unmarshalKey(string(k))
+		if err != nil {
+			return true, err
+		}
+
+		var e entry
+		if err := json.Unmarshal(v, &e); err != nil {
+			return true, err
+		}
+		if e.expired(now) {
+			return false, nil
+		}
+
+		peers = append(peers, p2p.BlockedPeer{
+			Address:   addr,
+			Timestamp: e.Timestamp,
+			Duration:  e.Duration,
+		})
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+func (e entry) expired(now time.Time) bool {
+	return e.Duration > 0 && now.After(e.Timestamp.Add(e.Duration))
+}
+
+func generateKey(overlay swarm.Address) string {
+	return keyPrefix + overlay.String()
+}
+
+func unmarshalKey(s string) (swarm.Address, error) {
+	return swarm.ParseHexAddress(strings.TrimPrefix(s, keyPrefix))
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package blocklist_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/blocklist"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestExistsAndExpiry(t *testing.T) {
+	addr1 := swarm.NewAddress([]byte{0, 1, 2, 3})
+	addr2 := swarm.NewAddress([]byte{4, 5, 6, 7})
+
+	now := time.Now()
+	blocklist.SetTimeNow(func() time.Time { return now })
+	defer blocklist.SetTimeNow(time.Now)
+
+	bl := blocklist.NewBlocklist(mock.NewStateStore())
+
+	if err := bl.Add(addr1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.Add(addr2, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	mustExist(t, bl, addr1, true)
+	mustExist(t, bl, addr2, true)
+
+	now = now.Add(2 * time.Minute)
+
+	mustExist(t, bl, addr1, false)
+	mustExist(t, bl, addr2, true)
+}
+
+func TestRemove(t *testing.T) {
+	addr := swarm.NewAddress([]byte{0, 1, 2, 3})
+
+	bl := blocklist.NewBlocklist(mock.NewStateStore())
+
+	if err := bl.Remove(addr); !errors.Is(err, p2p.ErrPeerNotFound) {
+		t.Fatalf("got error %v, want %v", err, p2p.ErrPeerNotFound)
+	}
+
+	if err := bl.Add(addr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.Remove(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	mustExist(t, bl, addr, false)
+}
+
+func TestPeers(t *testing.T) {
+	addr1 := swarm.NewAddress([]byte{0, 1, 2, 3})
+	addr2 := swarm.NewAddress([]byte{4, 5, 6, 7})
+
+	now := time.Now()
+	blocklist.SetTimeNow(func() time.Time { return now })
+	defer blocklist.SetTimeNow(time.Now)
+
+	bl := blocklist.NewBlocklist(mock.NewStateStore())
+
+	if err := bl.Add(addr1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.Add(addr2, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	peers, err := bl.Peers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("got %v peers, want 2", len(peers))
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	peers, err = bl.Peers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 {
+		t.Fatalf("got %v peers, want 1", len(peers))
+	}
+	if !peers[0].Address.Equal(addr2) {
+		t.Fatalf("got peer %s, want %s", peers[0].Address, addr2)
+	}
+	if peers[0].Duration != time.Hour {
+		t.Fatalf("got duration %v, want %v", peers[0].Duration, time.Hour)
+	}
+}
+
+func mustExist(t *testing.T, bl *blocklist.Blocklist, addr swarm.Address, want bool) {
+	t.Helper()
+
+	got, err := bl.Exists(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("peer %s exists %v, want %v", addr, got, want)
+	}
+}
@@ -58,6 +58,10 @@ type AdvertisableAddressResolver interface {
 	Resolve(observedAdddress ma.Multiaddr) (ma.Multiaddr, error)
 }
 
+// PickerFunc reports whether an inbound peer with the overlay address should
+// be accepted. The overlay address is zero if the peer does not announce it.
+type PickerFunc func(overlay swarm.Address) bool
+
 // Service can perform initiate or handle a handshake between peers.
 type Service struct {
 	signer                crypto.Signer
@@ -68,6 +72,9 @@ type Service struct {
 	welcomeMessage        atomic.Value
 	receivedHandshakes    map[libp2ppeer.ID]struct{}
 	receivedHandshakesMu  sync.Mutex
+	picker                PickerFunc
+	protocols             []Protocol
+	protocolsMu           sync.RWMutex
 	logger                logging.Logger
 
 	network.Notifiee // handshake service can be the receiver for network.Notify
@@ -77,6 +84,17 @@ type Service struct {
 type Info struct {
 	BzzAddress *bzz.Address
 	Light      bool
+	// Protocols are the protocol versions that the peer supports. It is
+	// empty if the peer does not advertise its protocols.
+	Protocols []Protocol
+	// WelcomeMessage is the greeting that the peer sent in the handshake.
+	WelcomeMessage string
+}
+
+// Protocol is the name and the version of a supported protocol.
+type Protocol struct {
+	Name    string
+	Version string
 }
 
 // New creates a new handshake Service.
@@ -115,6 +133,7 @@ func (s *Service) Handshake(stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerI
 
 	if err := w.WriteMsgWithTimeout(messageTimeout, &pb.Syn{
 		ObservedUnderlay: fullRemoteMABytes,
+		Overlay:          s.overlay.Bytes(),
 	}); err != nil {
 		return nil, fmt.Errorf("write syn message: %w", err)
 	}
@@ -124,6 +143,10 @@ func (s *Service) Handshake(stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerI
 		return nil, fmt.Errorf("read synack message: %w", err)
 	}
 
+	if resp.ConnectionLimit {
+		return nil, p2p.ErrConnectionLimit
+	}
+
 	remoteBzzAddress, err := s.parseCheckAck(resp.Ack)
 	if err != nil {
 		return nil, err
@@ -159,6 +182,7 @@ func (s *Service) Handshake(stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerI
 		},
 		NetworkID:      s.networkID,
 		Light:          s.lightNode,
+		Protocols:      s.pbProtocols(),
 		WelcomeMessage: welcomeMessage,
 	}); err != nil {
 		return nil, fmt.Errorf("write ack message: %w", err)
@@ -170,8 +194,10 @@ func (s *Service) Handshake(stream p2p.Stream, peerMultiaddr ma.Multiaddr, peerI
 	}
 
 	return &Info{
-		BzzAddress: remoteBzzAddress,
-		Light:      resp.Ack.Light,
+		BzzAddress:     remoteBzzAddress,
+		Light:          resp.Ack.Light,
+		Protocols:      parseProtocols(resp.Ack.Protocols),
+		WelcomeMessage: resp.Ack.WelcomeMessage,
 	}, nil
 }
 
@@ -223,6 +249,10 @@ func (s *Service) Handle(stream p2p.Stream, remoteMultiaddr ma.Multiaddr, remote
 
 	welcomeMessage := s.GetWelcomeMessage()
 
+	// the ack is sent even if the peer is rejected, as the peer expects it
+	remoteOverlay := swarm.NewAddress(syn.Overlay)
+	rejected := s.picker != nil && !s.picker(remoteOverlay)
+
 	if err := w.WriteMsgWithTimeout(messageTimeout, &pb.SynAck{
 		Syn: &pb.Syn{
 			ObservedUnderlay: fullRemoteMABytes,
@@ -235,12 +265,18 @@ func (s *Service) Handle(stream p2p.Stream, remoteMultiaddr ma.Multiaddr, remote
 			},
 			NetworkID:      s.networkID,
 			Light:          s.lightNode,
+			Protocols:      s.pbProtocols(),
 			WelcomeMessage: welcomeMessage,
 		},
+		ConnectionLimit: rejected,
 	}); err != nil {
 		return nil, fmt.Errorf("write synack message: %w", err)
 	}
 
+	if rejected {
+		return nil, p2p.ErrConnectionLimit
+	}
+
 	var ack pb.Ack
 	if err := r.ReadMsgWithTimeout(messageTimeout, &ack); err != nil {
 		return nil, fmt.Errorf("read ack message: %w", err)
@@ -251,14 +287,58 @@ func (s *Service) Handle(stream p2p.Stream, remoteMultiaddr ma.Multiaddr, remote
 		return nil, err
 	}
 
+	// the overlay that the peer was accepted with must be the signed one
+	if !remoteOverlay.IsZero() && !remoteOverlay.Equal(remoteBzzAddress.Overlay) {
+		return nil, ErrInvalidAck
+	}
+
 	s.logger.Tracef("handshake finished for peer (inbound) %s", remoteBzzAddress.Overlay.String())
 
 	return &Info{
-		BzzAddress: remoteBzzAddress,
-		Light:      ack.Light,
+		BzzAddress:     remoteBzzAddress,
+		Light:          ack.Light,
+		Protocols:      parseProtocols(ack.Protocols),
+		WelcomeMessage: ack.WelcomeMessage,
 	}, nil
 }
 
+// SetPicker sets the function that decides whether inbound peers are
+// accepted. Rejected peers are notified that the connection limit is reached.
+func (s *Service) SetPicker(f PickerFunc) {
+	s.picker = f
+}
+
+// AddProtocol adds the protocol version to the list of supported protocols
+// that is advertised to peers.
+func (s *Service) AddProtocol(name, version string) {
+	s.protocolsMu.Lock()
+	defer s.protocolsMu.Unlock()
+
+	s.protocols = append(s.protocols, Protocol{Name: name, Version: version})
+}
+
+func (s *Service) pbProtocols() []*pb.Protocol {
+	s.protocolsMu.RLock()
+	defer s.protocolsMu.RUnlock()
+
+	protocols := make([]*pb.Protocol, 0, len(s.protocols))
+	for _, p := range s.protocols {
+		protocols = append(protocols, &pb.Protocol{Name: p.Name, Version: p.Version})
+	}
+	return protocols
+}
+
+func parseProtocols(pbProtocols []*pb.Protocol) []Protocol {
+	if len(pbProtocols) == 0 {
+		return nil
+	}
+	protocols := make([]Protocol, 0, len(pbProtocols))
+	for _, p := range pbProtocols {
+		protocols = append(protocols, Protocol{Name: p.Name, Version: p.Version})
+	}
+	return protocols
+}
+
 // Disconnected is called when the peer disconnects.
 func (s *Service) Disconnected(_ network.Network, c network.Conn) {
 	s.receivedHandshakesMu.Lock()
@@ -9,15 +9,18 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"reflect"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/bzz"
 	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake/mock"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake/pb"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/swarm"
 
 	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
 	ma "github.com/multiformats/go-multiaddr"
@@ -127,6 +130,9 @@ func TestHandshake(t *testing.T) {
 		}
 
 		testInfo(t, *res, node2Info)
+		if res.WelcomeMessage != testWelcomeMessage {
+			t.Fatalf("got welcome message %q, want %q", res.WelcomeMessage, testWelcomeMessage)
+		}
 
 		var syn pb.Syn
 		if err := r.ReadMsg(&syn); err != nil {
@@ -324,6 +330,105 @@ func TestHandshake(t *testing.T) {
 		}
 	})
 
+	t.Run("Handshake - protocols", func(t *testing.T) {
+		handshakeService, err := handshake.New(signer1, aaddresser, node1Info.BzzAddress.Overlay, networkID, false, "", logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handshakeService.AddProtocol("pushsync", "1.0.0")
+		handshakeService.AddProtocol("pushsync", "2.0.0")
+
+		var buffer1 bytes.Buffer
+		var buffer2 bytes.Buffer
+		stream1 := mock.NewStream(&buffer1, &buffer2)
+		stream2 := mock.NewStream(&buffer2, &buffer1)
+
+		w, r := protobuf.NewWriterAndReader(stream2)
+		if err := w.WriteMsg(&pb.SynAck{
+			Syn: &pb.Syn{
+				ObservedUnderlay: node1maBinary,
+			},
+			Ack: &pb.Ack{
+				Address: &pb.BzzAddress{
+					Underlay:  node2maBinary,
+					Overlay:   node2BzzAddress.Overlay.Bytes(),
+					Signature: node2BzzAddress.Signature,
+				},
+				NetworkID: networkID,
+				Protocols: []*pb.Protocol{{Name: "retrieval", Version: "1.1.0"}},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := handshakeService.Handshake(stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []handshake.Protocol{{Name: "retrieval", Version: "1.1.0"}}
+		if !reflect.DeepEqual(res.Protocols, want) {
+			t.Fatalf("got protocols %v, want %v", res.Protocols, want)
+		}
+
+		var syn pb.Syn
+		if err := r.ReadMsg(&syn); err != nil {
+			t.Fatal(err)
+		}
+
+		var ack pb.Ack
+		if err := r.ReadMsg(&ack); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ack.Protocols) != 2 ||
+			ack.Protocols[0].Name != "pushsync" || ack.Protocols[0].Version != "1.0.0" ||
+			ack.Protocols[1].Name != "pushsync" || ack.Protocols[1].Version != "2.0.0" {
+			t.Fatalf("bad ack protocols %v", ack.Protocols)
+		}
+	})
+
+	t.Run("Handshake - connection limit", func(t *testing.T) {
+		var buffer1 bytes.Buffer
+		var buffer2 bytes.Buffer
+		stream1 := mock.NewStream(&buffer1, &buffer2)
+		stream2 := mock.NewStream(&buffer2, &buffer1)
+
+		w, r := protobuf.NewWriterAndReader(stream2)
+		if err := w.WriteMsg(&pb.SynAck{
+			Syn: &pb.Syn{
+				ObservedUnderlay: node1maBinary,
+			},
+			Ack: &pb.Ack{
+				Address: &pb.BzzAddress{
+					Underlay:  node2maBinary,
+					Overlay:   node2BzzAddress.Overlay.Bytes(),
+					Signature: node2BzzAddress.Signature,
+				},
+				NetworkID: networkID,
+			},
+			ConnectionLimit: true,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := handshakeService.Handshake(stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+		if !errors.Is(err, p2p.ErrConnectionLimit) {
+			t.Fatalf("got error %v, want %v", err, p2p.ErrConnectionLimit)
+		}
+		if res != nil {
+			t.Fatal("expected nil res")
+		}
+
+		var syn pb.Syn
+		if err := r.ReadMsg(&syn); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(syn.Overlay, node1BzzAddress.Overlay.Bytes()) {
+			t.Fatal("syn without overlay")
+		}
+	})
+
 	t.Run("Handshake - error advertisable address", func(t *testing.T) {
 		var buffer1 bytes.Buffer
 		var buffer2 bytes.Buffer
@@ -634,6 +739,91 @@ func TestHandshake(t *testing.T) {
 		}
 	})
 
+	t.Run("Handle - connection limit", func(t *testing.T) {
+		handshakeService, err := handshake.New(signer1, aaddresser, node1Info.BzzAddress.Overlay, networkID, false, "", logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var picked swarm.Address
+		handshakeService.SetPicker(func(overlay swarm.Address) bool {
+			picked = overlay
+			return false
+		})
+
+		var buffer1 bytes.Buffer
+		var buffer2 bytes.Buffer
+		stream1 := mock.NewStream(&buffer1, &buffer2)
+		stream2 := mock.NewStream(&buffer2, &buffer1)
+
+		w := protobuf.NewWriter(stream2)
+		if err := w.WriteMsg(&pb.Syn{
+			ObservedUnderlay: node1maBinary,
+			Overlay:          node2BzzAddress.Overlay.Bytes(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := handshakeService.Handle(stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+		if !errors.Is(err, p2p.ErrConnectionLimit) {
+			t.Fatalf("got error %v, want %v", err, p2p.ErrConnectionLimit)
+		}
+		if res != nil {
+			t.Fatal("expected nil res")
+		}
+		if !picked.Equal(node2BzzAddress.Overlay) {
+			t.Fatalf("got picked overlay %s, want %s", picked, node2BzzAddress.Overlay)
+		}
+
+		_, r := protobuf.NewWriterAndReader(stream2)
+		var got pb.SynAck
+		if err := r.ReadMsg(&got); err != nil {
+			t.Fatal(err)
+		}
+		if !got.ConnectionLimit {
+			t.Fatal("connection limit not signaled")
+		}
+	})
+
+	t.Run("Handle - overlay mismatch", func(t *testing.T) {
+		handshakeService, err := handshake.New(signer1, aaddresser, node1Info.BzzAddress.Overlay, networkID, false, "", logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handshakeService.SetPicker(func(overlay swarm.Address) bool {
+			return true
+		})
+
+		var buffer1 bytes.Buffer
+		var buffer2 bytes.Buffer
+		stream1 := mock.NewStream(&buffer1, &buffer2)
+		stream2 := mock.NewStream(&buffer2, &buffer1)
+
+		w := protobuf.NewWriter(stream2)
+		if err := w.WriteMsg(&pb.Syn{
+			ObservedUnderlay: node1maBinary,
+			Overlay:          node1BzzAddress.Overlay.Bytes(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.WriteMsg(&pb.Ack{
+			Address: &pb.BzzAddress{
+				Underlay:  node2maBinary,
+				Overlay:   node2BzzAddress.Overlay.Bytes(),
+				Signature: node2BzzAddress.Signature,
+			},
+			NetworkID: networkID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = handshakeService.Handle(stream1, node2AddrInfo.Addrs[0], node2AddrInfo.ID)
+		if err != handshake.ErrInvalidAck {
+			t.Fatalf("expected %s, got %v", handshake.ErrInvalidAck, err)
+		}
+	})
+
 	t.Run("Handle - advertisable error", func(t *testing.T) {
 		handshakeService, err := handshake.New(signer1, aaddresser, node1Info.BzzAddress.Overlay, networkID, false, "", logger)
 		if err != nil {
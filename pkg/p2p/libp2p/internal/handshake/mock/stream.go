@@ -56,6 +56,10 @@ func (s *Stream) Headers() p2p.Headers {
 	return nil
 }
 
+func (s *Stream) ProtocolVersion() string {
+	return ""
+}
+
 func (s *Stream) Close() error {
 	return nil
 }
@@ -24,6 +24,7 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 type Syn struct {
 	ObservedUnderlay []byte `protobuf:"bytes,1,opt,name=ObservedUnderlay,proto3" json:"ObservedUnderlay,omitempty"`
+	Overlay          []byte `protobuf:"bytes,2,opt,name=Overlay,proto3" json:"Overlay,omitempty"`
 }
 
 func (m *Syn) Reset()         { *m = Syn{} }
@@ -66,10 +67,18 @@ func (m *Syn) GetObservedUnderlay() []byte {
 	return nil
 }
 
+func (m *Syn) GetOverlay() []byte {
+	if m != nil {
+		return m.Overlay
+	}
+	return nil
+}
+
 type Ack struct {
 	Address        *BzzAddress `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
 	NetworkID      uint64      `protobuf:"varint,2,opt,name=NetworkID,proto3" json:"NetworkID,omitempty"`
 	Light          bool        `protobuf:"varint,3,opt,name=Light,proto3" json:"Light,omitempty"`
+	Protocols      []*Protocol `protobuf:"bytes,4,rep,name=Protocols,proto3" json:"Protocols,omitempty"`
 	WelcomeMessage string      `protobuf:"bytes,99,opt,name=WelcomeMessage,proto3" json:"WelcomeMessage,omitempty"`
 }
 
@@ -127,6 +136,13 @@ func (m *Ack) GetLight() bool {
 	return false
 }
 
+func (m *Ack) GetProtocols() []*Protocol {
+	if m != nil {
+		return m.Protocols
+	}
+	return nil
+}
+
 func (m *Ack) GetWelcomeMessage() string {
 	if m != nil {
 		return m.WelcomeMessage
@@ -134,16 +150,69 @@ func (m *Ack) GetWelcomeMessage() string {
 	return ""
 }
 
+type Protocol struct {
+	Name    string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Version string `protobuf:"bytes,2,opt,name=Version,proto3" json:"Version,omitempty"`
+}
+
+func (m *Protocol) Reset()         { *m = Protocol{} }
+func (m *Protocol) String() string { return proto.CompactTextString(m) }
+func (*Protocol) ProtoMessage()    {}
+func (*Protocol) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a77305914d5d202f, []int{2}
+}
+func (m *Protocol) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Protocol) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Protocol.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Protocol) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Protocol.Merge(m, src)
+}
+func (m *Protocol) XXX_Size() int {
+	return m.Size()
+}
+func (m *Protocol) XXX_DiscardUnknown() {
+	xxx_messageInfo_Protocol.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Protocol proto.InternalMessageInfo
+
+func (m *Protocol) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Protocol) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 type SynAck struct {
-	Syn *Syn `protobuf:"bytes,1,opt,name=Syn,proto3" json:"Syn,omitempty"`
-	Ack *Ack `protobuf:"bytes,2,opt,name=Ack,proto3" json:"Ack,omitempty"`
+	Syn             *Syn `protobuf:"bytes,1,opt,name=Syn,proto3" json:"Syn,omitempty"`
+	Ack             *Ack `protobuf:"bytes,2,opt,name=Ack,proto3" json:"Ack,omitempty"`
+	ConnectionLimit bool `protobuf:"varint,3,opt,name=ConnectionLimit,proto3" json:"ConnectionLimit,omitempty"`
 }
 
 func (m *SynAck) Reset()         { *m = SynAck{} }
 func (m *SynAck) String() string { return proto.CompactTextString(m) }
 func (*SynAck) ProtoMessage()    {}
 func (*SynAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a77305914d5d202f, []int{2}
+	return fileDescriptor_a77305914d5d202f, []int{3}
 }
 func (m *SynAck) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -186,6 +255,13 @@ func (m *SynAck) GetAck() *Ack {
 	return nil
 }
 
+func (m *SynAck) GetConnectionLimit() bool {
+	if m != nil {
+		return m.ConnectionLimit
+	}
+	return false
+}
+
 type BzzAddress struct {
 	Underlay  []byte `protobuf:"bytes,1,opt,name=Underlay,proto3" json:"Underlay,omitempty"`
 	Signature []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
@@ -196,7 +272,7 @@ func (m *BzzAddress) Reset()         { *m = BzzAddress{} }
 func (m *BzzAddress) String() string { return proto.CompactTextString(m) }
 func (*BzzAddress) ProtoMessage()    {}
 func (*BzzAddress) Descriptor() ([]byte, []int) {
-	return fileDescriptor_a77305914d5d202f, []int{3}
+	return fileDescriptor_a77305914d5d202f, []int{4}
 }
 func (m *BzzAddress) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -249,6 +325,7 @@ func (m *BzzAddress) GetOverlay() []byte {
 func init() {
 	proto.RegisterType((*Syn)(nil), "handshake.Syn")
 	proto.RegisterType((*Ack)(nil), "handshake.Ack")
+	proto.RegisterType((*Protocol)(nil), "handshake.Protocol")
 	proto.RegisterType((*SynAck)(nil), "handshake.SynAck")
 	proto.RegisterType((*BzzAddress)(nil), "handshake.BzzAddress")
 }
@@ -256,26 +333,31 @@ func init() {
 func init() { proto.RegisterFile("handshake.proto", fileDescriptor_a77305914d5d202f) }
 
 var fileDescriptor_a77305914d5d202f = []byte{
-	// 302 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0x4d, 0x4b, 0xc3, 0x30,
-	0x1c, 0xc6, 0x97, 0x55, 0xf7, 0xf2, 0x77, 0x4c, 0x09, 0x0a, 0x45, 0x46, 0x08, 0x3d, 0x48, 0xf1,
-	0x30, 0x51, 0x3f, 0xc1, 0x86, 0x17, 0x61, 0x3a, 0x48, 0x11, 0xc1, 0x93, 0x7d, 0x09, 0xed, 0xe8,
-	0x4c, 0x47, 0x52, 0x27, 0xdd, 0xa7, 0x10, 0x3f, 0x95, 0xc7, 0x1d, 0x3d, 0x4a, 0xfb, 0x45, 0xa4,
-	0xd9, 0x4b, 0x75, 0x1e, 0x9f, 0x97, 0x36, 0xbf, 0x27, 0x81, 0xc3, 0xc8, 0x15, 0x81, 0x8a, 0xdc,
-	0x98, 0xf7, 0x67, 0x32, 0x49, 0x13, 0xdc, 0xde, 0x1a, 0xd6, 0x25, 0x18, 0x4e, 0x26, 0xf0, 0x39,
-	0x1c, 0x8d, 0x3d, 0xc5, 0xe5, 0x9c, 0x07, 0x0f, 0x22, 0xe0, 0x72, 0xea, 0x66, 0x26, 0xa2, 0xc8,
-	0xee, 0xb0, 0x7f, 0xbe, 0xf5, 0x81, 0xc0, 0x18, 0xf8, 0x31, 0xbe, 0x80, 0xe6, 0x20, 0x08, 0x24,
-	0x57, 0x4a, 0x57, 0x0f, 0xae, 0x4e, 0xfa, 0xd5, 0x41, 0xc3, 0xc5, 0x62, 0x1d, 0xb2, 0x4d, 0x0b,
-	0xf7, 0xa0, 0x7d, 0xcf, 0xd3, 0xb7, 0x44, 0xc6, 0xb7, 0x37, 0x66, 0x9d, 0x22, 0x7b, 0x8f, 0x55,
-	0x06, 0x3e, 0x86, 0xfd, 0xd1, 0x24, 0x8c, 0x52, 0xd3, 0xa0, 0xc8, 0x6e, 0xb1, 0x95, 0xc0, 0x67,
-	0xd0, 0x7d, 0xe4, 0x53, 0x3f, 0x79, 0xe1, 0x77, 0x5c, 0x29, 0x37, 0xe4, 0xa6, 0x4f, 0x91, 0xdd,
-	0x66, 0x3b, 0xae, 0x35, 0x82, 0x86, 0x93, 0x89, 0x12, 0x8b, 0xea, 0x45, 0x6b, 0xa4, 0xee, 0x2f,
-	0x24, 0x27, 0x13, 0x4c, 0x8f, 0xa5, 0x9a, 0x5f, 0x13, 0xfc, 0x6d, 0x0c, 0xfc, 0x98, 0x95, 0x91,
-	0xf5, 0x0c, 0x50, 0x0d, 0xc0, 0xa7, 0xd0, 0xda, 0xb9, 0x94, 0xad, 0x2e, 0x37, 0x39, 0x93, 0x50,
-	0xb8, 0xe9, 0xab, 0xe4, 0xfa, 0x8f, 0x1d, 0x56, 0x19, 0xd8, 0x84, 0xe6, 0x78, 0xbe, 0xfa, 0xd0,
-	0xd0, 0xd9, 0x46, 0x0e, 0x7b, 0x9f, 0x39, 0x41, 0xcb, 0x9c, 0xa0, 0xef, 0x9c, 0xa0, 0xf7, 0x82,
-	0xd4, 0x96, 0x05, 0xa9, 0x7d, 0x15, 0xa4, 0xf6, 0x54, 0x9f, 0x79, 0x5e, 0x43, 0xbf, 0xd3, 0xf5,
-	0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x9d, 0x61, 0xea, 0x46, 0xba, 0x01, 0x00, 0x00,
+	// 373 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x92, 0xc1, 0x4e, 0xf2, 0x40,
+	0x14, 0x85, 0x19, 0xca, 0x0f, 0xf4, 0xf2, 0x07, 0xcc, 0xa8, 0x49, 0x63, 0x48, 0xd3, 0x74, 0x61,
+	0x1a, 0x17, 0x18, 0x71, 0xe3, 0x16, 0x74, 0x63, 0x44, 0x30, 0xd3, 0xa8, 0x89, 0x2b, 0x4b, 0x3b,
+	0x81, 0xa6, 0x30, 0x43, 0x3a, 0x15, 0x53, 0x9e, 0xc2, 0x97, 0x32, 0x71, 0xc9, 0xd2, 0xa5, 0x81,
+	0x17, 0x31, 0x1d, 0x28, 0x45, 0xd8, 0xf5, 0x9c, 0x39, 0x9d, 0xde, 0xfb, 0x9d, 0x42, 0x6d, 0xe8,
+	0x30, 0x4f, 0x0c, 0x9d, 0x80, 0x36, 0x26, 0x21, 0x8f, 0x38, 0x56, 0x37, 0x86, 0x79, 0x07, 0x8a,
+	0x1d, 0x33, 0x7c, 0x06, 0x07, 0xbd, 0xbe, 0xa0, 0xe1, 0x94, 0x7a, 0x8f, 0xcc, 0xa3, 0xe1, 0xc8,
+	0x89, 0x35, 0x64, 0x20, 0xeb, 0x3f, 0xd9, 0xf3, 0xb1, 0x06, 0xa5, 0xde, 0x74, 0x15, 0xc9, 0xcb,
+	0x48, 0x2a, 0xcd, 0x4f, 0x04, 0x4a, 0xcb, 0x0d, 0xf0, 0x39, 0x94, 0x5a, 0x9e, 0x17, 0x52, 0x21,
+	0xe4, 0x25, 0x95, 0xe6, 0x71, 0x23, 0x1b, 0xa1, 0x3d, 0x9b, 0xad, 0x0f, 0x49, 0x9a, 0xc2, 0x75,
+	0x50, 0xbb, 0x34, 0x7a, 0xe7, 0x61, 0x70, 0x7b, 0x23, 0x2f, 0x2d, 0x90, 0xcc, 0xc0, 0x47, 0xf0,
+	0xaf, 0xe3, 0x0f, 0x86, 0x91, 0xa6, 0x18, 0xc8, 0x2a, 0x93, 0x95, 0xc0, 0x17, 0xa0, 0x3e, 0x24,
+	0xdb, 0xb8, 0x7c, 0x24, 0xb4, 0x82, 0xa1, 0x58, 0x95, 0xe6, 0xe1, 0xd6, 0x67, 0xd2, 0x33, 0x92,
+	0xa5, 0xf0, 0x29, 0x54, 0x9f, 0xe9, 0xc8, 0xe5, 0x63, 0x7a, 0x4f, 0x85, 0x70, 0x06, 0x54, 0x73,
+	0x0d, 0x64, 0xa9, 0x64, 0xc7, 0x35, 0xaf, 0xa0, 0x9c, 0xbe, 0x84, 0x31, 0x14, 0xba, 0xce, 0x98,
+	0xca, 0x45, 0x54, 0x22, 0x9f, 0x13, 0x02, 0x4f, 0x34, 0x14, 0x3e, 0x67, 0x72, 0x58, 0x95, 0xa4,
+	0xd2, 0x9c, 0x42, 0xd1, 0x8e, 0x59, 0xc2, 0xc0, 0x90, 0x60, 0xd7, 0xfb, 0x57, 0xb7, 0x06, 0xb3,
+	0x63, 0x46, 0x24, 0x73, 0x43, 0xc2, 0xd2, 0xf2, 0x7b, 0x89, 0x96, 0x1b, 0x10, 0xc9, 0xd1, 0x82,
+	0xda, 0x35, 0x67, 0x8c, 0xba, 0x91, 0xcf, 0x59, 0xc7, 0x1f, 0xfb, 0x29, 0x82, 0x5d, 0xdb, 0x7c,
+	0x05, 0xc8, 0xb8, 0xe2, 0x13, 0x28, 0xef, 0xb4, 0xb8, 0xd1, 0x09, 0x6a, 0xdb, 0x1f, 0x30, 0x27,
+	0x7a, 0x0b, 0xe9, 0xba, 0xbf, 0xcc, 0xd8, 0xee, 0x56, 0xf9, 0xd3, 0x6d, 0xbb, 0xfe, 0xb5, 0xd0,
+	0xd1, 0x7c, 0xa1, 0xa3, 0x9f, 0x85, 0x8e, 0x3e, 0x96, 0x7a, 0x6e, 0xbe, 0xd4, 0x73, 0xdf, 0x4b,
+	0x3d, 0xf7, 0x92, 0x9f, 0xf4, 0xfb, 0x45, 0xf9, 0x63, 0x5d, 0xfe, 0x0e, 0x00, 0x56, 0x40, 0x29,
+	0x56, 0x6b, 0x02, 0x00, 0x00,
 }
 
 func (m *Syn) Marshal() (dAtA []byte, err error) {
@@ -298,6 +380,13 @@ func (m *Syn) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Overlay) > 0 {
+		i -= len(m.Overlay)
+		copy(dAtA[i:], m.Overlay)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Overlay)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.ObservedUnderlay) > 0 {
 		i -= len(m.ObservedUnderlay)
 		copy(dAtA[i:], m.ObservedUnderlay)
@@ -337,6 +426,20 @@ func (m *Ack) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x9a
 	}
+	if len(m.Protocols) > 0 {
+		for iNdEx := len(m.Protocols) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Protocols[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintHandshake(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.Light {
 		i--
 		if m.Light {
@@ -367,6 +470,43 @@ func (m *Ack) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Protocol) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Protocol) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Protocol) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Version) > 0 {
+		i -= len(m.Version)
+		copy(dAtA[i:], m.Version)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Version)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *SynAck) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -387,6 +527,16 @@ func (m *SynAck) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ConnectionLimit {
+		i--
+		if m.ConnectionLimit {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
 	if m.Ack != nil {
 		{
 			size, err := m.Ack.MarshalToSizedBuffer(dAtA[:i])
@@ -479,6 +629,10 @@ func (m *Syn) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.Overlay)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	return n
 }
 
@@ -498,6 +652,12 @@ func (m *Ack) Size() (n int) {
 	if m.Light {
 		n += 2
 	}
+	if len(m.Protocols) > 0 {
+		for _, e := range m.Protocols {
+			l = e.Size()
+			n += 1 + l + sovHandshake(uint64(l))
+		}
+	}
 	l = len(m.WelcomeMessage)
 	if l > 0 {
 		n += 2 + l + sovHandshake(uint64(l))
@@ -505,6 +665,23 @@ func (m *Ack) Size() (n int) {
 	return n
 }
 
+func (m *Protocol) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
+	l = len(m.Version)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
+	return n
+}
+
 func (m *SynAck) Size() (n int) {
 	if m == nil {
 		return 0
@@ -519,6 +696,9 @@ func (m *SynAck) Size() (n int) {
 		l = m.Ack.Size()
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	if m.ConnectionLimit {
+		n += 2
+	}
 	return n
 }
 
@@ -612,6 +792,40 @@ func (m *Syn) Unmarshal(dAtA []byte) error {
 				m.ObservedUnderlay = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Overlay", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Overlay = append(m.Overlay[:0], dAtA[iNdEx:postIndex]...)
+			if m.Overlay == nil {
+				m.Overlay = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
@@ -740,6 +954,40 @@ func (m *Ack) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Light = bool(v != 0)
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocols", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocols = append(m.Protocols, &Protocol{})
+			if err := m.Protocols[len(m.Protocols)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 99:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field WelcomeMessage", wireType)
@@ -796,6 +1044,123 @@ func (m *Ack) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Protocol) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowHandshake
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Protocol: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Protocol: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipHandshake(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *SynAck) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -897,6 +1262,26 @@ func (m *SynAck) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectionLimit", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ConnectionLimit = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
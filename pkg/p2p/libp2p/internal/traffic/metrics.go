@@ -0,0 +1,57 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traffic
+
+import (
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	ReadBytes      *prometheus.CounterVec
+	WrittenBytes   *prometheus.CounterVec
+	StreamCount    *prometheus.CounterVec
+	StreamDuration *prometheus.HistogramVec
+}
+
+func newMetrics() metrics {
+	subsystem := "libp2p"
+
+	return metrics{
+		ReadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "stream_read_bytes",
+			Help:      "Number of bytes read from protocol streams.",
+		}, []string{"protocol", "stream"}),
+		WrittenBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "stream_written_bytes",
+			Help:      "Number of bytes written to protocol streams.",
+		}, []string{"protocol", "stream"}),
+		StreamCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "protocol_stream_count",
+			Help:      "Number of protocol streams by direction.",
+		}, []string{"protocol", "stream", "direction"}),
+		StreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "protocol_stream_duration_seconds",
+			Help:      "Duration of protocol streams.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		}, []string{"protocol", "stream"}),
+	}
+}
+
+// Metrics returns the prometheus collectors of the meter.
+func (mt *Meter) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(mt.metrics)
+}
@@ -0,0 +1,187 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package traffic meters the bytes transferred over protocol streams and the
+// number and the duration of the streams, per peer and per protocol stream.
+//
+// Prometheus metrics are labeled only by the protocol, the stream and the
+// direction, so that their cardinality is bounded by the number of registered
+// protocols. Per peer traffic is kept in memory while the peer is connected.
+package traffic
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Meter meters the traffic of protocol streams.
+type Meter struct {
+	peers   map[string]*peer // keyed by overlay address
+	mu      sync.Mutex
+	metrics metrics
+}
+
+type peer struct {
+	address swarm.Address
+	streams map[streamKey]*counters
+}
+
+type streamKey struct {
+	protocol string
+	stream   string
+}
+
+// counters are updated atomically, 64-bit fields are kept at the beginning of
+// the struct for alignment.
+type counters struct {
+	read     uint64
+	written  uint64
+	inbound  uint64
+	outbound uint64
+	duration int64 // total duration of the finished streams in nanoseconds
+}
+
+// NewMeter creates a new Meter.
+func NewMeter() *Meter {
+	return &Meter{
+		peers:   make(map[string]*peer),
+		metrics: newMetrics(),
+	}
+}
+
+// NewStream starts metering a new stream with the peer.
+func (mt *Meter) NewStream(overlay swarm.Address, protocol, stream string, direction p2p.Direction) *Stream {
+	key := streamKey{protocol: protocol, stream: stream}
+
+	mt.mu.Lock()
+	p, ok := mt.peers[overlay.ByteString()]
+	if !ok {
+		p = &peer{
+			address: overlay,
+			streams: make(map[streamKey]*counters),
+		}
+		mt.peers[overlay.ByteString()] = p
+	}
+	c, ok := p.streams[key]
+	if !ok {
+		c = new(counters)
+		p.streams[key] = c
+	}
+	mt.mu.Unlock()
+
+	if direction == p2p.DirectionInbound {
+		atomic.AddUint64(&c.inbound, 1)
+	} else {
+		atomic.AddUint64(&c.outbound, 1)
+	}
+	mt.metrics.StreamCount.WithLabelValues(protocol, stream, string(direction)).Inc()
+
+	return &Stream{
+		counters: c,
+		read:     mt.metrics.ReadBytes.WithLabelValues(protocol, stream),
+		written:  mt.metrics.WrittenBytes.WithLabelValues(protocol, stream),
+		duration: mt.metrics.StreamDuration.WithLabelValues(protocol, stream),
+		start:    time.Now(),
+	}
+}
+
+// RemovePeer discards the traffic of the peer.
+func (mt *Meter) RemovePeer(overlay swarm.Address) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+
+	delete(mt.peers, overlay.ByteString())
+}
+
+// TopPeers returns at most n peers with the most bytes transferred in both
+// directions. All peers are returned if n is negative.
+func (mt *Meter) TopPeers(n int) []p2p.PeerTraffic {
+	mt.mu.Lock()
+	peers := make([]p2p.PeerTraffic, 0, len(mt.peers))
+	for _, p := range mt.peers {
+		t := p2p.PeerTraffic{
+			Address:   p.address,
+			Protocols: make([]p2p.ProtocolTraffic, 0, len(p.streams)),
+		}
+		for k, c := range p.streams {
+			pt := p2p.ProtocolTraffic{
+				Protocol:        k.protocol,
+				Stream:          k.stream,
+				Read:            atomic.LoadUint64(&c.read),
+				Written:         atomic.LoadUint64(&c.written),
+				InboundStreams:  atomic.LoadUint64(&c.inbound),
+				OutboundStreams: atomic.LoadUint64(&c.outbound),
+				StreamDuration:  time.Duration(atomic.LoadInt64(&c.duration)),
+			}
+			t.Read += pt.Read
+			t.Written += pt.Written
+			t.Protocols = append(t.Protocols, pt)
+		}
+		sort.Slice(t.Protocols, func(i, j int) bool {
+			a, b := t.Protocols[i], t.Protocols[j]
+			if a.Protocol != b.Protocol {
+				return a.Protocol < b.Protocol
+			}
+			return a.Stream < b.Stream
+		})
+		peers = append(peers, t)
+	}
+	mt.mu.Unlock()
+
+	sort.Slice(peers, func(i, j int) bool {
+		ti, tj := peers[i].Read+peers[i].Written, peers[j].Read+peers[j].Written
+		if ti != tj {
+			return ti > tj
+		}
+		return bytes.Compare(peers[i].Address.Bytes(), peers[j].Address.Bytes()) < 0
+	})
+	if n >= 0 && len(peers) > n {
+		peers = peers[:n]
+	}
+	return peers
+}
+
+// Stream meters a single stream.
+type Stream struct {
+	counters *counters
+	read     prometheus.Counter
+	written  prometheus.Counter
+	duration prometheus.Observer
+	start    time.Time
+	once     sync.Once
+}
+
+// Read records bytes read from the stream.
+func (s *Stream) Read(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddUint64(&s.counters.read, uint64(n))
+	s.read.Add(float64(n))
+}
+
+// Written records bytes written to the stream.
+func (s *Stream) Written(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddUint64(&s.counters.written, uint64(n))
+	s.written.Add(float64(n))
+}
+
+// Done records the duration of the stream. Only the first call has effect.
+func (s *Stream) Done() {
+	s.once.Do(func() {
+		d := time.Since(s.start)
+		atomic.AddInt64(&s.counters.duration, int64(d))
+		s.duration.Observe(d.Seconds())
+	})
+}
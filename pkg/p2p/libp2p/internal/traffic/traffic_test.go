@@ -0,0 +1,76 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traffic_test
+
+import (
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/traffic"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestMeter(t *testing.T) {
+	var (
+		peer1 = swarm.MustParseHexAddress("01")
+		peer2 = swarm.MustParseHexAddress("02")
+		peer3 = swarm.MustParseHexAddress("03")
+	)
+
+	m := traffic.NewMeter()
+
+	s := m.NewStream(peer1, "pushsync", "pushsync", p2p.DirectionOutbound)
+	s.Written(100)
+	s.Read(10)
+	s.Done()
+	s.Done()
+
+	s = m.NewStream(peer1, "retrieval", "retrieval", p2p.DirectionInbound)
+	s.Read(50)
+	s.Written(4096)
+
+	s = m.NewStream(peer2, "pushsync", "pushsync", p2p.DirectionInbound)
+	s.Read(10)
+
+	s = m.NewStream(peer3, "pullsync", "pullsync", p2p.DirectionOutbound)
+	s.Read(1000)
+
+	peers := m.TopPeers(2)
+	if len(peers) != 2 {
+		t.Fatalf("got %v peers, want 2", len(peers))
+	}
+	if !peers[0].Address.Equal(peer1) || !peers[1].Address.Equal(peer3) {
+		t.Fatalf("got peers %s and %s, want %s and %s", peers[0].Address, peers[1].Address, peer1, peer3)
+	}
+	if peers[0].Read != 60 || peers[0].Written != 4196 {
+		t.Fatalf("got read %v and written %v bytes, want 60 and 4196", peers[0].Read, peers[0].Written)
+	}
+
+	want := []p2p.ProtocolTraffic{
+		{Protocol: "pushsync", Stream: "pushsync", Read: 10, Written: 100, OutboundStreams: 1},
+		{Protocol: "retrieval", Stream: "retrieval", Read: 50, Written: 4096, InboundStreams: 1},
+	}
+	got := peers[0].Protocols
+	if len(got) != len(want) {
+		t.Fatalf("got %v protocols, want %v", len(got), len(want))
+	}
+	if got[0].StreamDuration <= 0 {
+		t.Errorf("got stream duration %v of a finished stream", got[0].StreamDuration)
+	}
+	if got[1].StreamDuration != 0 {
+		t.Errorf("got stream duration %v of an open stream", got[1].StreamDuration)
+	}
+	for i := range got {
+		got[i].StreamDuration = 0
+		if got[i] != want[i] {
+			t.Errorf("got protocol traffic %+v, want %+v", got[i], want[i])
+		}
+	}
+
+	m.RemovePeer(peer1)
+	if peers := m.TopPeers(-1); len(peers) != 2 {
+		t.Fatalf("got %v peers after removal, want 2", len(peers))
+	}
+}
@@ -10,14 +10,21 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/bzz"
 	beecrypto "github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/bandwidth"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/blocklist"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/breaker"
 	handshake "github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/traffic"
+	"github.com/ethersphere/bee/pkg/reputation"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/tracing"
@@ -50,11 +57,21 @@ type Service struct {
 	libp2pPeerstore   peerstore.Peerstore
 	metrics           metrics
 	networkID         uint64
+	overlay           swarm.Address
 	handshakeService  *handshake.Service
 	addressbook       addressbook.Putter
 	peers             *peerRegistry
 	topologyNotifiers []topology.Notifier
 	connectionBreaker breaker.Interface
+	reputation        reputation.Recorder // peer behaviour recorder, may be nil
+	bandwidthLimiter  *bandwidth.Limiter
+	traffic           *traffic.Meter
+	blocklist         *blocklist.Blocklist
+	slots             slots
+	protocols         map[string][]string // supported versions by protocol name
+	protocolsMu       sync.RWMutex
+	depther           topology.NeighborhoodDepther
+	depthMu           sync.RWMutex
 	logger            logging.Logger
 	tracer            *tracing.Tracer
 }
@@ -66,9 +83,27 @@ type Options struct {
 	EnableQUIC     bool
 	LightNode      bool
 	WelcomeMessage string
+	Bandwidth      p2p.BandwidthLimits
+	// MaxInbound and MaxOutbound limit the number of connected peers in each
+	// direction. Zero value means that the number is not limited.
+	MaxInbound  int
+	MaxOutbound int
+	// ReservedSlots is the number of slots within each limit that only
+	// bootnodes, pinned peers and neighbourhood peers can use.
+	ReservedSlots int
+	// PinnedPeers are the overlay addresses of peers that can always use the
+	// reserved slots.
+	PinnedPeers []swarm.Address
 }
 
-func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay swarm.Address, addr string, ab addressbook.Putter, logger logging.Logger, tracer *tracing.Tracer, o Options) (*Service, error) {
+type slots struct {
+	maxInbound  int
+	maxOutbound int
+	reserved    int
+	pinned      []swarm.Address
+}
+
+func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay swarm.Address, addr string, ab addressbook.Putter, storer storage.StateStorer, logger logging.Logger, tracer *tracing.Tracer, o Options) (*Service, error) {
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, fmt.Errorf("address: %w", err)
@@ -183,7 +218,14 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		return nil, fmt.Errorf("handshake service: %w", err)
 	}
 
+	bandwidthLimiter, err := bandwidth.NewLimiter(o.Bandwidth)
+	if err != nil {
+		return nil, fmt.Errorf("bandwidth limiter: %w", err)
+	}
+
+	trafficMeter := traffic.NewMeter()
 	peerRegistry := newPeerRegistry()
+	peerRegistry.setRemovedFunc(trafficMeter.RemovePeer)
 	s := &Service{
 		ctx:               ctx,
 		host:              h,
@@ -192,12 +234,26 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		libp2pPeerstore:   libp2pPeerstore,
 		metrics:           newMetrics(),
 		networkID:         networkID,
+		overlay:           overlay,
 		peers:             peerRegistry,
 		addressbook:       ab,
 		logger:            logger,
 		tracer:            tracer,
 		connectionBreaker: breaker.NewBreaker(breaker.Options{}), // use default options
-	}
+		bandwidthLimiter:  bandwidthLimiter,
+		traffic:           trafficMeter,
+		blocklist:         blocklist.NewBlocklist(storer),
+		protocols:         make(map[string][]string),
+		slots: slots{
+			maxInbound:  o.MaxInbound,
+			maxOutbound: o.MaxOutbound,
+			reserved:    o.ReservedSlots,
+			pinned:      o.PinnedPeers,
+		},
+	}
+	handshakeService.SetPicker(func(overlay swarm.Address) bool {
+		return s.hasSlot(overlay, network.DirInbound, false)
+	})
 	// Construct protocols.
 	id := protocol.ID(p2p.NewSwarmStreamName(handshake.ProtocolName, handshake.ProtocolVersion, handshake.StreamName))
 	matcher, err := s.protocolSemverMatcher(id)
@@ -210,6 +266,12 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 		peerID := stream.Conn().RemotePeer()
 		handshakeStream := NewStream(stream)
 		i, err := s.handshakeService.Handle(handshakeStream, stream.Conn().RemoteMultiaddr(), peerID)
+		if errors.Is(err, p2p.ErrConnectionLimit) {
+			s.logger.Debugf("handshake: handle %s: inbound connection limit reached", peerID)
+			_ = handshakeStream.FullClose()
+			_ = s.disconnect(peerID)
+			return
+		}
 		if err != nil {
 			s.logger.Debugf("handshake: handle %s: %v", peerID, err)
 			s.logger.Errorf("unable to handshake with peer %v", peerID)
@@ -218,7 +280,31 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 			return
 		}
 
-		if exists := s.peers.addIfNotExists(stream.Conn(), i.BzzAddress.Overlay); exists {
+		blocked, err := s.blocklist.Exists(i.BzzAddress.Overlay)
+		if err != nil {
+			s.logger.Debugf("blocklisting: exists %s: %v", peerID, err)
+			s.logger.Errorf("internal error while connecting with peer %s", peerID)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+
+		if blocked {
+			s.logger.Debugf("handshake: blocklisted peer %s (%s) tried to connect", i.BzzAddress.Overlay, peerID)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+
+		exists, full := s.peers.addIfNotExists(stream.Conn(), i, s.slotLimit(i.BzzAddress.Overlay, network.DirInbound, false))
+		if full {
+			// the slot picked in the handshake was taken by a concurrent one
+			s.logger.Debugf("handshake: handle %s: inbound connection limit reached", peerID)
+			_ = handshakeStream.Reset()
+			_ = s.disconnect(peerID)
+			return
+		}
+		if exists {
 			if err = handshakeStream.FullClose(); err != nil {
 				s.logger.Debugf("handshake: could not close stream %s: %v", peerID, err)
 				s.logger.Errorf("unable to handshake with peer %v", peerID)
@@ -233,6 +319,14 @@ func New(ctx context.Context, signer beecrypto.Signer, networkID uint64, overlay
 			_ = s.disconnect(peerID)
 		}
 
+		// light nodes are served, but they are neither advertised nor used
+		// by the topology to forward or store chunks
+		if i.Light {
+			s.metrics.HandledStreamCount.Inc()
+			s.logger.Infof("successfully connected to light peer (inbound) %s", i.BzzAddress.ShortString())
+			return
+		}
+
 		err = s.addressbook.Put(i.BzzAddress.Overlay, *i.BzzAddress)
 		if err != nil {
 			s.logger.Debugf("handshake: addressbook put error %s: %v", peerID, err)
@@ -280,7 +374,8 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 				return
 			}
 
-			stream := newStream(streamlibp2p)
+			stream := newStream(s.ctx, streamlibp2p, s.bandwidthLimiter, p.Name, s.traffic.NewStream(overlay, p.Name, ss.Name, p2p.DirectionInbound))
+			defer stream.done()
 
 			// exchange headers
 			if err := handleHeaders(ss.Headler, stream); err != nil {
@@ -311,11 +406,26 @@ func (s *Service) AddProtocol(p p2p.ProtocolSpec) (err error) {
 					_ = s.Disconnect(overlay)
 				}
 
+				var bpe *p2p.BlockPeerError
+				if errors.As(err, &bpe) {
+					if err := s.Blocklist(overlay, bpe.Duration()); err != nil {
+						logger.Debugf("blocklist peer %s: %v", overlay, err)
+					}
+					logger.Debugf("blocklisted peer %s for %s", overlay, bpe.Duration())
+				}
+
 				logger.Debugf("error handle protocol %s/%s: stream %s: peer %s: error: %v", p.Name, p.Version, ss.Name, overlay, err)
 				return
 			}
 		})
 	}
+
+	s.protocolsMu.Lock()
+	s.protocols[p.Name] = append(s.protocols[p.Name], p.Version)
+	s.protocolsMu.Unlock()
+
+	s.handshakeService.AddProtocol(p.Name, p.Version)
+
 	return nil
 }
 
@@ -380,16 +490,24 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 		return nil, p2p.ErrAlreadyConnected
 	}
 
+	// the overlay is not known before the handshake, so only the full limit
+	// is checked, the reserved slots are checked after the handshake
+	if s.slots.maxOutbound > 0 && s.peers.count(network.DirOutbound) >= s.slots.maxOutbound {
+		return nil, p2p.ErrConnectionLimit
+	}
+
 	if err := s.connectionBreaker.Execute(func() error { return s.host.Connect(ctx, *info) }); err != nil {
 		if errors.Is(err, breaker.ErrClosed) {
 			return nil, p2p.NewConnectionBackoffError(err, s.connectionBreaker.ClosedUntil())
 		}
+		s.recordConnectionFailed(ctx)
 		return nil, err
 	}
 
 	stream, err := s.newStreamForPeerID(ctx, info.ID, handshake.ProtocolName, handshake.ProtocolVersion, handshake.StreamName)
 	if err != nil {
 		_ = s.disconnect(info.ID)
+		s.recordConnectionFailed(ctx)
 		return nil, fmt.Errorf("connect new stream: %w", err)
 	}
 
@@ -398,10 +516,36 @@ func (s *Service) Connect(ctx context.Context, addr ma.Multiaddr) (address *bzz.
 	if err != nil {
 		_ = handshakeStream.Reset()
 		_ = s.disconnect(info.ID)
+		s.recordConnectionFailed(ctx)
 		return nil, fmt.Errorf("handshake: %w", err)
 	}
 
-	if exists := s.peers.addIfNotExists(stream.Conn(), i.BzzAddress.Overlay); exists {
+	blocked, err := s.blocklist.Exists(i.BzzAddress.Overlay)
+	if err != nil {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, fmt.Errorf("blocklist: %w", err)
+	}
+
+	if blocked {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, p2p.ErrPeerBlocklisted
+	}
+
+	if i.Light {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, p2p.ErrLightPeer
+	}
+
+	exists, full := s.peers.addIfNotExists(stream.Conn(), i, s.slotLimit(i.BzzAddress.Overlay, network.DirOutbound, p2p.IsBootnode(ctx)))
+	if full {
+		_ = handshakeStream.Reset()
+		_ = s.disconnect(info.ID)
+		return nil, p2p.ErrConnectionLimit
+	}
+	if exists {
 		if err := handshakeStream.FullClose(); err != nil {
 			_ = s.disconnect(info.ID)
 			return nil, fmt.Errorf("peer exists, full close: %w", err)
@@ -435,6 +579,35 @@ func (s *Service) Disconnect(overlay swarm.Address) error {
 	return s.disconnect(peerID)
 }
 
+// Blocklist disconnects the peer and prevents it from connecting for the
+// duration. Zero duration blocks the peer permanently.
+func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration) error {
+	if err := s.blocklist.Add(overlay, duration); err != nil {
+		return fmt.Errorf("blocklist peer %s: %w", overlay, err)
+	}
+
+	if err := s.Disconnect(overlay); err != nil && !errors.Is(err, p2p.ErrPeerNotFound) {
+		return fmt.Errorf("disconnect blocklisted peer %s: %w", overlay, err)
+	}
+	return nil
+}
+
+// Blocklisted reports whether the peer is blocklisted.
+func (s *Service) Blocklisted(overlay swarm.Address) (bool, error) {
+	return s.blocklist.Exists(overlay)
+}
+
+// BlocklistedPeers returns peers that are currently on the blocklist.
+func (s *Service) BlocklistedPeers() ([]p2p.BlockedPeer, error) {
+	return s.blocklist.Peers()
+}
+
+// RemoveFromBlocklist allows the peer to connect again. It returns
+// p2p.ErrPeerNotFound if the peer is not on the blocklist.
+func (s *Service) RemoveFromBlocklist(overlay swarm.Address) error {
+	return s.blocklist.Remove(overlay)
+}
+
 func (s *Service) disconnect(peerID libp2ppeer.ID) error {
 	if err := s.host.Network().ClosePeer(peerID); err != nil {
 		return err
@@ -447,9 +620,108 @@ func (s *Service) Peers() []p2p.Peer {
 	return s.peers.peers()
 }
 
+// AddNotifier adds the topology notifier. If the notifier also reports the
+// neighbourhood depth, peers within the depth can use reserved slots.
 func (s *Service) AddNotifier(n topology.Notifier) {
 	s.topologyNotifiers = append(s.topologyNotifiers, n)
 	s.peers.addDisconnecter(n)
+
+	if d, ok := n.(topology.NeighborhoodDepther); ok {
+		s.depthMu.Lock()
+		s.depther = d
+		s.depthMu.Unlock()
+	}
+}
+
+// SetReputation sets the recorder of peer behaviour. Failed connections and
+// handshakes counted by the connection breaker are recorded for the peer
+// which overlay is set in the connection context. This call is not goroutine
+// safe.
+func (s *Service) SetReputation(r reputation.Recorder) {
+	s.reputation = r
+}
+
+// recordConnectionFailed records a failed connection for the peer which
+// overlay is set in the context.
+func (s *Service) recordConnectionFailed(ctx context.Context) {
+	if s.reputation == nil {
+		return
+	}
+	if overlay, ok := p2p.OverlayFromContext(ctx); ok {
+		s.reputation.Record(overlay, reputation.EventConnectionFailed)
+	}
+}
+
+// ConnectionSlots returns the connection limits and their usage.
+func (s *Service) ConnectionSlots() p2p.ConnectionSlots {
+	return p2p.ConnectionSlots{
+		InboundLimit:  s.slots.maxInbound,
+		Inbound:       s.peers.count(network.DirInbound),
+		OutboundLimit: s.slots.maxOutbound,
+		Outbound:      s.peers.count(network.DirOutbound),
+		Reserved:      s.slots.reserved,
+	}
+}
+
+// Traffic returns the connected peers with the most traffic over protocol
+// streams.
+func (s *Service) Traffic(limit int) []p2p.PeerTraffic {
+	return s.traffic.TopPeers(limit)
+}
+
+// PeerInfo returns the details of the connection to the peer.
+func (s *Service) PeerInfo(overlay swarm.Address) (*p2p.PeerInfo, error) {
+	info, found := s.peers.info(overlay)
+	if !found {
+		return nil, p2p.ErrPeerNotFound
+	}
+	return info, nil
+}
+
+// hasSlot reports whether there is a free connection slot in the direction
+// for the peer.
+func (s *Service) hasSlot(overlay swarm.Address, direction network.Direction, bootnode bool) bool {
+	limit := s.slotLimit(overlay, direction, bootnode)
+	return limit < 0 || s.peers.count(direction) < limit
+}
+
+// slotLimit returns the number of connections in the direction up to which
+// the peer can connect. Reserved slots are used only by bootnodes, pinned
+// peers and peers in the neighbourhood. Negative limit means no limit.
+func (s *Service) slotLimit(overlay swarm.Address, direction network.Direction, bootnode bool) int {
+	limit := s.slots.maxInbound
+	if direction == network.DirOutbound {
+		limit = s.slots.maxOutbound
+	}
+	if limit <= 0 {
+		return -1
+	}
+	if bootnode || s.reserved(overlay) {
+		return limit
+	}
+	if limit -= s.slots.reserved; limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// reserved reports whether the peer is allowed to use the reserved slots.
+func (s *Service) reserved(overlay swarm.Address) bool {
+	if overlay.IsZero() {
+		return false
+	}
+
+	for _, p := range s.slots.pinned {
+		if p.Equal(overlay) {
+			return true
+		}
+	}
+
+	s.depthMu.RLock()
+	d := s.depther
+	s.depthMu.RUnlock()
+
+	return d != nil && swarm.Proximity(s.overlay.Bytes(), overlay.Bytes()) >= d.NeighborhoodDepth()
 }
 
 func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers p2p.Headers, protocolName, protocolVersion, streamName string) (p2p.Stream, error) {
@@ -458,12 +730,14 @@ func (s *Service) NewStream(ctx context.Context, overlay swarm.Address, headers
 		return nil, p2p.ErrPeerNotFound
 	}
 
+	protocolVersion = s.negotiateVersion(peerID, protocolName, protocolVersion)
+
 	streamlibp2p, err := s.newStreamForPeerID(ctx, peerID, protocolName, protocolVersion, streamName)
 	if err != nil {
 		return nil, fmt.Errorf("new stream for peerid: %w", err)
 	}
 
-	stream := newStream(streamlibp2p)
+	stream := newStream(s.ctx, streamlibp2p, s.bandwidthLimiter, protocolName, s.traffic.NewStream(overlay, protocolName, streamName, p2p.DirectionOutbound))
 
 	// tracing: add span context header
 	if headers == nil {
@@ -515,3 +789,13 @@ func (s *Service) SetWelcomeMessage(val string) error {
 func (s *Service) GetWelcomeMessage() string {
 	return s.handshakeService.GetWelcomeMessage()
 }
+
+// SetBandwidthLimits changes the bandwidth limits of protocol streams.
+func (s *Service) SetBandwidthLimits(limits p2p.BandwidthLimits) error {
+	return s.bandwidthLimiter.SetLimits(limits)
+}
+
+// GetBandwidthLimits returns the bandwidth limits of protocol streams.
+func (s *Service) GetBandwidthLimits() p2p.BandwidthLimits {
+	return s.bandwidthLimiter.Limits()
+}
@@ -9,8 +9,10 @@ import (
 	"context"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/handshake"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/libp2p/go-libp2p-core/network"
@@ -22,10 +24,16 @@ type peerRegistry struct {
 	overlays    map[libp2ppeer.ID]swarm.Address             // map underlay peer id to overlay address
 	connections map[libp2ppeer.ID]map[network.Conn]struct{} // list of connections for safe removal on Disconnect notification
 	streams     map[libp2ppeer.ID]map[network.Stream]context.CancelFunc
+	light       map[libp2ppeer.ID]struct{} // light node peers are not reported to disconnecters
+	directions  map[libp2ppeer.ID]network.Direction
+	protocols   map[libp2ppeer.ID][]handshake.Protocol // protocol versions advertised in the handshake
+	welcomes    map[libp2ppeer.ID]string               // welcome messages received in the handshake
+	connected   map[libp2ppeer.ID]time.Time            // when the peers were added
 	mu          sync.RWMutex
 
 	//nolint:misspell
 	disconnecters    []topology.Disconnecter // peerRegistry notifies topology on peer disconnection
+	removed          func(swarm.Address)     // called when any peer, including light peers, is removed
 	network.Notifiee                         // peerRegistry can be the receiver for network.Notify
 }
 
@@ -35,6 +43,11 @@ func newPeerRegistry() *peerRegistry {
 		overlays:    make(map[libp2ppeer.ID]swarm.Address),
 		connections: make(map[libp2ppeer.ID]map[network.Conn]struct{}),
 		streams:     make(map[libp2ppeer.ID]map[network.Stream]context.CancelFunc),
+		light:       make(map[libp2ppeer.ID]struct{}),
+		directions:  make(map[libp2ppeer.ID]network.Direction),
+		protocols:   make(map[libp2ppeer.ID][]handshake.Protocol),
+		welcomes:    make(map[libp2ppeer.ID]string),
+		connected:   make(map[libp2ppeer.ID]time.Time),
 
 		Notifiee: new(network.NoopNotifiee),
 	}
@@ -74,10 +87,20 @@ func (r *peerRegistry) Disconnected(_ network.Network, c network.Conn) {
 		cancel()
 	}
 	delete(r.streams, peerID)
+	_, light := r.light[peerID]
+	delete(r.light, peerID)
+	delete(r.directions, peerID)
+	delete(r.protocols, peerID)
+	delete(r.welcomes, peerID)
+	delete(r.connected, peerID)
 
 	r.mu.Unlock()
 
-	if len(r.disconnecters) > 0 {
+	if r.removed != nil {
+		r.removed(overlay)
+	}
+
+	if len(r.disconnecters) > 0 && !light {
 		for _, d := range r.disconnecters {
 			d.Disconnected(overlay)
 		}
@@ -116,9 +139,11 @@ func (r *peerRegistry) removeStream(peerID libp2ppeer.ID, stream network.Stream)
 func (r *peerRegistry) peers() []p2p.Peer {
 	r.mu.RLock()
 	peers := make([]p2p.Peer, 0, len(r.overlays))
-	for _, a := range r.overlays {
+	for peerID, a := range r.overlays {
+		_, light := r.light[peerID]
 		peers = append(peers, p2p.Peer{
 			Address: a,
+			Light:   light,
 		})
 	}
 	r.mu.RUnlock()
@@ -128,11 +153,21 @@ func (r *peerRegistry) peers() []p2p.Peer {
 	return peers
 }
 
-func (r *peerRegistry) addIfNotExists(c network.Conn, overlay swarm.Address) (exists bool) {
+// addIfNotExists adds the peer if it is not already in the registry. A new
+// peer is not added if the limit of peers in the direction of the connection
+// is reached, so that the limit is checked and the peer is added atomically.
+// Negative limit disables the check.
+func (r *peerRegistry) addIfNotExists(c network.Conn, i *handshake.Info, limit int) (exists, full bool) {
 	peerID := c.RemotePeer()
+	overlay := i.BzzAddress.Overlay
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	_, exists = r.underlays[overlay.ByteString()]
+	if !exists && limit >= 0 && r.countLocked(c.Stat().Direction) >= limit {
+		return false, true
+	}
+
 	if _, ok := r.connections[peerID]; !ok {
 		r.connections[peerID] = make(map[network.Conn]struct{})
 	}
@@ -140,15 +175,25 @@ func (r *peerRegistry) addIfNotExists(c network.Conn, overlay swarm.Address) (ex
 	// this is solving a case of multiple underlying libp2p connections for the same peer
 	r.connections[peerID][c] = struct{}{}
 
-	if _, exists := r.underlays[overlay.ByteString()]; exists {
-		return true
+	if exists {
+		return true, false
 	}
 
 	r.streams[peerID] = make(map[network.Stream]context.CancelFunc)
 	r.underlays[overlay.ByteString()] = peerID
 	r.overlays[peerID] = overlay
-	return false
-
+	r.directions[peerID] = c.Stat().Direction
+	r.connected[peerID] = time.Now()
+	if len(i.Protocols) > 0 {
+		r.protocols[peerID] = i.Protocols
+	}
+	if i.WelcomeMessage != "" {
+		r.welcomes[peerID] = i.WelcomeMessage
+	}
+	if i.Light {
+		r.light[peerID] = struct{}{}
+	}
+	return false, false
 }
 
 func (r *peerRegistry) peerID(overlay swarm.Address) (peerID libp2ppeer.ID, found bool) {
@@ -165,9 +210,75 @@ func (r *peerRegistry) overlay(peerID libp2ppeer.ID) (swarm.Address, bool) {
 	return overlay, found
 }
 
+// protocolVersions returns the versions of the protocol that the peer
+// advertised in the handshake.
+func (r *peerRegistry) protocolVersions(peerID libp2ppeer.ID, name string) (versions []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.protocols[peerID] {
+		if p.Name == name {
+			versions = append(versions, p.Version)
+		}
+	}
+	return versions
+}
+
+// info returns the details of the connection to the peer.
+func (r *peerRegistry) info(overlay swarm.Address) (*p2p.PeerInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	peerID, found := r.underlays[overlay.ByteString()]
+	if !found {
+		return nil, false
+	}
+	_, light := r.light[peerID]
+	direction := p2p.DirectionOutbound
+	if r.directions[peerID] == network.DirInbound {
+		direction = p2p.DirectionInbound
+	}
+	var protocols []p2p.ProtocolVersion
+	for _, p := range r.protocols[peerID] {
+		protocols = append(protocols, p2p.ProtocolVersion{Name: p.Name, Version: p.Version})
+	}
+	return &p2p.PeerInfo{
+		Address:        overlay,
+		Direction:      direction,
+		ConnectedAt:    r.connected[peerID],
+		Light:          light,
+		WelcomeMessage: r.welcomes[peerID],
+		Protocols:      protocols,
+	}, true
+}
+
+// count returns the number of peers connected in the direction.
+func (r *peerRegistry) count(direction network.Direction) (n int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.countLocked(direction)
+}
+
+// countLocked returns the number of peers connected in the direction. It must
+// be called with the lock held.
+func (r *peerRegistry) countLocked(direction network.Direction) (n int) {
+	for _, d := range r.directions {
+		if d == direction {
+			n++
+		}
+	}
+	return n
+}
+
 func (r *peerRegistry) remove(peerID libp2ppeer.ID) {
 	r.mu.Lock()
 	overlay, found := r.overlays[peerID]
+	_, light := r.light[peerID]
+	delete(r.light, peerID)
+	delete(r.directions, peerID)
+	delete(r.protocols, peerID)
+	delete(r.welcomes, peerID)
+	delete(r.connected, peerID)
 	delete(r.overlays, peerID)
 	delete(r.underlays, overlay.ByteString())
 	delete(r.connections, peerID)
@@ -177,14 +288,23 @@ func (r *peerRegistry) remove(peerID libp2ppeer.ID) {
 	delete(r.streams, peerID)
 	r.mu.Unlock()
 
+	if r.removed != nil && found {
+		r.removed(overlay)
+	}
+
 	// if overlay was not found disconnect handler should not be signaled.
-	if len(r.disconnecters) > 0 && found {
+	if len(r.disconnecters) > 0 && found && !light {
 		for _, d := range r.disconnecters {
 			d.Disconnected(overlay)
 		}
 	}
 }
 
+// setRemovedFunc sets the function that is called when a peer is removed.
+func (r *peerRegistry) setRemovedFunc(f func(swarm.Address)) {
+	r.removed = f
+}
+
 func (r *peerRegistry) addDisconnecter(d topology.Disconnecter) {
 	r.disconnecters = append(r.disconnecters, d)
 }
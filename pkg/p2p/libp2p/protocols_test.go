@@ -7,8 +7,10 @@ package libp2p_test
 import (
 	"context"
 	"errors"
+	"io"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/multiformats/go-multistream"
@@ -43,6 +45,69 @@ func TestNewStream(t *testing.T) {
 	}
 }
 
+func TestNewStream_traffic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{})
+
+	s2, overlay2 := newService(t, 1, libp2pServiceOpts{})
+
+	data := []byte("traffic")
+	received := make(chan struct{})
+	if err := s1.AddProtocol(newTestProtocol(func(_ context.Context, _ p2p.Peer, s p2p.Stream) error {
+		defer close(received)
+		defer s.Close()
+		_, err := io.ReadFull(s, make([]byte, len(data)))
+		return err
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := serviceUnderlayAddress(t, s1)
+
+	if _, err := s2.Connect(ctx, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, testProtocolVersion, testStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the stream to be handled")
+	}
+
+	// headers are exchanged over the stream as well
+	peers := s2.Traffic(10)
+	if len(peers) != 1 || !peers[0].Address.Equal(overlay1) {
+		t.Fatalf("got traffic of peers %v, want %s", peers, overlay1)
+	}
+	if peers[0].Written < uint64(len(data)) {
+		t.Fatalf("got %v bytes written, want at least %v", peers[0].Written, len(data))
+	}
+	if p := peers[0].Protocols; len(p) != 1 || p[0].OutboundStreams != 1 {
+		t.Fatalf("got protocol traffic %+v, want one outbound stream", p)
+	}
+
+	peers = s1.Traffic(10)
+	if len(peers) != 1 || !peers[0].Address.Equal(overlay2) {
+		t.Fatalf("got traffic of peers %v, want %s", peers, overlay2)
+	}
+	if peers[0].Read < uint64(len(data)) {
+		t.Fatalf("got %v bytes read, want at least %v", peers[0].Read, len(data))
+	}
+}
+
 // TestNewStreamMulti is a regression test to see that we trigger
 // the right handler when multiple streams are registered under
 // a single protocol.
@@ -185,6 +250,121 @@ func TestNewStream_semanticVersioning(t *testing.T) {
 	}
 }
 
+func TestNewStream_versionNegotiation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{})
+	s2, _ := newService(t, 1, libp2pServiceOpts{})
+
+	handled := make(chan string, 1)
+	handler := func(_ context.Context, _ p2p.Peer, stream p2p.Stream) error {
+		handled <- stream.ProtocolVersion()
+		return nil
+	}
+	newVersionedProtocol := func(version string) p2p.ProtocolSpec {
+		p := newTestProtocol(handler)
+		p.Version = version
+		return p
+	}
+
+	// s1 serves two versions of the protocol at once
+	for _, v := range []string{"1.0.0", "2.3.4"} {
+		if err := s1.AddProtocol(newVersionedProtocol(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, v := range []string{"2.3.4", "3.0.0"} {
+		if err := s2.AddProtocol(newVersionedProtocol(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s2.Connect(ctx, serviceUnderlayAddress(t, s1)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		requested string
+		want      string
+	}{
+		{requested: "3.0.0", want: "2.3.4"},
+		{requested: "2.3.4", want: "2.3.4"},
+		{requested: "1.0.0", want: "1.0.0"},
+	} {
+		stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, tc.requested, testStreamName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := stream.ProtocolVersion(); v != tc.want {
+			t.Fatalf("requested %s: got stream version %s, want %s", tc.requested, v, tc.want)
+		}
+		select {
+		case v := <-handled:
+			if v != tc.want {
+				t.Fatalf("requested %s: got handled version %s, want %s", tc.requested, v, tc.want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the handler")
+		}
+		_ = stream.FullClose()
+	}
+}
+
+// TestNewStream_exactVersion tests that a stream is handled by the exact
+// negotiated version when a node serves several compatible versions of the
+// protocol.
+func TestNewStream_exactVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s1, overlay1 := newService(t, 1, libp2pServiceOpts{})
+	s2, _ := newService(t, 1, libp2pServiceOpts{})
+
+	handled := make(chan string, 1)
+	newVersionedProtocol := func(version string) p2p.ProtocolSpec {
+		p := newTestProtocol(func(_ context.Context, _ p2p.Peer, _ p2p.Stream) error {
+			handled <- version
+			return nil
+		})
+		p.Version = version
+		return p
+	}
+
+	// the higher version is registered first, so that its handler would
+	// also match the lower version
+	for _, v := range []string{"1.1.0", "1.0.0"} {
+		if err := s1.AddProtocol(newVersionedProtocol(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		if err := s2.AddProtocol(newVersionedProtocol(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s2.Connect(ctx, serviceUnderlayAddress(t, s1)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		stream, err := s2.NewStream(ctx, overlay1, nil, testProtocolName, version, testStreamName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case v := <-handled:
+			if v != version {
+				t.Fatalf("requested %s: got handled version %s", version, v)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the handler")
+		}
+		_ = stream.FullClose()
+	}
+}
+
 func TestDisconnectError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
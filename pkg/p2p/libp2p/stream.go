@@ -5,7 +5,14 @@
 package libp2p
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/bandwidth"
+	"github.com/ethersphere/bee/pkg/p2p/libp2p/internal/traffic"
 	"github.com/libp2p/go-libp2p-core/helpers"
 	"github.com/libp2p/go-libp2p-core/network"
 )
@@ -15,19 +22,163 @@ var _ p2p.Stream = (*stream)(nil)
 type stream struct {
 	network.Stream
 	headers map[string][]byte
+
+	// bandwidth limiting, disabled if the limiter is nil
+	ctx      context.Context
+	limiter  *bandwidth.Limiter
+	protocol string
+
+	// deadlines that bound waiting for bandwidth
+	readDeadline  time.Time
+	writeDeadline time.Time
+	deadlineMu    sync.Mutex
+
+	// traffic metering, disabled if the meter is nil
+	meter *traffic.Stream
 }
 
 func NewStream(s network.Stream) p2p.Stream {
 	return &stream{Stream: s}
 }
 
-func newStream(s network.Stream) *stream {
-	return &stream{Stream: s}
+func newStream(ctx context.Context, s network.Stream, limiter *bandwidth.Limiter, protocol string, meter *traffic.Stream) *stream {
+	return &stream{
+		Stream:   s,
+		ctx:      ctx,
+		limiter:  limiter,
+		protocol: protocol,
+		meter:    meter,
+	}
 }
+
 func (s *stream) Headers() p2p.Headers {
 	return s.headers
 }
 
+// ProtocolVersion returns the version part of the negotiated protocol ID.
+func (s *stream) ProtocolVersion() string {
+	parts := strings.Split(string(s.Protocol()), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
+
 func (s *stream) FullClose() error {
 	return helpers.FullClose(s)
 }
+
+func (s *stream) Close() error {
+	s.done()
+	return s.Stream.Close()
+}
+
+func (s *stream) Reset() error {
+	s.done()
+	return s.Stream.Reset()
+}
+
+// done ends the stream metering.
+func (s *stream) done() {
+	if s.meter != nil {
+		s.meter.Done()
+	}
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	n, err := s.read(p)
+	if s.meter != nil {
+		s.meter.Read(n)
+	}
+	return n, err
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	n, err := s.write(p)
+	if s.meter != nil {
+		s.meter.Written(n)
+	}
+	return n, err
+}
+
+func (s *stream) SetDeadline(t time.Time) error {
+	s.deadlineMu.Lock()
+	s.readDeadline = t
+	s.writeDeadline = t
+	s.deadlineMu.Unlock()
+	return s.Stream.SetDeadline(t)
+}
+
+func (s *stream) SetReadDeadline(t time.Time) error {
+	s.deadlineMu.Lock()
+	s.readDeadline = t
+	s.deadlineMu.Unlock()
+	return s.Stream.SetReadDeadline(t)
+}
+
+func (s *stream) SetWriteDeadline(t time.Time) error {
+	s.deadlineMu.Lock()
+	s.writeDeadline = t
+	s.deadlineMu.Unlock()
+	return s.Stream.SetWriteDeadline(t)
+}
+
+// read reads from the stream and then waits for the download bandwidth limit
+// to allow the read bytes. Bandwidth is charged after the read, so that idle
+// streams which wait for data do not hold the bandwidth of others.
+func (s *stream) read(p []byte) (int, error) {
+	if s.limiter == nil {
+		return s.Stream.Read(p)
+	}
+
+	n, err := s.Stream.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	s.deadlineMu.Lock()
+	deadline := s.readDeadline
+	s.deadlineMu.Unlock()
+	ctx, cancel := deadlineContext(s.ctx, deadline)
+	defer cancel()
+
+	if werr := s.limiter.ChargeDownload(ctx, s.protocol, n); werr != nil && err == nil {
+		err = werr
+	}
+	return n, err
+}
+
+// write writes to the stream in parts that the upload bandwidth limit allows.
+func (s *stream) write(p []byte) (n int, err error) {
+	if s.limiter == nil {
+		return s.Stream.Write(p)
+	}
+
+	s.deadlineMu.Lock()
+	deadline := s.writeDeadline
+	s.deadlineMu.Unlock()
+	ctx, cancel := deadlineContext(s.ctx, deadline)
+	defer cancel()
+
+	for n < len(p) {
+		allowed, err := s.limiter.WaitUpload(ctx, s.protocol, len(p)-n)
+		if err != nil {
+			return n, err
+		}
+		w, err := s.Stream.Write(p[n : n+allowed])
+		n += w
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// deadlineContext returns the context that is cancelled on the stream
+// deadline, if it is set.
+func deadlineContext(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
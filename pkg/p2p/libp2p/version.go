@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/coreos/go-semver/semver"
+	libp2ppeer "github.com/libp2p/go-libp2p-core/peer"
 	protocol "github.com/libp2p/go-libp2p-core/protocol"
 )
 
@@ -18,17 +19,20 @@ import (
 // The matcher function will return a boolean indicating whether a protocol ID
 // matches the base protocol. A given protocol ID matches the base protocol if
 // the IDs are the same and if the semantic version of the base protocol is the
-// same or higher than that of the protocol ID provided.
+// same or higher than that of the protocol ID provided. A protocol ID with a
+// different version is not matched if the exact version of the protocol is
+// registered, so that the stream is handled by the negotiated version.
 func (s *Service) protocolSemverMatcher(base protocol.ID) (func(string) bool, error) {
 	parts := strings.Split(string(base), "/")
 	partsLen := len(parts)
-	if partsLen < 2 {
+	if partsLen < 3 {
 		return nil, errors.New("invalid protocol id")
 	}
 	vers, err := semver.NewVersion(parts[partsLen-2])
 	if err != nil {
 		return nil, err
 	}
+	name := parts[partsLen-3]
 
 	return func(check string) bool {
 		chparts := strings.Split(check, "/")
@@ -52,6 +56,82 @@ func (s *Service) protocolSemverMatcher(base protocol.ID) (func(string) bool, er
 			return false
 		}
 
-		return vers.Major == chvers.Major && vers.Minor >= chvers.Minor
+		if vers.Equal(*chvers) {
+			return true
+		}
+		if vers.Major != chvers.Major || vers.Minor < chvers.Minor {
+			return false
+		}
+		return !s.hasProtocolVersion(name, chvers)
 	}, nil
 }
+
+// hasProtocolVersion returns true if the exact version of the protocol is
+// registered.
+func (s *Service) hasProtocolVersion(name string, v *semver.Version) bool {
+	s.protocolsMu.RLock()
+	defer s.protocolsMu.RUnlock()
+
+	for _, version := range s.protocols[name] {
+		pv, err := semver.NewVersion(version)
+		if err == nil && pv.Equal(*v) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateVersion returns the highest version of the protocol that is
+// supported by both this node and the peer, and is not higher than the
+// requested version. The peer supports a version if it advertised a version
+// that matches it in the same way as protocolSemverMatcher does. If there is no
+// common version, the requested version is returned, as the peer may have
+// registered the protocol after the handshake, and the stream negotiation
+// decides.
+func (s *Service) negotiateVersion(peerID libp2ppeer.ID, name, version string) string {
+	peerVersions := s.peers.protocolVersions(peerID, name)
+	if len(peerVersions) == 0 {
+		return version
+	}
+
+	requested, err := semver.NewVersion(version)
+	if err != nil {
+		return version
+	}
+
+	s.protocolsMu.RLock()
+	candidates := append([]string{version}, s.protocols[name]...)
+	s.protocolsMu.RUnlock()
+
+	negotiated := version
+	var best *semver.Version
+	for _, c := range candidates {
+		cv, err := semver.NewVersion(c)
+		if err != nil || requested.LessThan(*cv) {
+			continue
+		}
+		if best != nil && !best.LessThan(*cv) {
+			continue
+		}
+		if supportsVersion(peerVersions, cv) {
+			best = cv
+			negotiated = c
+		}
+	}
+	return negotiated
+}
+
+// supportsVersion returns true if any of the versions can handle the streams
+// of the protocol version v.
+func supportsVersion(versions []string, v *semver.Version) bool {
+	for _, version := range versions {
+		pv, err := semver.NewVersion(version)
+		if err != nil {
+			continue
+		}
+		if pv.Major == v.Major && pv.Minor >= v.Minor {
+			return true
+		}
+	}
+	return false
+}
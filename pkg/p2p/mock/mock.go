@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/bzz"
 	"github.com/ethersphere/bee/pkg/p2p"
@@ -27,6 +28,15 @@ type Service struct {
 	setWelcomeMessageFunc func(string) error
 	getWelcomeMessageFunc func() string
 	welcomeMessage        string
+	setBandwidthFunc      func(p2p.BandwidthLimits) error
+	blocklistFunc         func(swarm.Address, time.Duration) error
+	blocklistedPeersFunc  func() ([]p2p.BlockedPeer, error)
+	blocklistedFunc       func(swarm.Address) (bool, error)
+	removeBlocklistFunc   func(swarm.Address) error
+	bandwidthLimits       p2p.BandwidthLimits
+	connectionSlots       p2p.ConnectionSlots
+	peerInfoFunc          func(swarm.Address) (*p2p.PeerInfo, error)
+	trafficFunc           func(int) []p2p.PeerTraffic
 	notifyCalled          int32
 }
 
@@ -86,6 +96,69 @@ func WithSetWelcomeMessageFunc(f func(string) error) Option {
 	})
 }
 
+// WithSetBandwidthLimitsFunc sets the mock implementation of the SetBandwidthLimits function
+func WithSetBandwidthLimitsFunc(f func(p2p.BandwidthLimits) error) Option {
+	return optionFunc(func(s *Service) {
+		s.setBandwidthFunc = f
+	})
+}
+
+// WithBandwidthLimits sets the bandwidth limits returned by the GetBandwidthLimits function
+func WithBandwidthLimits(limits p2p.BandwidthLimits) Option {
+	return optionFunc(func(s *Service) {
+		s.bandwidthLimits = limits
+	})
+}
+
+// WithBlocklistFunc sets the mock implementation of the Blocklist function
+func WithBlocklistFunc(f func(swarm.Address, time.Duration) error) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistFunc = f
+	})
+}
+
+// WithBlocklistedPeersFunc sets the mock implementation of the BlocklistedPeers function
+func WithBlocklistedPeersFunc(f func() ([]p2p.BlockedPeer, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistedPeersFunc = f
+	})
+}
+
+// WithBlocklistedFunc sets the mock implementation of the Blocklisted function
+func WithBlocklistedFunc(f func(swarm.Address) (bool, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.blocklistedFunc = f
+	})
+}
+
+// WithRemoveFromBlocklistFunc sets the mock implementation of the RemoveFromBlocklist function
+func WithRemoveFromBlocklistFunc(f func(swarm.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.removeBlocklistFunc = f
+	})
+}
+
+// WithConnectionSlots sets the connection slots returned by the ConnectionSlots function
+func WithConnectionSlots(slots p2p.ConnectionSlots) Option {
+	return optionFunc(func(s *Service) {
+		s.connectionSlots = slots
+	})
+}
+
+// WithPeerInfoFunc sets the mock implementation of the PeerInfo function
+func WithPeerInfoFunc(f func(swarm.Address) (*p2p.PeerInfo, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.peerInfoFunc = f
+	})
+}
+
+// WithTrafficFunc sets the mock implementation of the Traffic function
+func WithTrafficFunc(f func(int) []p2p.PeerTraffic) Option {
+	return optionFunc(func(s *Service) {
+		s.trafficFunc = f
+	})
+}
+
 // New will create a new mock P2P Service with the given options
 func New(opts ...Option) *Service {
 	s := new(Service)
@@ -166,6 +239,66 @@ func (s *Service) GetWelcomeMessage() string {
 	return s.welcomeMessage
 }
 
+func (s *Service) SetBandwidthLimits(limits p2p.BandwidthLimits) error {
+	if s.setBandwidthFunc != nil {
+		if err := s.setBandwidthFunc(limits); err != nil {
+			return err
+		}
+	}
+	s.bandwidthLimits = limits
+	return nil
+}
+
+func (s *Service) GetBandwidthLimits() p2p.BandwidthLimits {
+	return s.bandwidthLimits
+}
+
+func (s *Service) Blocklist(overlay swarm.Address, duration time.Duration) error {
+	if s.blocklistFunc == nil {
+		return errors.New("function Blocklist not configured")
+	}
+	return s.blocklistFunc(overlay, duration)
+}
+
+func (s *Service) BlocklistedPeers() ([]p2p.BlockedPeer, error) {
+	if s.blocklistedPeersFunc == nil {
+		return nil, nil
+	}
+	return s.blocklistedPeersFunc()
+}
+
+func (s *Service) Blocklisted(overlay swarm.Address) (bool, error) {
+	if s.blocklistedFunc == nil {
+		return false, nil
+	}
+	return s.blocklistedFunc(overlay)
+}
+
+func (s *Service) RemoveFromBlocklist(overlay swarm.Address) error {
+	if s.removeBlocklistFunc == nil {
+		return errors.New("function RemoveFromBlocklist not configured")
+	}
+	return s.removeBlocklistFunc(overlay)
+}
+
+func (s *Service) ConnectionSlots() p2p.ConnectionSlots {
+	return s.connectionSlots
+}
+
+func (s *Service) PeerInfo(overlay swarm.Address) (*p2p.PeerInfo, error) {
+	if s.peerInfoFunc == nil {
+		return nil, errors.New("function PeerInfo not configured")
+	}
+	return s.peerInfoFunc(overlay)
+}
+
+func (s *Service) Traffic(limit int) []p2p.PeerTraffic {
+	if s.trafficFunc == nil {
+		return nil
+	}
+	return s.trafficFunc(limit)
+}
+
 type Option interface {
 	apply(*Service)
 }
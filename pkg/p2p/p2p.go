@@ -7,6 +7,7 @@ package p2p
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/bzz"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -26,6 +27,11 @@ type Service interface {
 	Peers() []Peer
 	AddNotifier(topology.Notifier)
 	Addresses() ([]ma.Multiaddr, error)
+	// Blocklist disconnects the peer and prevents it from connecting for
+	// the duration. Zero duration blocks the peer permanently.
+	Blocklist(overlay swarm.Address, duration time.Duration) error
+	// Blocklisted reports whether the peer is blocklisted.
+	Blocklisted(overlay swarm.Address) (bool, error)
 }
 
 // DebugService extends the Service with method used for debugging.
@@ -33,6 +39,94 @@ type DebugService interface {
 	Service
 	SetWelcomeMessage(val string) error
 	GetWelcomeMessage() string
+	SetBandwidthLimits(limits BandwidthLimits) error
+	GetBandwidthLimits() BandwidthLimits
+	BlocklistedPeers() ([]BlockedPeer, error)
+	RemoveFromBlocklist(overlay swarm.Address) error
+	ConnectionSlots() ConnectionSlots
+	// PeerInfo returns the details of the connection to the peer, or
+	// ErrPeerNotFound if the peer is not connected.
+	PeerInfo(overlay swarm.Address) (*PeerInfo, error)
+	// Traffic returns at most limit connected peers with the most bytes
+	// transferred over protocol streams, in descending order.
+	Traffic(limit int) []PeerTraffic
+}
+
+// PeerTraffic holds the amount of data transferred with a peer.
+type PeerTraffic struct {
+	Address   swarm.Address
+	Read      uint64 // bytes read from the peer
+	Written   uint64 // bytes written to the peer
+	Protocols []ProtocolTraffic
+}
+
+// ProtocolTraffic holds the amount of data transferred and the number of
+// streams of a protocol stream with a peer.
+type ProtocolTraffic struct {
+	Protocol        string
+	Stream          string
+	Read            uint64
+	Written         uint64
+	InboundStreams  uint64
+	OutboundStreams uint64
+	StreamDuration  time.Duration // total duration of the finished streams
+}
+
+// PeerInfo holds the details of a connection to a peer.
+type PeerInfo struct {
+	Address        swarm.Address
+	Direction      Direction
+	ConnectedAt    time.Time
+	Light          bool
+	WelcomeMessage string // welcome message received in the handshake
+	// Protocols are the protocol versions that the peer supports.
+	Protocols []ProtocolVersion
+}
+
+// Direction is the direction in which a connection was established.
+type Direction string
+
+const (
+	DirectionInbound  Direction = "inbound"
+	DirectionOutbound Direction = "outbound"
+)
+
+// ProtocolVersion is a name and a version of a protocol.
+type ProtocolVersion struct {
+	Name    string
+	Version string
+}
+
+// ConnectionSlots holds the connection limits and the number of connected
+// peers in each direction. Zero limit means that the number of connections is
+// not limited.
+type ConnectionSlots struct {
+	InboundLimit  int
+	Inbound       int
+	OutboundLimit int
+	Outbound      int
+	// Reserved is the number of slots within each limit that are available
+	// only to bootnodes, pinned peers and neighbourhood peers.
+	Reserved int
+}
+
+// BlockedPeer holds information about a blocklisted peer.
+type BlockedPeer struct {
+	Address   swarm.Address
+	Timestamp time.Time     // when the peer was blocked
+	Duration  time.Duration // zero means permanent
+}
+
+// BandwidthLimits holds node-wide bandwidth limits of protocol streams.
+type BandwidthLimits struct {
+	// Upload and Download are limits in bytes per second. Zero value means
+	// that the bandwidth is not limited.
+	Upload   uint64
+	Download uint64
+	// Weights are fractions of the bandwidth, in the (0, 1] range, that
+	// protocols are allowed to use when there is other traffic, keyed by
+	// protocol names. Protocols without a weight have the weight of 1.
+	Weights map[string]float64
 }
 
 // Streamer is able to create a new Stream.
@@ -45,6 +139,9 @@ type Stream interface {
 	io.ReadWriter
 	io.Closer
 	Headers() Headers
+	// ProtocolVersion returns the protocol version that the stream was
+	// opened with.
+	ProtocolVersion() string
 	FullClose() error
 	Reset() error
 }
@@ -66,6 +163,9 @@ type StreamSpec struct {
 // Peer holds information about a Peer.
 type Peer struct {
 	Address swarm.Address `json:"address"`
+	// Light is true if the peer is a light node that does not store chunks
+	// for the network.
+	Light bool `json:"light"`
 }
 
 // HandlerFunc handles a received Stream from a Peer.
@@ -86,6 +186,36 @@ const (
 	HeaderNameTracingSpanContext = "tracing-span-context"
 )
 
+type bootnodeContextKey struct{}
+
+// WithBootnode marks the context of a connection to a bootnode, which is
+// allowed to use the reserved connection slots.
+func WithBootnode(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bootnodeContextKey{}, true)
+}
+
+// IsBootnode reports whether the context is of a connection to a bootnode.
+func IsBootnode(ctx context.Context) bool {
+	v, _ := ctx.Value(bootnodeContextKey{}).(bool)
+	return v
+}
+
+type overlayContextKey struct{}
+
+// WithOverlay sets the expected overlay address of the peer in the context of
+// a connection, so that connection failures can be attributed to the peer
+// before its overlay is known from the handshake.
+func WithOverlay(ctx context.Context, overlay swarm.Address) context.Context {
+	return context.WithValue(ctx, overlayContextKey{}, overlay)
+}
+
+// OverlayFromContext returns the expected overlay address of the peer from
+// the context of a connection, if it is set.
+func OverlayFromContext(ctx context.Context) (swarm.Address, bool) {
+	v, ok := ctx.Value(overlayContextKey{}).(swarm.Address)
+	return v, ok
+}
+
 // NewSwarmStreamName constructs a libp2p compatible stream name out of
 // protocol name and version and stream name.
 func NewSwarmStreamName(protocol, version, stream string) string {
@@ -5,9 +5,11 @@
 package p2p_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 func TestNewSwarmStreamName(t *testing.T) {
@@ -18,3 +20,15 @@ func TestNewSwarmStreamName(t *testing.T) {
 		t.Errorf("got %s, want %s", got, want)
 	}
 }
+
+func TestOverlayContext(t *testing.T) {
+	if _, ok := p2p.OverlayFromContext(context.Background()); ok {
+		t.Fatal("got overlay from an empty context")
+	}
+
+	want := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	got, ok := p2p.OverlayFromContext(p2p.WithOverlay(context.Background(), want))
+	if !ok || !got.Equal(want) {
+		t.Errorf("got overlay %s, want %s", got, want)
+	}
+}
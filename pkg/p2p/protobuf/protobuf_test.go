@@ -379,6 +379,10 @@ func (noopWriteCloser) Headers() p2p.Headers {
 	return nil
 }
 
+func (noopWriteCloser) ProtocolVersion() string {
+	return ""
+}
+
 func (noopWriteCloser) Close() error {
 	return nil
 }
@@ -407,6 +411,10 @@ func (noopReadCloser) Headers() p2p.Headers {
 	return nil
 }
 
+func (noopReadCloser) ProtocolVersion() string {
+	return ""
+}
+
 func (noopReadCloser) Close() error {
 	return nil
 }
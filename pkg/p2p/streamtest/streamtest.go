@@ -68,8 +68,8 @@ func (r *Recorder) SetProtocols(protocols ...p2p.ProtocolSpec) {
 func (r *Recorder) NewStream(ctx context.Context, addr swarm.Address, h p2p.Headers, protocolName, protocolVersion, streamName string) (p2p.Stream, error) {
 	recordIn := newRecord()
 	recordOut := newRecord()
-	streamOut := newStream(recordIn, recordOut)
-	streamIn := newStream(recordOut, recordIn)
+	streamOut := newStream(recordIn, recordOut, protocolVersion)
+	streamIn := newStream(recordOut, recordIn, protocolVersion)
 
 	var handler p2p.HandlerFunc
 	var headler p2p.HeadlerFunc
@@ -188,10 +188,11 @@ type stream struct {
 	in      *record
 	out     *record
 	headers p2p.Headers
+	version string
 }
 
-func newStream(in, out *record) *stream {
-	return &stream{in: in, out: out}
+func newStream(in, out *record, version string) *stream {
+	return &stream{in: in, out: out, version: version}
 }
 
 func (s *stream) Read(p []byte) (int, error) {
@@ -206,6 +207,10 @@ func (s *stream) Headers() p2p.Headers {
 	return s.headers
 }
 
+func (s *stream) ProtocolVersion() string {
+	return s.version
+}
+
 func (s *stream) Close() error {
 	return s.in.Close()
 }
@@ -17,6 +17,7 @@ type metrics struct {
 	PongSentCount     prometheus.Counter
 	PingReceivedCount prometheus.Counter
 	PongReceivedCount prometheus.Counter
+	RTT               prometheus.Histogram
 }
 
 func newMetrics() metrics {
@@ -47,6 +48,13 @@ func newMetrics() metrics {
 			Name:      "pong_received_count",
 			Help:      "Number of pong responses received.",
 		}),
+		RTT: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "rtt_seconds",
+			Help:      "Round trip time to peers per ping message.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.5, 1, 2, 5},
+		}),
 	}
 }
 
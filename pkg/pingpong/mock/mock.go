@@ -6,19 +6,41 @@ package mock
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 type Service struct {
-	pingFunc func(ctx context.Context, address swarm.Address, msgs ...string) (rtt time.Duration, err error)
+	pingFunc    func(ctx context.Context, address swarm.Address, msgs ...string) (rtt time.Duration, err error)
+	latencies   map[string]time.Duration
+	latenciesMu sync.Mutex
 }
 
 func New(pingFunc func(ctx context.Context, address swarm.Address, msgs ...string) (rtt time.Duration, err error)) *Service {
-	return &Service{pingFunc: pingFunc}
+	return &Service{
+		pingFunc:  pingFunc,
+		latencies: make(map[string]time.Duration),
+	}
 }
 
 func (s *Service) Ping(ctx context.Context, address swarm.Address, msgs ...string) (rtt time.Duration, err error) {
 	return s.pingFunc(ctx, address, msgs...)
 }
+
+func (s *Service) Latency(address swarm.Address) (time.Duration, bool) {
+	s.latenciesMu.Lock()
+	defer s.latenciesMu.Unlock()
+
+	l, ok := s.latencies[address.ByteString()]
+	return l, ok
+}
+
+// SetLatency sets the latency that is reported for the peer.
+func (s *Service) SetLatency(address swarm.Address, latency time.Duration) {
+	s.latenciesMu.Lock()
+	defer s.latenciesMu.Unlock()
+
+	s.latencies[address.ByteString()] = latency
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
@@ -15,6 +16,7 @@ import (
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/pingpong/pb"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/tracing"
 )
 
@@ -24,23 +26,46 @@ const (
 	streamName      = "pingpong"
 )
 
+const (
+	pingTimeout = 10 * time.Second
+	// latencyWeight is the weight of a new round trip time sample in the
+	// exponentially weighted moving average of the peer latency.
+	latencyWeight = 0.2
+)
+
+var pingInterval = 30 * time.Second // interval between pings to connected peers
+
 type Interface interface {
 	Ping(ctx context.Context, address swarm.Address, msgs ...string) (rtt time.Duration, err error)
+	LatencyReporter
+}
+
+// LatencyReporter reports the measured latency to peers.
+type LatencyReporter interface {
+	// Latency returns the moving average of round trip times to the peer
+	// and false if the latency was never measured.
+	Latency(address swarm.Address) (latency time.Duration, ok bool)
 }
 
 type Service struct {
-	streamer p2p.Streamer
-	logger   logging.Logger
-	tracer   *tracing.Tracer
-	metrics  metrics
+	streamer    p2p.Streamer
+	logger      logging.Logger
+	tracer      *tracing.Tracer
+	metrics     metrics
+	latencies   map[string]time.Duration // moving average of round trip times keyed by peer overlay
+	latenciesMu sync.RWMutex
+	quit        chan struct{}
+	wg          sync.WaitGroup
 }
 
 func New(streamer p2p.Streamer, logger logging.Logger, tracer *tracing.Tracer) *Service {
 	return &Service{
-		streamer: streamer,
-		logger:   logger,
-		tracer:   tracer,
-		metrics:  newMetrics(),
+		streamer:  streamer,
+		logger:    logger,
+		tracer:    tracer,
+		metrics:   newMetrics(),
+		latencies: make(map[string]time.Duration),
+		quit:      make(chan struct{}),
 	}
 }
 
@@ -91,7 +116,102 @@ func (s *Service) Ping(ctx context.Context, address swarm.Address, msgs ...strin
 		logger.Tracef("got pong: %q", pong.Response)
 		s.metrics.PongReceivedCount.Inc()
 	}
-	return time.Since(start), nil
+	rtt = time.Since(start)
+
+	if n := len(msgs); n > 0 {
+		s.recordLatency(address, rtt/time.Duration(n))
+	}
+	return rtt, nil
+}
+
+// Latency implements the LatencyReporter interface.
+func (s *Service) Latency(address swarm.Address) (time.Duration, bool) {
+	s.latenciesMu.RLock()
+	defer s.latenciesMu.RUnlock()
+
+	l, ok := s.latencies[address.ByteString()]
+	return l, ok
+}
+
+// recordLatency adds a round trip time sample to the moving average of the
+// peer latency.
+func (s *Service) recordLatency(address swarm.Address, rtt time.Duration) {
+	s.metrics.RTT.Observe(rtt.Seconds())
+
+	s.latenciesMu.Lock()
+	defer s.latenciesMu.Unlock()
+
+	l, ok := s.latencies[address.ByteString()]
+	if !ok {
+		s.latencies[address.ByteString()] = rtt
+		return
+	}
+	s.latencies[address.ByteString()] = l + time.Duration(latencyWeight*float64(rtt-l))
+}
+
+// Start periodically pings all peers provided by the peerer to measure their
+// latency, until the service is closed.
+func (s *Service) Start(peerer topology.EachPeerer) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.pingPeers(peerer)
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// pingPeers pings all peers concurrently and forgets the latency of the peers
+// that are no longer connected.
+func (s *Service) pingPeers(peerer topology.EachPeerer) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	connected := make(map[string]struct{})
+	_ = peerer.EachPeer(func(peer swarm.Address, _ uint8) (bool, bool, error) {
+		connected[peer.ByteString()] = struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Ping(ctx, peer, "ping"); err != nil {
+				s.logger.Debugf("pingpong: ping peer %s: %v", peer, err)
+			}
+		}()
+		return false, false, nil
+	})
+	wg.Wait()
+
+	s.latenciesMu.Lock()
+	defer s.latenciesMu.Unlock()
+	for k := range s.latencies {
+		if _, ok := connected[k]; !ok {
+			delete(s.latencies, k)
+		}
+	}
+}
+
+// Close stops the periodic pinging.
+func (s *Service) Close() error {
+	close(s.quit)
+	s.wg.Wait()
+	return nil
 }
 
 func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) error {
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
 	"github.com/ethersphere/bee/pkg/pingpong"
 	"github.com/ethersphere/bee/pkg/pingpong/pb"
+	"github.com/ethersphere/bee/pkg/topology"
 )
 
 func TestPing(t *testing.T) {
@@ -110,3 +112,78 @@ func TestPing(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPingLatency(t *testing.T) {
+	defer func(d time.Duration) {
+		*pingpong.PingInterval = d
+	}(*pingpong.PingInterval)
+	*pingpong.PingInterval = 10 * time.Millisecond
+
+	logger := logging.New(ioutil.Discard, 0)
+	server := pingpong.New(nil, logger, nil)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+		streamtest.WithMiddlewares(func(f p2p.HandlerFunc) p2p.HandlerFunc {
+			time.Sleep(time.Millisecond)
+			return f
+		}),
+	)
+	client := pingpong.New(recorder, logger, nil)
+
+	addr := swarm.MustParseHexAddress("ca1e9f3938cc1425c6061b96ad9eb93e134dfe8734ad490164ef20af9d1cf59c")
+	if _, ok := client.Latency(addr); ok {
+		t.Fatal("got latency of a peer that was never pinged")
+	}
+
+	peerer := &peerer{peers: []swarm.Address{addr}}
+	client.Start(peerer)
+	defer client.Close()
+
+	waitLatency(t, client, addr, true)
+
+	// the latency of disconnected peers is forgotten
+	peerer.set(nil)
+	waitLatency(t, client, addr, false)
+}
+
+func waitLatency(t *testing.T, s *pingpong.Service, addr swarm.Address, want bool) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		l, ok := s.Latency(addr)
+		if ok == want {
+			if ok && l <= 0 {
+				t.Fatalf("invalid latency %v", l)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for the latency to be known: %v", want)
+}
+
+type peerer struct {
+	peers []swarm.Address
+	mu    sync.Mutex
+}
+
+func (p *peerer) set(peers []swarm.Address) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.peers = peers
+}
+
+func (p *peerer) EachPeer(f topology.EachPeerFunc) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, peer := range p.peers {
+		if _, _, err := f(peer, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *peerer) EachPeerRev(f topology.EachPeerFunc) error {
+	return p.EachPeer(f)
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"github.com/ethersphere/bee/pkg/puller"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type Service struct {
+	status *puller.Status
+}
+
+// WithStatus sets the sync status returned by the mock.
+func WithStatus(status *puller.Status) Option {
+	return optionFunc(func(s *Service) {
+		s.status = status
+	})
+}
+
+// NewService creates the mock puller service.
+func NewService(opts ...Option) *Service {
+	s := &Service{
+		status: &puller.Status{SyncPercentage: 100},
+	}
+	for _, o := range opts {
+		o.apply(s)
+	}
+	return s
+}
+
+func (s *Service) SyncStatus() (*puller.Status, error) {
+	return s.status, nil
+}
+
+func (s *Service) PeerSyncStatus(peer swarm.Address) (*puller.PeerStatus, error) {
+	for _, ps := range s.status.Peers {
+		if ps.Address.Equal(peer) {
+			ps := ps
+			return &ps, nil
+		}
+	}
+	return nil, puller.ErrPeerNotSyncing
+}
+
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(s *Service) { f(s) }
@@ -46,6 +46,9 @@ type Puller struct {
 	cursors    map[string][]uint64
 	cursorsMtx sync.Mutex
 
+	workers    map[string]*binWorkers // key is peer interval key
+	workersMtx sync.Mutex
+
 	quit chan struct{}
 	wg   sync.WaitGroup
 
@@ -72,6 +75,7 @@ func New(stateStore storage.StateStorer, topology topology.Driver, pullSync pull
 		metrics:    newMetrics(),
 		logger:     logger,
 		cursors:    make(map[string][]uint64),
+		workers:    make(map[string]*binWorkers),
 
 		syncPeers: make([]map[string]*syncPeer, bins),
 		quit:      make(chan struct{}),
@@ -368,6 +372,8 @@ func (p *Puller) histSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 	if logMore {
 		p.logger.Tracef("histSyncWorker starting, peer %s bin %d cursor %d", peer, bin, cur)
 	}
+	p.workerStarted(peer, bin, false)
+	defer p.workerDone(peer, bin, false)
 	for {
 		p.metrics.HistWorkerIterCounter.Inc()
 		select {
@@ -424,6 +430,8 @@ func (p *Puller) liveSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 	if logMore {
 		p.logger.Tracef("liveSyncWorker starting, peer %s bin %d cursor %d", peer, bin, cur)
 	}
+	p.workerStarted(peer, bin, true)
+	defer p.workerDone(peer, bin, true)
 	from := cur + 1
 	for {
 		p.metrics.LiveWorkerIterCounter.Inc()
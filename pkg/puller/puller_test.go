@@ -381,6 +381,74 @@ func TestDepthChange(t *testing.T) {
 	}
 }
 
+func TestSyncStatus(t *testing.T) {
+	addr := test.RandomAddress()
+
+	p, _, kad, pullsync := newPuller(opts{
+		kad: []mockk.Option{
+			mockk.WithEachPeerRevCalls(
+				mockk.AddrTuple{Addr: addr, PO: 1},
+			), mockk.WithDepth(2),
+		},
+		pullSync: []mockps.Option{mockps.WithCursors([]uint64{0, 10}), mockps.WithAutoReply(), mockps.WithLiveSyncBlock()},
+		bins:     5,
+	})
+	defer p.Close()
+	defer pullsync.Close()
+	runtime.Gosched()
+	time.Sleep(10 * time.Millisecond)
+
+	kad.Trigger()
+
+	waitCursorsCalled(t, pullsync, addr, false)
+	waitLiveSyncCalled(t, pullsync, addr, false)
+
+	var status *puller.Status
+	for i := 0; i < 20; i++ {
+		var err error
+		status, err = p.SyncStatus()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.SyncPercentage == 100 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if status.SyncPercentage != 100 {
+		t.Fatalf("got sync percentage %v, want 100", status.SyncPercentage)
+	}
+	if len(status.Peers) != 1 {
+		t.Fatalf("got %d peers, want 1", len(status.Peers))
+	}
+
+	ps, err := p.PeerSyncStatus(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ps.Address.Equal(addr) || ps.PO != 1 {
+		t.Fatalf("got peer %s po %d, want peer %s po 1", ps.Address, ps.PO, addr)
+	}
+	if len(ps.Bins) != 1 {
+		t.Fatalf("got %d bins, want 1", len(ps.Bins))
+	}
+	b := ps.Bins[0]
+	if b.Bin != 1 || b.Cursor != 10 || b.HistoricalRemaining != 0 {
+		t.Fatalf("got bin %d cursor %d remaining %d, want bin 1 cursor 10 remaining 0", b.Bin, b.Cursor, b.HistoricalRemaining)
+	}
+	if !b.LiveSyncing {
+		t.Fatal("bin is not live syncing")
+	}
+	if len(b.Intervals) != 1 || b.Intervals[0] != [2]uint64{1, 10} {
+		t.Fatalf("got intervals %v, want [[1 10]]", b.Intervals)
+	}
+
+	if _, err := p.PeerSyncStatus(test.RandomAddress()); !errors.Is(err, puller.ErrPeerNotSyncing) {
+		t.Fatalf("got error %v, want %v", err, puller.ErrPeerNotSyncing)
+	}
+}
+
 func checkIntervals(t *testing.T, s storage.StateStorer, addr swarm.Address, expInterval string, bin uint8) {
 	t.Helper()
 	key := puller.PeerIntervalKey(addr, bin)
@@ -0,0 +1,264 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package puller
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/intervalstore"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrPeerNotSyncing is returned when the sync status is requested for a peer
+// that the node is not syncing with.
+var ErrPeerNotSyncing = errors.New("not syncing with peer")
+
+// Interface exposes the state of pull syncing.
+type Interface interface {
+	// SyncStatus returns the sync state of all peers that the node is
+	// syncing with.
+	SyncStatus() (*Status, error)
+	// PeerSyncStatus returns the sync state of a single peer.
+	PeerSyncStatus(peer swarm.Address) (*PeerStatus, error)
+}
+
+// Status is the sync state of all peers that the node is syncing with.
+type Status struct {
+	Peers []PeerStatus
+	// SyncPercentage is the percentage of the historical ranges of all
+	// synced bins that are covered by the intervals.
+	SyncPercentage float64
+}
+
+// PeerStatus is the sync state of a single peer.
+type PeerStatus struct {
+	Address        swarm.Address
+	PO             uint8
+	Bins           []BinStatus
+	SyncPercentage float64
+}
+
+// BinStatus is the sync state of a single bin of a peer.
+type BinStatus struct {
+	Bin uint8
+	// Cursor is the last bin ID of the peer at the time of the cursors
+	// exchange, bin IDs up to it are synced historically.
+	Cursor uint64
+	// Intervals are the synced ranges of bin IDs.
+	Intervals [][2]uint64
+	// HistoricalRemaining is the number of bin IDs up to the cursor that
+	// are not yet synced.
+	HistoricalRemaining uint64
+	HistoricalSyncing   bool
+	LiveSyncing         bool
+}
+
+// binWorkers counts the running sync workers for a peer bin.
+type binWorkers struct {
+	live, historical int
+}
+
+// SyncStatus returns the sync state of all peers that the node is syncing
+// with, sorted by proximity order and address.
+func (p *Puller) SyncStatus() (*Status, error) {
+	p.syncPeersMtx.Lock()
+	var peers []PeerStatus
+	for po, bin := range p.syncPeers {
+		for _, sp := range bin {
+			peers = append(peers, p.newPeerStatus(sp, uint8(po)))
+		}
+	}
+	p.syncPeersMtx.Unlock()
+
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].PO != peers[j].PO {
+			return peers[i].PO < peers[j].PO
+		}
+		return peers[i].Address.String() < peers[j].Address.String()
+	})
+
+	var covered, total uint64
+	for i := range peers {
+		c, t, err := p.fillBinStatuses(&peers[i])
+		if err != nil {
+			return nil, err
+		}
+		covered += c
+		total += t
+	}
+
+	return &Status{
+		Peers:          peers,
+		SyncPercentage: percentage(covered, total),
+	}, nil
+}
+
+// PeerSyncStatus returns the sync state of a peer. ErrPeerNotSyncing is
+// returned if the node is not syncing with the peer.
+func (p *Puller) PeerSyncStatus(peer swarm.Address) (*PeerStatus, error) {
+	p.syncPeersMtx.Lock()
+	var (
+		ps    PeerStatus
+		found bool
+	)
+	for po, bin := range p.syncPeers {
+		if sp, ok := bin[peer.String()]; ok {
+			ps = p.newPeerStatus(sp, uint8(po))
+			found = true
+			break
+		}
+	}
+	p.syncPeersMtx.Unlock()
+
+	if !found {
+		return nil, ErrPeerNotSyncing
+	}
+
+	if _, _, err := p.fillBinStatuses(&ps); err != nil {
+		return nil, err
+	}
+	return &ps, nil
+}
+
+// newPeerStatus creates the status of a sync peer with bins that are
+// currently synced. It must be called under the syncPeersMtx lock.
+func (p *Puller) newPeerStatus(sp *syncPeer, po uint8) PeerStatus {
+	sp.Lock()
+	bins := make([]BinStatus, 0, len(sp.binCancelFuncs))
+	for bin := range sp.binCancelFuncs {
+		bins = append(bins, BinStatus{Bin: bin})
+	}
+	sp.Unlock()
+
+	sort.Slice(bins, func(i, j int) bool {
+		return bins[i].Bin < bins[j].Bin
+	})
+
+	return PeerStatus{
+		Address: sp.address,
+		PO:      po,
+		Bins:    bins,
+	}
+}
+
+// fillBinStatuses sets the cursors, intervals and workers state of all peer
+// bins. It returns the number of covered and total bin IDs of historical
+// ranges.
+func (p *Puller) fillBinStatuses(ps *PeerStatus) (covered, total uint64, err error) {
+	p.cursorsMtx.Lock()
+	cursors := p.cursors[ps.Address.String()]
+	p.cursorsMtx.Unlock()
+
+	for i := range ps.Bins {
+		b := &ps.Bins[i]
+		if int(b.Bin) < len(cursors) {
+			b.Cursor = cursors[b.Bin]
+		}
+
+		intervals, err := p.peerInterval(ps.Address, b.Bin)
+		if err != nil {
+			return 0, 0, err
+		}
+		b.Intervals = intervals.Ranges()
+		c := coveredUntil(b.Intervals, b.Cursor)
+		b.HistoricalRemaining = b.Cursor - c
+
+		p.workersMtx.Lock()
+		if w, ok := p.workers[peerIntervalKey(ps.Address, b.Bin)]; ok {
+			b.HistoricalSyncing = w.historical > 0
+			b.LiveSyncing = w.live > 0
+		}
+		p.workersMtx.Unlock()
+
+		covered += c
+		total += b.Cursor
+	}
+
+	ps.SyncPercentage = percentage(covered, total)
+	return covered, total, nil
+}
+
+// peerInterval returns the persisted intervals for a peer bin without
+// creating them if they do not exist.
+func (p *Puller) peerInterval(peer swarm.Address, bin uint8) (*intervalstore.Intervals, error) {
+	p.intervalMtx.Lock()
+	defer p.intervalMtx.Unlock()
+
+	i := &intervalstore.Intervals{}
+	err := p.statestore.Get(peerIntervalKey(peer, bin), i)
+	switch err {
+	case nil:
+		return i, nil
+	case storage.ErrNotFound:
+		return intervalstore.NewIntervals(1), nil
+	default:
+		return nil, fmt.Errorf("get peer interval: %w", err)
+	}
+}
+
+func (p *Puller) workerStarted(peer swarm.Address, bin uint8, live bool) {
+	p.workersMtx.Lock()
+	defer p.workersMtx.Unlock()
+
+	key := peerIntervalKey(peer, bin)
+	w, ok := p.workers[key]
+	if !ok {
+		w = new(binWorkers)
+		p.workers[key] = w
+	}
+	if live {
+		w.live++
+	} else {
+		w.historical++
+	}
+}
+
+func (p *Puller) workerDone(peer swarm.Address, bin uint8, live bool) {
+	p.workersMtx.Lock()
+	defer p.workersMtx.Unlock()
+
+	key := peerIntervalKey(peer, bin)
+	w, ok := p.workers[key]
+	if !ok {
+		return
+	}
+	if live {
+		w.live--
+	} else {
+		w.historical--
+	}
+	if w.live <= 0 && w.historical <= 0 {
+		delete(p.workers, key)
+	}
+}
+
+// coveredUntil returns the number of bin IDs from 1 to the cursor, inclusive,
+// that are covered by the ranges.
+func coveredUntil(ranges [][2]uint64, cursor uint64) (covered uint64) {
+	for _, r := range ranges {
+		start, end := r[0], r[1]
+		if start < 1 {
+			start = 1
+		}
+		if start > cursor {
+			break
+		}
+		if end > cursor {
+			end = cursor
+		}
+		covered += end - start + 1
+	}
+	return covered
+}
+
+func percentage(covered, total uint64) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(covered) * 100 / float64(total)
+}
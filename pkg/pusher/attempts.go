@@ -0,0 +1,152 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pusher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const attemptKeyPrefix = "pusher_attempt_"
+
+var (
+	// ErrChunkNotTracked is returned when a retry is requested for a chunk
+	// that has no failed push attempts.
+	ErrChunkNotTracked = errors.New("chunk not tracked")
+)
+
+// Attempt holds the state of push attempts for a chunk that could not be
+// pushed to its neighbourhood.
+type Attempt struct {
+	Address     swarm.Address `json:"address"`
+	TagID       uint32        `json:"tagID"`
+	Count       int           `json:"count"`
+	LastAttempt time.Time     `json:"lastAttempt"`
+	NextAttempt time.Time     `json:"nextAttempt"`
+	Failed      bool          `json:"failed"`
+	Reason      string        `json:"reason"`
+}
+
+// attempts keeps track of failed push attempts per chunk. Every change is
+// persisted in the state store so that backoff and failure information
+// survives node restarts.
+type attempts struct {
+	store       storage.StateStorer
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+
+	attempts map[string]*Attempt // key is the chunk address byte string
+	mtx      sync.Mutex
+}
+
+func newAttempts(store storage.StateStorer, maxAttempts int, backoff, maxBackoff time.Duration) (*attempts, error) {
+	a := &attempts{
+		store:       store,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		maxBackoff:  maxBackoff,
+		attempts:    make(map[string]*Attempt),
+	}
+
+	if err := store.Iterate(attemptKeyPrefix, func(key, value []byte) (stop bool, err error) {
+		var at Attempt
+		if err := json.Unmarshal(value, &at); err != nil {
+			return true, fmt.Errorf("attempt %s: %w", key, err)
+		}
+		a.attempts[at.Address.ByteString()] = &at
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// due reports whether the chunk should be pushed now. Chunks that have
+// permanently failed or are still backing off are not due.
+func (a *attempts) due(addr swarm.Address, now time.Time) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	at, ok := a.attempts[addr.ByteString()]
+	if !ok {
+		return true
+	}
+	return !at.Failed && !now.Before(at.NextAttempt)
+}
+
+// fail records an unsuccessful push attempt and schedules the next one with
+// an exponential backoff. It returns true if the chunk has reached the maximal
+// number of attempts and is marked as failed.
+func (a *attempts) fail(ch swarm.Chunk, reason error, now time.Time) (failed bool, err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	key := ch.Address().ByteString()
+	at, ok := a.attempts[key]
+	if !ok {
+		at = &Attempt{
+			Address: ch.Address(),
+			TagID:   ch.TagID(),
+		}
+		a.attempts[key] = at
+	}
+	if at.Failed {
+		return false, nil
+	}
+
+	at.Count++
+	at.LastAttempt = now
+	at.Reason = reason.Error()
+
+	backoff := a.maxBackoff
+	if shift := uint(at.Count - 1); shift < 32 && a.backoff<<shift < a.maxBackoff {
+		backoff = a.backoff << shift
+	}
+	at.NextAttempt = now.Add(backoff)
+
+	if a.maxAttempts > 0 && at.Count >= a.maxAttempts {
+		at.Failed = true
+		at.NextAttempt = time.Time{}
+	}
+
+	return at.Failed, a.store.Put(attemptKey(ch.Address()), at)
+}
+
+// remove deletes the attempts record for the chunk, returning it, if it
+// exists.
+func (a *attempts) remove(addr swarm.Address) (*Attempt, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	at, ok := a.attempts[addr.ByteString()]
+	if !ok {
+		return nil, ErrChunkNotTracked
+	}
+	delete(a.attempts, addr.ByteString())
+	return at, a.store.Delete(attemptKey(addr))
+}
+
+// list returns copies of all attempts records.
+func (a *attempts) list() []Attempt {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	list := make([]Attempt, 0, len(a.attempts))
+	for _, at := range a.attempts {
+		list = append(list, *at)
+	}
+	return list
+}
+
+func attemptKey(addr swarm.Address) string {
+	return attemptKeyPrefix + addr.String()
+}
@@ -0,0 +1,9 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pusher
+
+var RetryInterval = &retryInterval
+
+var ReceiptsPruneInterval = &receiptsPruneInterval
@@ -17,6 +17,8 @@ type metrics struct {
 	TotalChunksToBeSentCounter prometheus.Counter
 	TotalChunksSynced          prometheus.Counter
 	ErrorSettingChunkToSynced  prometheus.Counter
+	TotalFailedAttempts        prometheus.Counter
+	TotalChunksFailed          prometheus.Counter
 	MarkAndSweepTimer          prometheus.Histogram
 }
 
@@ -42,6 +44,18 @@ func newMetrics() metrics {
 			Name:      "cannot_set_chunk_sync_in_db",
 			Help:      "Total no of times the chunk cannot be synced in DB.",
 		}),
+		TotalFailedAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_failed_attempts",
+			Help:      "Total no of failed attempts to push a chunk.",
+		}),
+		TotalChunksFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "total_chunk_failed",
+			Help:      "Total chunks marked as failed after reaching the maximal number of push attempts.",
+		}),
 		MarkAndSweepTimer: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -5,6 +5,8 @@
 package mock
 
 import (
+	"github.com/ethersphere/bee/pkg/pusher"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 )
 
@@ -37,3 +39,68 @@ func (m *MockPusher) RcvdReceipt(uid uint32) error {
 
 	return nil
 }
+
+// Service is the mock of the pusher push attempts interface.
+type Service struct {
+	attempts  []pusher.Attempt
+	receipts  []pusher.Receipt
+	retryFunc func(swarm.Address) error
+}
+
+// WithAttempts sets the push attempts returned by the mock.
+func WithAttempts(attempts ...pusher.Attempt) Option {
+	return optionFunc(func(s *Service) {
+		s.attempts = attempts
+	})
+}
+
+// WithReceipts sets the receipts returned by the mock.
+func WithReceipts(receipts ...pusher.Receipt) Option {
+	return optionFunc(func(s *Service) {
+		s.receipts = receipts
+	})
+}
+
+// WithRetryFunc sets the mock Retry function.
+func WithRetryFunc(f func(swarm.Address) error) Option {
+	return optionFunc(func(s *Service) {
+		s.retryFunc = f
+	})
+}
+
+// NewService creates the mock pusher attempts service.
+func NewService(opts ...Option) *Service {
+	s := new(Service)
+	for _, o := range opts {
+		o.apply(s)
+	}
+	return s
+}
+
+func (s *Service) Attempts() []pusher.Attempt {
+	return s.attempts
+}
+
+func (s *Service) Retry(addr swarm.Address) error {
+	if s.retryFunc != nil {
+		return s.retryFunc(addr)
+	}
+	return nil
+}
+
+func (s *Service) Receipt(addr swarm.Address) (pusher.Receipt, error) {
+	for _, r := range s.receipts {
+		if r.Address.Equal(addr) {
+			return r, nil
+		}
+	}
+	return pusher.Receipt{}, pusher.ErrReceiptNotFound
+}
+
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(r *Service) { f(r) }
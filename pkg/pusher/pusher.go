@@ -7,6 +7,7 @@ package pusher
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -18,30 +19,89 @@ import (
 	"github.com/ethersphere/bee/pkg/topology"
 )
 
+// Interface exposes the push attempts of chunks that could not be synced.
+type Interface interface {
+	// Attempts returns all chunks that are backing off or have failed to
+	// be pushed.
+	Attempts() []Attempt
+	// Retry resets the attempts of a chunk so that it is pushed again on
+	// the next iteration of the push index.
+	Retry(addr swarm.Address) error
+	// Receipt returns the verified receipt of a synced chunk.
+	Receipt(addr swarm.Address) (Receipt, error)
+}
+
+type Options struct {
+	MaxAttempts int           // number of push attempts after which a chunk is marked as failed
+	Backoff     time.Duration // initial backoff after a failed push attempt
+	MaxBackoff  time.Duration // maximal backoff between push attempts
+	ReceiptTTL  time.Duration // duration for which the receipts of synced chunks are kept
+}
+
 type Service struct {
-	storer            storage.Storer
-	pushSyncer        pushsync.PushSyncer
-	logger            logging.Logger
-	tagg              *tags.Tags
-	metrics           metrics
-	quit              chan struct{}
-	chunksWorkerQuitC chan struct{}
+	storer              storage.Storer
+	pushSyncer          pushsync.PushSyncer
+	logger              logging.Logger
+	tagg                *tags.Tags
+	attempts            *attempts
+	receipts            *receipts
+	metrics             metrics
+	quit                chan struct{}
+	chunksWorkerQuitC   chan struct{}
+	receiptsWorkerQuitC chan struct{}
 }
 
-var retryInterval = 10 * time.Second // time interval between retries
+var (
+	retryInterval      = 10 * time.Second // time interval between retries
+	defaultMaxAttempts = 16
+	defaultMaxBackoff  = time.Hour
+	defaultReceiptTTL  = 7 * 24 * time.Hour
+
+	// receiptsPruneInterval is the time interval between removals of
+	// expired receipts
+	receiptsPruneInterval = time.Hour
+)
+
+func New(storer storage.Storer, stateStore storage.StateStorer, peerSuggester topology.ClosestPeerer, pushSyncer pushsync.PushSyncer, tagger *tags.Tags, logger logging.Logger, o Options) (*Service, error) {
+	var (
+		maxAttempts = defaultMaxAttempts
+		backoff     = retryInterval
+		maxBackoff  = defaultMaxBackoff
+		receiptTTL  = defaultReceiptTTL
+	)
+	if o.MaxAttempts != 0 {
+		maxAttempts = o.MaxAttempts
+	}
+	if o.Backoff != 0 {
+		backoff = o.Backoff
+	}
+	if o.MaxBackoff != 0 {
+		maxBackoff = o.MaxBackoff
+	}
+	if o.ReceiptTTL != 0 {
+		receiptTTL = o.ReceiptTTL
+	}
+
+	a, err := newAttempts(stateStore, maxAttempts, backoff, maxBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("load push attempts: %w", err)
+	}
 
-func New(storer storage.Storer, peerSuggester topology.ClosestPeerer, pushSyncer pushsync.PushSyncer, tagger *tags.Tags, logger logging.Logger) *Service {
 	service := &Service{
-		storer:            storer,
-		pushSyncer:        pushSyncer,
-		tagg:              tagger,
-		logger:            logger,
-		metrics:           newMetrics(),
-		quit:              make(chan struct{}),
-		chunksWorkerQuitC: make(chan struct{}),
+		storer:              storer,
+		pushSyncer:          pushSyncer,
+		tagg:                tagger,
+		logger:              logger,
+		attempts:            a,
+		receipts:            &receipts{store: stateStore, ttl: receiptTTL},
+		metrics:             newMetrics(),
+		quit:                make(chan struct{}),
+		chunksWorkerQuitC:   make(chan struct{}),
+		receiptsWorkerQuitC: make(chan struct{}),
 	}
 	go service.chunksWorker()
-	return service
+	go service.receiptsWorker()
+	return service, nil
 }
 
 // chunksWorker is a loop that keeps looking for chunks that are locally uploaded ( by monitoring pushIndex )
@@ -82,6 +142,12 @@ LOOP:
 
 			// postpone a retry only after we've finished processing everything in index
 			timer.Reset(retryInterval)
+
+			// skip chunks that are backing off after a failed push or have failed permanently
+			if !s.attempts.due(ch.Address(), time.Now()) {
+				break
+			}
+
 			chunksInBatch++
 			s.metrics.TotalChunksToBeSentCounter.Inc()
 			select {
@@ -114,15 +180,22 @@ LOOP:
 					mtx.Unlock()
 					<-sem
 				}()
-				// Later when we process receipt, get the receipt and process it
-				// for now ignoring the receipt and checking only for error
-				_, err = s.pushSyncer.PushChunkToClosest(ctx, ch)
+				var receipt *pushsync.Receipt
+				receipt, err = s.pushSyncer.PushChunkToClosest(ctx, ch)
 				if err != nil {
+					// no peer to push to is not counted as a failed attempt
 					if !errors.Is(err, topology.ErrNotFound) {
 						s.logger.Debugf("pusher: error while sending chunk or receiving receipt: %v", err)
+						s.recordFailedAttempt(ch, err)
 					}
 					return
 				}
+				// the storer of the receipt is verified by pushsync
+				if receipt != nil {
+					if err := s.receipts.put(receipt, time.Now()); err != nil {
+						s.logger.Errorf("pusher: store receipt of chunk %s: %v", ch.Address(), err)
+					}
+				}
 				s.setChunkAsSynced(ctx, ch)
 			}(ctx, ch)
 		case <-timer.C:
@@ -165,6 +238,30 @@ LOOP:
 	}
 }
 
+// receiptsWorker periodically removes the expired receipts from the state
+// store.
+func (s *Service) receiptsWorker() {
+	defer close(s.receiptsWorkerQuitC)
+
+	ticker := time.NewTicker(receiptsPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			count, err := s.receipts.prune(time.Now())
+			if err != nil {
+				s.logger.Errorf("pusher: prune receipts: %v", err)
+			}
+			if count > 0 {
+				s.logger.Debugf("pusher: %d expired receipts pruned", count)
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
 func (s *Service) setChunkAsSynced(ctx context.Context, ch swarm.Chunk) {
 	if err := s.storer.Set(ctx, storage.ModeSetSyncPush, ch.Address()); err != nil {
 		s.logger.Errorf("pusher: error setting chunk as synced: %v", err)
@@ -174,6 +271,57 @@ func (s *Service) setChunkAsSynced(ctx context.Context, ch swarm.Chunk) {
 	if err == nil && t != nil {
 		t.Inc(tags.StateSynced)
 	}
+	if _, err := s.attempts.remove(ch.Address()); err != nil && !errors.Is(err, ErrChunkNotTracked) {
+		s.logger.Errorf("pusher: remove push attempts of chunk %s: %v", ch.Address(), err)
+	}
+}
+
+// recordFailedAttempt schedules the next push attempt of the chunk with
+// backoff and marks it as failed if the maximal number of attempts is reached.
+func (s *Service) recordFailedAttempt(ch swarm.Chunk, reason error) {
+	s.metrics.TotalFailedAttempts.Inc()
+	failed, err := s.attempts.fail(ch, reason, time.Now())
+	if err != nil {
+		s.logger.Errorf("pusher: store push attempt of chunk %s: %v", ch.Address(), err)
+	}
+	if !failed {
+		return
+	}
+	s.logger.Debugf("pusher: chunk %s marked as failed: %v", ch.Address(), reason)
+	s.metrics.TotalChunksFailed.Inc()
+	t, err := s.tagg.Get(ch.TagID())
+	if err == nil && t != nil {
+		t.Inc(tags.StateFailed)
+	}
+}
+
+// Attempts returns all chunks that are backing off or have failed to be
+// pushed.
+func (s *Service) Attempts() []Attempt {
+	return s.attempts.list()
+}
+
+// Retry resets the push attempts of a chunk, so that it is pushed on the next
+// iteration of the push index.
+func (s *Service) Retry(addr swarm.Address) error {
+	at, err := s.attempts.remove(addr)
+	if err != nil {
+		return err
+	}
+	if at.Failed {
+		t, err := s.tagg.Get(at.TagID)
+		if err == nil && t != nil {
+			t.IncN(tags.StateFailed, -1)
+		}
+	}
+	return nil
+}
+
+// Receipt returns the verified receipt of a synced chunk. It returns
+// ErrReceiptNotFound if the chunk is not synced by this node or if its
+// receipt is expired.
+func (s *Service) Receipt(addr swarm.Address) (Receipt, error) {
+	return s.receipts.get(addr, time.Now())
 }
 
 func (s *Service) Close() error {
@@ -184,5 +332,6 @@ func (s *Service) Close() error {
 	case <-s.chunksWorkerQuitC:
 	case <-time.After(3 * time.Second):
 	}
+	<-s.receiptsWorkerQuitC
 	return nil
 }
@@ -5,6 +5,7 @@
 package pusher_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io/ioutil"
@@ -17,6 +18,7 @@ import (
 	"github.com/ethersphere/bee/pkg/pusher"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -53,7 +55,9 @@ func TestSendChunkToPushSyncWithTag(t *testing.T) {
 
 	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
 		receipt := &pushsync.Receipt{
-			Address: swarm.NewAddress(chunk.Address().Bytes()),
+			Address:   swarm.NewAddress(chunk.Address().Bytes()),
+			Storer:    closestPeer,
+			Signature: []byte{1, 2, 3},
 		}
 		return receipt, nil
 	})
@@ -90,6 +94,20 @@ func TestSendChunkToPushSyncWithTag(t *testing.T) {
 		t.Fatalf("tags error")
 	}
 
+	receipt, err := p.Receipt(chunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Storer.Equal(closestPeer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+	if !bytes.Equal(receipt.Signature, []byte{1, 2, 3}) {
+		t.Fatalf("got receipt signature %x, want %x", receipt.Signature, []byte{1, 2, 3})
+	}
+	if _, err := p.Receipt(closestPeer); !errors.Is(err, pusher.ErrReceiptNotFound) {
+		t.Fatalf("got error %v, want %v", err, pusher.ErrReceiptNotFound)
+	}
+
 	p.Close()
 }
 
@@ -212,6 +230,173 @@ func TestSendChunkAndTimeoutinReceivingReceipt(t *testing.T) {
 	}
 }
 
+// TestPushChunkFailedAttempts checks that a chunk which repeatedly fails to be
+// pushed is backing off, is marked as failed after the maximal number of
+// attempts and that the failure survives a restart of the pusher until the
+// chunk is retried.
+func TestPushChunkFailedAttempts(t *testing.T) {
+	defer func(d time.Duration) { *pusher.RetryInterval = d }(*pusher.RetryInterval)
+	*pusher.RetryInterval = 10 * time.Millisecond
+
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+
+	var (
+		pushes   int
+		pushesMu sync.Mutex
+	)
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		pushesMu.Lock()
+		pushes++
+		pushesMu.Unlock()
+		return nil, errors.New("invalid receipt")
+	})
+
+	stateStore := statestore.NewStateStore()
+	o := pusher.Options{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+	mtags, p, storer := createPusherWithOptions(t, triggerPeer, stateStore, pushSyncService, o, mock.WithClosestPeer(closestPeer))
+	defer storer.Close()
+
+	ta, err := mtags.Create("test", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := createChunk().WithTagID(ta.Uid)
+
+	_, err = storer.Put(context.Background(), storage.ModePutUpload, chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts []pusher.Attempt
+	for i := 0; i < noOfRetries*10; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		attempts = p.Attempts()
+		if len(attempts) == 1 && attempts[0].Failed {
+			break
+		}
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("got %d attempts records, want 1", len(attempts))
+	}
+	if !attempts[0].Failed {
+		t.Fatal("chunk not marked as failed")
+	}
+	if !attempts[0].Address.Equal(chunk.Address()) {
+		t.Fatalf("got attempts for chunk %s, want %s", attempts[0].Address, chunk.Address())
+	}
+	if attempts[0].Count != o.MaxAttempts {
+		t.Fatalf("got %d attempts, want %d", attempts[0].Count, o.MaxAttempts)
+	}
+	if attempts[0].Reason != "invalid receipt" {
+		t.Fatalf("got failure reason %q", attempts[0].Reason)
+	}
+	if got := ta.Get(tags.StateFailed); got != 1 {
+		t.Fatalf("got %d failed chunks in tag, want 1", got)
+	}
+
+	// failed chunks are not pushed any more
+	time.Sleep(50 * time.Millisecond)
+	pushesMu.Lock()
+	gotPushes := pushes
+	pushesMu.Unlock()
+	if gotPushes != o.MaxAttempts {
+		t.Fatalf("got %d pushes, want %d", gotPushes, o.MaxAttempts)
+	}
+	p.Close()
+
+	// failed attempts are loaded from the state store on restart
+	p, err = pusher.New(storer, stateStore, mock.NewTopologyDriver(), pushSyncService, mtags, logging.New(ioutil.Discard, 0), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	attempts = p.Attempts()
+	if len(attempts) != 1 || !attempts[0].Failed {
+		t.Fatalf("got attempts %v after restart, want one failed chunk", attempts)
+	}
+
+	if err := p.Retry(chunk.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if got := ta.Get(tags.StateFailed); got != 0 {
+		t.Fatalf("got %d failed chunks in tag after retry, want 0", got)
+	}
+	if err := p.Retry(chunk.Address()); !errors.Is(err, pusher.ErrChunkNotTracked) {
+		t.Fatalf("got error %v, want %v", err, pusher.ErrChunkNotTracked)
+	}
+}
+
+// TestReceiptsExpire checks that the receipts of synced chunks are removed
+// from the state store after they expire.
+func TestReceiptsExpire(t *testing.T) {
+	defer func(d time.Duration) { *pusher.ReceiptsPruneInterval = d }(*pusher.ReceiptsPruneInterval)
+	*pusher.ReceiptsPruneInterval = 10 * time.Millisecond
+
+	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
+	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+
+	pushSyncService := pushsyncmock.New(func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
+		return &pushsync.Receipt{
+			Address: swarm.NewAddress(chunk.Address().Bytes()),
+			Storer:  closestPeer,
+		}, nil
+	})
+
+	stateStore := statestore.NewStateStore()
+	o := pusher.Options{
+		ReceiptTTL: 50 * time.Millisecond,
+	}
+	_, p, storer := createPusherWithOptions(t, triggerPeer, stateStore, pushSyncService, o, mock.WithClosestPeer(closestPeer))
+	defer storer.Close()
+	defer p.Close()
+
+	chunk := createChunk()
+	if _, err := storer.Put(context.Background(), storage.ModePutUpload, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = p.Receipt(chunk.Address())
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	for i := 0; i < noOfRetries; i++ {
+		time.Sleep(10 * time.Millisecond)
+
+		count = 0
+		if err := stateStore.Iterate("pusher_receipt_", func(_, _ []byte) (bool, error) {
+			count++
+			return false, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if count == 0 {
+			break
+		}
+	}
+	if count != 0 {
+		t.Fatalf("got %d receipts in the state store, want none", count)
+	}
+	if _, err := p.Receipt(chunk.Address()); !errors.Is(err, pusher.ErrReceiptNotFound) {
+		t.Fatalf("got error %v, want %v", err, pusher.ErrReceiptNotFound)
+	}
+}
+
 func createChunk() swarm.Chunk {
 	// chunk data to upload
 	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
@@ -220,6 +405,11 @@ func createChunk() swarm.Chunk {
 }
 
 func createPusher(t *testing.T, addr swarm.Address, pushSyncService pushsync.PushSyncer, mockOpts ...mock.Option) (*tags.Tags, *pusher.Service, *Store) {
+	t.Helper()
+	return createPusherWithOptions(t, addr, statestore.NewStateStore(), pushSyncService, pusher.Options{}, mockOpts...)
+}
+
+func createPusherWithOptions(t *testing.T, addr swarm.Address, stateStore storage.StateStorer, pushSyncService pushsync.PushSyncer, o pusher.Options, mockOpts ...mock.Option) (*tags.Tags, *pusher.Service, *Store) {
 	t.Helper()
 	logger := logging.New(ioutil.Discard, 0)
 	storer, err := localstore.New("", addr.Bytes(), nil, logger)
@@ -235,7 +425,10 @@ func createPusher(t *testing.T, addr swarm.Address, pushSyncService pushsync.Pus
 	}
 	peerSuggester := mock.NewTopologyDriver(mockOpts...)
 
-	pusherService := pusher.New(pusherStorer, peerSuggester, pushSyncService, mtags, logger)
+	pusherService, err := pusher.New(pusherStorer, stateStore, peerSuggester, pushSyncService, mtags, logger, o)
+	if err != nil {
+		t.Fatal(err)
+	}
 	return mtags, pusherService, pusherStorer
 }
 
@@ -0,0 +1,101 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pusher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const receiptKeyPrefix = "pusher_receipt_"
+
+var (
+	// ErrReceiptNotFound is returned when no receipt is recorded for a
+	// chunk.
+	ErrReceiptNotFound = errors.New("receipt not found")
+)
+
+// Receipt is the verified push-sync receipt of a chunk, recording the node
+// that stored the chunk in its neighbourhood.
+type Receipt struct {
+	Address   swarm.Address `json:"address"`
+	Storer    swarm.Address `json:"storer"`
+	Signature []byte        `json:"signature"`
+	Synced    time.Time     `json:"synced"`
+}
+
+// receipts persists verified push-sync receipts in the state store, so that
+// the storer of every synced chunk can be audited after node restarts.
+// Receipts are kept for the ttl duration after the chunk is synced.
+type receipts struct {
+	store storage.StateStorer
+	ttl   time.Duration
+}
+
+// put records the storer of the chunk from the receipt.
+func (r *receipts) put(receipt *pushsync.Receipt, now time.Time) error {
+	return r.store.Put(receiptKey(receipt.Address), Receipt{
+		Address:   receipt.Address,
+		Storer:    receipt.Storer,
+		Signature: receipt.Signature,
+		Synced:    now,
+	})
+}
+
+// get returns the recorded receipt of the chunk, if it is not expired.
+func (r *receipts) get(addr swarm.Address, now time.Time) (receipt Receipt, err error) {
+	if err := r.store.Get(receiptKey(addr), &receipt); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return Receipt{}, ErrReceiptNotFound
+		}
+		return Receipt{}, err
+	}
+	if r.expired(receipt, now) {
+		return Receipt{}, ErrReceiptNotFound
+	}
+	return receipt, nil
+}
+
+// prune removes expired receipts from the state store. It returns the
+// number of removed receipts.
+func (r *receipts) prune(now time.Time) (count int, err error) {
+	// keys are removed after the iteration, as the state store
+	// can not be changed while it is iterated
+	var keys []string
+	if err := r.store.Iterate(receiptKeyPrefix, func(key, value []byte) (stop bool, err error) {
+		var receipt Receipt
+		if err := json.Unmarshal(value, &receipt); err != nil {
+			return true, fmt.Errorf("receipt %s: %w", key, err)
+		}
+		if r.expired(receipt, now) {
+			keys = append(keys, string(key))
+		}
+		return false, nil
+	}); err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		if err := r.store.Delete(key); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (r *receipts) expired(receipt Receipt, now time.Time) bool {
+	return now.Sub(receipt.Synced) > r.ttl
+}
+
+func receiptKey(addr swarm.Address) string {
+	return receiptKeyPrefix + addr.String()
+}
@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/pushsync/pb"
+	"github.com/ethersphere/bee/pkg/reputation"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
@@ -26,33 +28,51 @@ const (
 	streamName      = "pushsync"
 )
 
+var (
+	// ErrInvalidReceipt is returned when a receipt does not match the pushed
+	// chunk, is not signed by the claimed storer, or the storer is not within
+	// the neighbourhood of the chunk.
+	ErrInvalidReceipt = errors.New("invalid receipt")
+)
+
 type PushSyncer interface {
 	PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Receipt, error)
 }
 
+// Receipt is the proof that a chunk has been stored by a node in the
+// neighbourhood of the chunk.
 type Receipt struct {
-	Address swarm.Address
+	Address   swarm.Address // address of the stored chunk
+	Storer    swarm.Address // overlay address of the node that stored the chunk
+	Signature []byte        // signature of the storer over the chunk and storer addresses
 }
 
 type PushSync struct {
+	address          swarm.Address
 	streamer         p2p.Streamer
 	storer           storage.Putter
-	peerSuggester    topology.ClosestPeerer
+	topologyDriver   topology.Driver
 	tagg             *tags.Tags
 	deliveryCallback func(context.Context, swarm.Chunk) error // callback func to be invoked to deliver chunks to PSS
+	signer           crypto.Signer
+	networkID        uint64
+	reputation       reputation.Recorder
 	logger           logging.Logger
 	metrics          metrics
 }
 
 var timeToWaitForReceipt = 3 * time.Second // time to wait to get a receipt for a chunk
 
-func New(streamer p2p.Streamer, storer storage.Putter, closestPeerer topology.ClosestPeerer, tagger *tags.Tags, deliveryCallback func(context.Context, swarm.Chunk) error, logger logging.Logger) *PushSync {
+func New(address swarm.Address, streamer p2p.Streamer, storer storage.Putter, topologyDriver topology.Driver, tagger *tags.Tags, deliveryCallback func(context.Context, swarm.Chunk) error, signer crypto.Signer, networkID uint64, logger logging.Logger) *PushSync {
 	ps := &PushSync{
+		address:          address,
 		streamer:         streamer,
 		storer:           storer,
-		peerSuggester:    closestPeerer,
+		topologyDriver:   topologyDriver,
 		tagg:             tagger,
 		deliveryCallback: deliveryCallback,
+		signer:           signer,
+		networkID:        networkID,
 		logger:           logger,
 		metrics:          newMetrics(),
 	}
@@ -91,7 +111,7 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	}
 
 	// Select the closest peer to forward the chunk
-	peer, err := ps.peerSuggester.ClosestPeer(chunk.Address())
+	peer, err := ps.topologyDriver.ClosestPeer(chunk.Address())
 	if err != nil {
 		// If i am the closest peer then store the chunk and send receipt
 		if errors.Is(err, topology.ErrWantSelf) {
@@ -121,12 +141,14 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 
 	wc, rc := protobuf.NewWriterAndReader(streamer)
 	if err := ps.sendChunkDelivery(wc, chunk); err != nil {
+		ps.record(peer, reputation.EventTimeout)
 		return fmt.Errorf("forward chunk to peer %s: %w", peer.String(), err)
 	}
 	receiptRTTTimer := time.Now()
 
 	receipt, err := ps.receiveReceipt(rc)
 	if err != nil {
+		ps.record(peer, reputation.EventTimeout)
 		return fmt.Errorf("receive receipt from peer %s: %w", peer.String(), err)
 	}
 	ps.metrics.ReceiptRTT.Observe(time.Since(receiptRTTTimer).Seconds())
@@ -134,8 +156,10 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	// Check if the receipt is valid
 	if !chunk.Address().Equal(swarm.NewAddress(receipt.Address)) {
 		ps.metrics.InvalidReceiptReceived.Inc()
+		ps.record(peer, reputation.EventInvalidData)
 		return fmt.Errorf("invalid receipt from peer %s", peer.String())
 	}
+	ps.record(peer, reputation.EventSuccess)
 
 	// pass back the received receipt in the previously received stream
 	err = ps.sendReceipt(w, &receipt)
@@ -197,7 +221,7 @@ func (ps *PushSync) receiveReceipt(r protobuf.Reader) (receipt pb.Receipt, err e
 // a receipt from that peer and returns error or nil based on the receiving and
 // the validity of the receipt.
 func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Receipt, error) {
-	peer, err := ps.peerSuggester.ClosestPeer(ch.Address())
+	peer, err := ps.topologyDriver.ClosestPeer(ch.Address())
 	if err != nil {
 		if errors.Is(err, topology.ErrWantSelf) {
 			// this is to make sure that the sent number does not diverge from the synced counter
@@ -207,8 +231,14 @@ func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Re
 			}
 
 			// if you are the closest node return a receipt immediately
+			signature, err := ps.signReceipt(ch.Address())
+			if err != nil {
+				return nil, fmt.Errorf("sign receipt: %w", err)
+			}
 			return &Receipt{
-				Address: ch.Address(),
+				Address:   ch.Address(),
+				Storer:    ps.address,
+				Signature: signature,
 			}, nil
 		}
 		return nil, fmt.Errorf("closest peer: %w", err)
@@ -223,6 +253,7 @@ func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Re
 	w, r := protobuf.NewWriterAndReader(streamer)
 	if err := ps.sendChunkDelivery(w, ch); err != nil {
 		_ = streamer.Reset()
+		ps.record(peer, reputation.EventTimeout)
 		return nil, fmt.Errorf("chunk deliver to peer %s: %w", peer.String(), err)
 	}
 
@@ -236,24 +267,99 @@ func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Re
 	receipt, err := ps.receiveReceipt(r)
 	if err != nil {
 		_ = streamer.Reset()
+		ps.record(peer, reputation.EventTimeout)
 		return nil, fmt.Errorf("receive receipt from peer %s: %w", peer.String(), err)
 	}
 	ps.metrics.ReceiptRTT.Observe(time.Since(receiptRTTTimer).Seconds())
 
-	// Check if the receipt is valid
-	if !ch.Address().Equal(swarm.NewAddress(receipt.Address)) {
+	// Check if the receipt is valid and signed by a node in the chunk neighbourhood
+	storer, err := ps.verifyReceipt(ch.Address(), peer, &receipt)
+	if err != nil {
 		ps.metrics.InvalidReceiptReceived.Inc()
 		_ = streamer.Reset()
-		return nil, fmt.Errorf("invalid receipt. peer %s", peer.String())
+		ps.record(peer, reputation.EventInvalidData)
+		return nil, fmt.Errorf("peer %s: %w", peer.String(), err)
 	}
+	ps.record(peer, reputation.EventSuccess)
 
 	rec := &Receipt{
-		Address: swarm.NewAddress(receipt.Address),
+		Address:   swarm.NewAddress(receipt.Address),
+		Storer:    storer,
+		Signature: receipt.Signature,
 	}
 
 	return rec, nil
 }
 
+// signReceipt signs the receipt for the chunk stored by this node.
+func (ps *PushSync) signReceipt(chunkAddress swarm.Address) ([]byte, error) {
+	digest, err := receiptSignDigest(chunkAddress.Bytes(), ps.address.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return ps.signer.Sign(digest)
+}
+
+// verifyReceipt checks that the receipt is issued for the chunk, that it is
+// signed by the node whose overlay address is in the receipt and that this
+// node is within the neighbourhood of the chunk. The storer is in the
+// neighbourhood if it is not farther from the chunk than the closest peer
+// known to this node, which the chunk was pushed to, so that the check does
+// not depend on how dense the network is around this node. It returns the
+// overlay address of the storer.
+func (ps *PushSync) verifyReceipt(chunkAddress, closestPeer swarm.Address, receipt *pb.Receipt) (swarm.Address, error) {
+	if !chunkAddress.Equal(swarm.NewAddress(receipt.Address)) {
+		return swarm.ZeroAddress, fmt.Errorf("chunk address mismatch: %w", ErrInvalidReceipt)
+	}
+
+	digest, err := receiptSignDigest(receipt.Address, receipt.Storer)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	publicKey, err := crypto.Recover(receipt.Signature, digest)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("recover signer: %w", ErrInvalidReceipt)
+	}
+	storer, err := crypto.NewOverlayAddress(*publicKey, ps.networkID)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("signer overlay: %w", ErrInvalidReceipt)
+	}
+	if !storer.Equal(swarm.NewAddress(receipt.Storer)) {
+		return swarm.ZeroAddress, fmt.Errorf("storer %s is not the signer: %w", swarm.NewAddress(receipt.Storer), ErrInvalidReceipt)
+	}
+
+	if swarm.Proximity(storer.Bytes(), chunkAddress.Bytes()) < swarm.Proximity(closestPeer.Bytes(), chunkAddress.Bytes()) {
+		return swarm.ZeroAddress, fmt.Errorf("storer %s outside of chunk neighbourhood: %w", storer, ErrInvalidReceipt)
+	}
+
+	return storer, nil
+}
+
+// receiptSignDigest creates a digest suitable for signing to represent the
+// receipt for a chunk stored by the storer node.
+func receiptSignDigest(chunkAddress, storer []byte) ([]byte, error) {
+	h := swarm.NewHasher()
+	if _, err := h.Write(chunkAddress); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(storer); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// SetReputation sets the recorder of peer behaviour. This call is not
+// goroutine safe.
+func (ps *PushSync) SetReputation(r reputation.Recorder) {
+	ps.reputation = r
+}
+
+func (ps *PushSync) record(peer swarm.Address, e reputation.Event) {
+	if ps.reputation != nil {
+		ps.reputation.Record(peer, e)
+	}
+}
+
 func (ps *PushSync) deliverToPSS(ctx context.Context, ch swarm.Chunk) error {
 	// if callback is defined, call it for every new, valid chunk
 	if ps.deliveryCallback != nil {
@@ -270,8 +376,16 @@ func (ps *PushSync) handleDeliveryResponse(ctx context.Context, w protobuf.Write
 	}
 	ps.metrics.TotalChunksStoredInDB.Inc()
 
-	// Send a receipt immediately once the storage of the chunk is successfully
-	receipt := &pb.Receipt{Address: chunk.Address().Bytes()}
+	// Send a signed receipt immediately once the storage of the chunk is successfully
+	signature, err := ps.signReceipt(chunk.Address())
+	if err != nil {
+		return fmt.Errorf("sign receipt: %w", err)
+	}
+	receipt := &pb.Receipt{
+		Address:   chunk.Address().Bytes(),
+		Storer:    ps.address.Bytes(),
+		Signature: signature,
+	}
 	err = ps.sendReceipt(w, receipt)
 	if err != nil {
 		return fmt.Errorf("send receipt to peer %s: %w", p.Address.String(), err)
@@ -7,22 +7,28 @@ package pushsync_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/pushsync/pb"
+	"github.com/ethersphere/bee/pkg/reputation"
+	reputationmock "github.com/ethersphere/bee/pkg/reputation/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/topology/mock"
 )
 
+const networkID = 1
+
 // TestSendChunkAndGetReceipt inserts a chunk as uploaded chunk in db. This triggers sending a chunk to the closest node
 // and expects a receipt. The message are intercepted in the outgoing stream to check for correctness.
 func TestSendChunkAndReceiveReceipt(t *testing.T) {
@@ -32,19 +38,19 @@ func TestSendChunkAndReceiveReceipt(t *testing.T) {
 	chunk := swarm.NewChunk(chunkAddress, chunkData)
 
 	// create a pivot node and a mocked closest node
-	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
-	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+	pivotNode, pivotSigner := newTestNode(t)
+	closestPeer, closestSigner := newTestNode(t)
 
 	// peer is the node responding to the chunk receipt message
 	// mock should return ErrWantSelf since there's no one to forward to
-	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, closestSigner, nil, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
 	defer storerPeer.Close()
 
 	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
 
 	// pivot node needs the streamer since the chunk is intercepted by
 	// the chunk worker, then gets sent by opening a new stream
-	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, recorder, nil, mock.WithClosestPeer(closestPeer))
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, pivotSigner, recorder, nil, mock.WithClosestPeer(closestPeer))
 	defer storerPivot.Close()
 
 	// Trigger the sending of chunk to the closest node
@@ -57,6 +63,10 @@ func TestSendChunkAndReceiveReceipt(t *testing.T) {
 		t.Fatal("invalid receipt")
 	}
 
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+
 	// this intercepts the outgoing delivery message
 	waitOnRecordAndTest(t, closestPeer, recorder, chunkAddress, chunkData)
 
@@ -73,19 +83,19 @@ func TestPushChunkToClosest(t *testing.T) {
 	chunkData := []byte("1234")
 
 	// create a pivot node and a mocked closest node
-	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
-	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+	pivotNode, pivotSigner := newTestNode(t)
+	closestPeer, closestSigner := newTestNode(t)
 
 	// peer is the node responding to the chunk receipt message
 	// mock should return ErrWantSelf since there's no one to forward to
-	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, nil, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, closestSigner, nil, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
 	defer storerPeer.Close()
 
 	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
 
 	// pivot node needs the streamer since the chunk is intercepted by
 	// the chunk worker, then gets sent by opening a new stream
-	psPivot, storerPivot, pivotTags := createPushSyncNode(t, pivotNode, recorder, nil, mock.WithClosestPeer(closestPeer))
+	psPivot, storerPivot, pivotTags := createPushSyncNode(t, pivotNode, pivotSigner, recorder, nil, mock.WithClosestPeer(closestPeer))
 	defer storerPivot.Close()
 
 	ta, err := pivotTags.Create("test", 1, false)
@@ -142,9 +152,9 @@ func TestHandler(t *testing.T) {
 	chunk := swarm.NewChunk(chunkAddress, chunkData)
 
 	// create a pivot node and a mocked closest node
-	pivotPeer := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
-	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
-	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+	pivotPeer, pivotSigner := newTestNode(t)
+	triggerPeer, triggerSigner := newTestNode(t)
+	closestPeer, closestSigner := newTestNode(t)
 
 	// mock call back function to see if pss message is delivered when it is received in the destination (closestPeer in this testcase)
 	hookWasCalled := make(chan bool, 1) // channel to check if hook is called
@@ -154,19 +164,19 @@ func TestHandler(t *testing.T) {
 	}
 
 	// Create the closest peer
-	psClosestPeer, closestStorerPeerDB, _ := createPushSyncNode(t, closestPeer, nil, pssDeliver, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	psClosestPeer, closestStorerPeerDB, _ := createPushSyncNode(t, closestPeer, closestSigner, nil, pssDeliver, mock.WithClosestPeerErr(topology.ErrWantSelf))
 	defer closestStorerPeerDB.Close()
 
 	closestRecorder := streamtest.New(streamtest.WithProtocols(psClosestPeer.Protocol()))
 
 	// creating the pivot peer
-	psPivot, storerPivotDB, _ := createPushSyncNode(t, pivotPeer, closestRecorder, nil, mock.WithClosestPeer(closestPeer))
+	psPivot, storerPivotDB, _ := createPushSyncNode(t, pivotPeer, pivotSigner, closestRecorder, nil, mock.WithClosestPeer(closestPeer))
 	defer storerPivotDB.Close()
 
 	pivotRecorder := streamtest.New(streamtest.WithProtocols(psPivot.Protocol()))
 
 	// Creating the trigger peer
-	psTriggerPeer, triggerStorerDB, _ := createPushSyncNode(t, triggerPeer, pivotRecorder, nil, mock.WithClosestPeer(pivotPeer))
+	psTriggerPeer, triggerStorerDB, _ := createPushSyncNode(t, triggerPeer, triggerSigner, pivotRecorder, nil, mock.WithClosestPeer(pivotPeer))
 	defer triggerStorerDB.Close()
 
 	receipt, err := psTriggerPeer.PushChunkToClosest(context.Background(), chunk)
@@ -178,6 +188,10 @@ func TestHandler(t *testing.T) {
 		t.Fatal("invalid receipt")
 	}
 
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+
 	// In pivot peer,  intercept the incoming delivery chunk from the trigger peer and check for correctness
 	waitOnRecordAndTest(t, pivotPeer, pivotRecorder, chunkAddress, chunkData)
 
@@ -200,7 +214,99 @@ func TestHandler(t *testing.T) {
 	}
 }
 
-func createPushSyncNode(t *testing.T, addr swarm.Address, recorder *streamtest.Recorder, pssDeliver func(context.Context, swarm.Chunk) error, mockOpts ...mock.Option) (*pushsync.PushSync, *localstore.DB, *tags.Tags) {
+// TestInvalidReceipt checks that receipts which are not signed by the claimed
+// storer, or are signed by a storer farther from the chunk than the closest
+// peer of the origin, are rejected by the origin of the chunk.
+func TestInvalidReceipt(t *testing.T) {
+	chunk := swarm.NewChunk(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), []byte("1234"))
+
+	pivotNode, pivotSigner := newTestNode(t)
+	closestPeer, closestSigner := newTestNode(t)
+
+	for _, tc := range []struct {
+		name    string
+		storer  swarm.Address
+		closest swarm.Address // closest peer known to the pivot
+	}{
+		{
+			name:    "forged storer",
+			storer:  swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000"),
+			closest: swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000"),
+		},
+		{
+			name:    "outside of neighbourhood",
+			storer:  closestPeer,
+			closest: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000001"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// the storer signs with a key that may not belong to its overlay address
+			psPeer, storerPeer, _ := createPushSyncNode(t, tc.storer, closestSigner, nil, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+			defer storerPeer.Close()
+
+			recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+			psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, pivotSigner, recorder, nil, mock.WithClosestPeer(tc.closest))
+			defer storerPivot.Close()
+
+			rep := reputationmock.NewReputation()
+			psPivot.SetReputation(rep)
+
+			_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+			if !errors.Is(err, pushsync.ErrInvalidReceipt) {
+				t.Fatalf("got error %v, want %v", err, pushsync.ErrInvalidReceipt)
+			}
+
+			if events := rep.Events(tc.closest); len(events) != 1 || events[0] != reputation.EventInvalidData {
+				t.Fatalf("got reputation events %v, want [%v]", events, reputation.EventInvalidData)
+			}
+		})
+	}
+}
+
+// TestReceiptOriginDepth checks that the receipt of the closest peer is
+// accepted even if the neighbourhood depth of the origin is deeper than the
+// proximity of the peer to the chunk.
+func TestReceiptOriginDepth(t *testing.T) {
+	chunk := swarm.NewChunk(swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), []byte("1234"))
+
+	pivotNode, pivotSigner := newTestNode(t)
+	closestPeer, closestSigner := newTestNode(t)
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestPeer, closestSigner, nil, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotNode, pivotSigner, recorder, nil, mock.WithClosestPeer(closestPeer), mock.WithNeighborhoodDepth(swarm.MaxPO))
+	defer storerPivot.Close()
+
+	receipt, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Storer.Equal(closestPeer) {
+		t.Fatalf("got storer %s, want %s", receipt.Storer, closestPeer)
+	}
+}
+
+// newTestNode generates a new key and returns the signer and the overlay
+// address derived from it.
+func newTestNode(t *testing.T) (swarm.Address, crypto.Signer) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := crypto.NewOverlayAddress(key.PublicKey, networkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return overlay, crypto.NewDefaultSigner(key)
+}
+
+func createPushSyncNode(t *testing.T, addr swarm.Address, signer crypto.Signer, recorder *streamtest.Recorder, pssDeliver func(context.Context, swarm.Chunk) error, mockOpts ...mock.Option) (*pushsync.PushSync, *localstore.DB, *tags.Tags) {
 	logger := logging.New(ioutil.Discard, 0)
 
 	storer, err := localstore.New("", addr.Bytes(), nil, logger)
@@ -210,7 +316,7 @@ func createPushSyncNode(t *testing.T, addr swarm.Address, recorder *streamtest.R
 
 	mockTopology := mock.NewTopologyDriver(mockOpts...)
 	mtag := tags.NewTags()
-	return pushsync.New(recorder, storer, mockTopology, mtag, pssDeliver, logger), storer, mtag
+	return pushsync.New(addr, recorder, storer, mockTopology, mtag, pssDeliver, signer, networkID, logger), storer, mtag
 }
 
 func waitOnRecordAndTest(t *testing.T, peer swarm.Address, recorder *streamtest.Recorder, add swarm.Address, data []byte) {
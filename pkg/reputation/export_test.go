@@ -0,0 +1,17 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reputation
+
+import "time"
+
+func SetTimeNow(f func() time.Time) {
+	timeNow = f
+}
+
+func (s *Service) ScoresCount() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.scores)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/reputation"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ reputation.Interface = (*Service)(nil)
+
+// Service is the mock reputation service that keeps recorded events and
+// returns preset scores.
+type Service struct {
+	scores  map[string]float64
+	skipped map[string]bool
+	events  map[string][]reputation.Event
+	mtx     sync.Mutex
+}
+
+// WithScore sets the score of the peer.
+func WithScore(peer swarm.Address, score float64) Option {
+	return optionFunc(func(s *Service) {
+		s.scores[peer.ByteString()] = score
+	})
+}
+
+// WithSkipped marks peers as skipped.
+func WithSkipped(peers ...swarm.Address) Option {
+	return optionFunc(func(s *Service) {
+		for _, p := range peers {
+			s.skipped[p.ByteString()] = true
+		}
+	})
+}
+
+// NewReputation creates the mock reputation service.
+func NewReputation(opts ...Option) *Service {
+	s := &Service{
+		scores:  make(map[string]float64),
+		skipped: make(map[string]bool),
+		events:  make(map[string][]reputation.Event),
+	}
+	for _, o := range opts {
+		o.apply(s)
+	}
+	return s
+}
+
+func (s *Service) Record(peer swarm.Address, e reputation.Event) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.events[peer.ByteString()] = append(s.events[peer.ByteString()], e)
+}
+
+// Events returns events recorded for the peer.
+func (s *Service) Events(peer swarm.Address) []reputation.Event {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return append([]reputation.Event(nil), s.events[peer.ByteString()]...)
+}
+
+func (s *Service) Score(peer swarm.Address) float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.scores[peer.ByteString()]
+}
+
+func (s *Service) Skipped(peer swarm.Address) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.skipped[peer.ByteString()]
+}
+
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(s *Service) { f(s) }
@@ -0,0 +1,248 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reputation scores peers by their behaviour. Protocols record events
+// such as timeouts or invalid chunks, and the topology uses the scores to
+// deprioritise or temporarily skip misbehaving peers. Peers with persistently
+// low scores are disconnected.
+package reputation
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Event is a peer behaviour that changes its score.
+type Event int
+
+const (
+	// EventSuccess is recorded when the peer delivers a valid chunk or a
+	// valid receipt.
+	EventSuccess Event = iota
+	// EventTimeout is recorded when the peer does not respond in time or
+	// the stream to the peer fails.
+	EventTimeout
+	// EventInvalidData is recorded when the peer sends an invalid chunk or
+	// an invalid receipt.
+	EventInvalidData
+	// EventConnectionFailed is recorded when connecting or handshaking with
+	// the peer fails.
+	EventConnectionFailed
+)
+
+func (e Event) String() string {
+	switch e {
+	case EventSuccess:
+		return "success"
+	case EventTimeout:
+		return "timeout"
+	case EventInvalidData:
+		return "invalid data"
+	case EventConnectionFailed:
+		return "connection failed"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	maxScore = 100
+	minScore = -100
+
+	// defaults
+	skipThreshold       = -20
+	disconnectThreshold = -50
+	halfLife            = 30 * time.Minute
+
+	// scorePerProximityOrder is the negative score for which a peer is
+	// considered one proximity order farther when choosing between peers.
+	scorePerProximityOrder = 10
+
+	// scoreEpsilon is the absolute score value below which the score is
+	// considered decayed to zero and it is removed.
+	scoreEpsilon = 0.01
+)
+
+var (
+	_ Interface = (*Service)(nil)
+
+	// timeNow is used to deterministically mock time.Now() in tests.
+	timeNow = time.Now
+
+	// score changes for events
+	eventScores = map[Event]float64{
+		EventSuccess:          1,
+		EventTimeout:          -5,
+		EventInvalidData:      -25,
+		EventConnectionFailed: -10,
+	}
+)
+
+// Recorder records peer behaviour.
+type Recorder interface {
+	Record(peer swarm.Address, e Event)
+}
+
+// Interface provides peer scores.
+type Interface interface {
+	Recorder
+	// Score returns the current score of the peer. Peers without recorded
+	// events have the score of zero.
+	Score(peer swarm.Address) float64
+	// Skipped reports whether the peer score is low enough for the peer to
+	// be temporarily excluded from routing.
+	Skipped(peer swarm.Address) bool
+}
+
+// Penalty returns the number of proximity orders by which a peer with the
+// score is considered farther from an address when peers are compared by
+// their proximity. Peers with non-negative scores are not penalised.
+func Penalty(score float64) int {
+	if score >= 0 {
+		return 0
+	}
+	return int(-score / scorePerProximityOrder)
+}
+
+// Disconnecter disconnects peers.
+type Disconnecter interface {
+	Disconnect(overlay swarm.Address) error
+}
+
+// Options for the reputation Service.
+type Options struct {
+	// SkipThreshold is the score below which peers are skipped.
+	SkipThreshold float64
+	// DisconnectThreshold is the score below which peers are disconnected.
+	DisconnectThreshold float64
+	// HalfLife is the duration after which a score is halved, so that
+	// peers recover from past behaviour.
+	HalfLife time.Duration
+}
+
+// Service keeps peer scores in memory.
+type Service struct {
+	disconnecter        Disconnecter
+	skipThreshold       float64
+	disconnectThreshold float64
+	halfLife            time.Duration
+	logger              logging.Logger
+
+	scores map[string]*score // key is the peer overlay address byte string
+	pruned time.Time         // when the decayed scores were last removed
+	mtx    sync.Mutex
+}
+
+type score struct {
+	value   float64
+	updated time.Time
+}
+
+// New creates a new reputation Service. Peers whose score drops below the
+// disconnect threshold are disconnected using the disconnecter.
+func New(disconnecter Disconnecter, logger logging.Logger, o Options) *Service {
+	if o.SkipThreshold == 0 {
+		o.SkipThreshold = skipThreshold
+	}
+	if o.DisconnectThreshold == 0 {
+		o.DisconnectThreshold = disconnectThreshold
+	}
+	if o.HalfLife == 0 {
+		o.HalfLife = halfLife
+	}
+
+	return &Service{
+		disconnecter:        disconnecter,
+		skipThreshold:       o.SkipThreshold,
+		disconnectThreshold: o.DisconnectThreshold,
+		halfLife:            o.HalfLife,
+		logger:              logger,
+		scores:              make(map[string]*score),
+		pruned:              timeNow(),
+	}
+}
+
+// Record changes the peer score for the event and disconnects the peer if
+// its score drops below the disconnect threshold.
+func (s *Service) Record(peer swarm.Address, e Event) {
+	s.mtx.Lock()
+	now := timeNow()
+	sc, ok := s.scores[peer.ByteString()]
+	if !ok {
+		sc = &score{updated: now}
+		s.scores[peer.ByteString()] = sc
+	}
+	value := s.decay(sc, now) + eventScores[e]
+	if value > maxScore {
+		value = maxScore
+	}
+	if value < minScore {
+		value = minScore
+	}
+	sc.value = value
+	sc.updated = now
+	if now.Sub(s.pruned) >= s.halfLife {
+		s.prune(now)
+	}
+	s.mtx.Unlock()
+
+	if e != EventSuccess {
+		s.logger.Tracef("reputation: peer %s: %s: score %.2f", peer, e, value)
+	}
+
+	if value < s.disconnectThreshold && s.disconnecter != nil {
+		s.logger.Debugf("reputation: disconnecting peer %s with score %.2f", peer, value)
+		if err := s.disconnecter.Disconnect(peer); err != nil {
+			s.logger.Debugf("reputation: disconnect peer %s: %v", peer, err)
+		}
+	}
+}
+
+// Score returns the current score of the peer.
+func (s *Service) Score(peer swarm.Address) float64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	sc, ok := s.scores[peer.ByteString()]
+	if !ok {
+		return 0
+	}
+	value := s.decay(sc, timeNow())
+	if math.Abs(value) < scoreEpsilon {
+		delete(s.scores, peer.ByteString())
+		return 0
+	}
+	return value
+}
+
+// Skipped reports whether the peer score is below the skip threshold.
+func (s *Service) Skipped(peer swarm.Address) bool {
+	return s.Score(peer) < s.skipThreshold
+}
+
+// decay returns the score value reduced by the time passed since its last
+// update. It must be called with the lock held.
+func (s *Service) decay(sc *score, now time.Time) float64 {
+	elapsed := now.Sub(sc.updated)
+	if elapsed <= 0 {
+		return sc.value
+	}
+	return sc.value * math.Pow(0.5, float64(elapsed)/float64(s.halfLife))
+}
+
+// prune removes the scores that decayed to zero, so that the scores of peers
+// which are not seen any more are not kept forever. It must be called with
+// the lock held.
+func (s *Service) prune(now time.Time) {
+	for k, sc := range s.scores {
+		if math.Abs(s.decay(sc, now)) < scoreEpsilon {
+			delete(s.scores, k)
+		}
+	}
+	s.pruned = now
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reputation_test
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/reputation"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/swarm/test"
+)
+
+type disconnecter struct {
+	disconnected []swarm.Address
+}
+
+func (d *disconnecter) Disconnect(overlay swarm.Address) error {
+	d.disconnected = append(d.disconnected, overlay)
+	return nil
+}
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	reputation.SetTimeNow(func() time.Time { return now })
+	defer reputation.SetTimeNow(time.Now)
+
+	d := new(disconnecter)
+	s := reputation.New(d, logging.New(ioutil.Discard, 0), reputation.Options{
+		HalfLife: time.Minute,
+	})
+
+	peer := test.RandomAddress()
+
+	if score := s.Score(peer); score != 0 {
+		t.Fatalf("got score %v, want 0", score)
+	}
+
+	s.Record(peer, reputation.EventSuccess)
+	s.Record(peer, reputation.EventSuccess)
+	if score := s.Score(peer); score != 2 {
+		t.Fatalf("got score %v, want 2", score)
+	}
+
+	s.Record(peer, reputation.EventInvalidData)
+	if score := s.Score(peer); score != -23 {
+		t.Fatalf("got score %v, want -23", score)
+	}
+	if !s.Skipped(peer) {
+		t.Fatal("peer is not skipped")
+	}
+	if len(d.disconnected) != 0 {
+		t.Fatalf("got %d disconnects, want 0", len(d.disconnected))
+	}
+
+	// score recovers with time
+	now = now.Add(time.Minute)
+	if score := s.Score(peer); score != -11.5 {
+		t.Fatalf("got score %v, want -11.5", score)
+	}
+	if s.Skipped(peer) {
+		t.Fatal("peer is skipped")
+	}
+
+	s.Record(peer, reputation.EventInvalidData)
+	s.Record(peer, reputation.EventTimeout)
+	s.Record(peer, reputation.EventConnectionFailed)
+	if len(d.disconnected) != 1 || !d.disconnected[0].Equal(peer) {
+		t.Fatalf("got disconnected %v, want %v", d.disconnected, peer)
+	}
+
+	// score is limited
+	for i := 0; i < 10; i++ {
+		s.Record(peer, reputation.EventInvalidData)
+	}
+	if score := s.Score(peer); score != -100 {
+		t.Fatalf("got score %v, want -100", score)
+	}
+}
+
+// TestScorePrune validates that the scores which decayed to zero are removed.
+func TestScorePrune(t *testing.T) {
+	now := time.Now()
+	reputation.SetTimeNow(func() time.Time { return now })
+	defer reputation.SetTimeNow(time.Now)
+
+	s := reputation.New(nil, logging.New(ioutil.Discard, 0), reputation.Options{
+		HalfLife: time.Minute,
+	})
+
+	peers := []swarm.Address{test.RandomAddress(), test.RandomAddress(), test.RandomAddress()}
+	for _, p := range peers {
+		s.Record(p, reputation.EventTimeout)
+	}
+	if got := s.ScoresCount(); got != len(peers) {
+		t.Fatalf("got %d scores, want %d", got, len(peers))
+	}
+
+	// decayed score is removed when it is read
+	now = now.Add(10 * time.Minute)
+	if score := s.Score(peers[0]); score != 0 {
+		t.Fatalf("got score %v, want 0", score)
+	}
+	if got := s.ScoresCount(); got != len(peers)-1 {
+		t.Fatalf("got %d scores, want %d", got, len(peers)-1)
+	}
+
+	// decayed scores are removed when any event is recorded
+	peer := test.RandomAddress()
+	s.Record(peer, reputation.EventSuccess)
+	if got := s.ScoresCount(); got != 1 {
+		t.Fatalf("got %d scores, want 1", got)
+	}
+	if score := s.Score(peer); score != 1 {
+		t.Fatalf("got score %v, want 1", score)
+	}
+}
+
+func TestPenalty(t *testing.T) {
+	for _, tc := range []struct {
+		score float64
+		want  int
+	}{
+		{score: 10, want: 0},
+		{score: 0, want: 0},
+		{score: -9.5, want: 0},
+		{score: -10, want: 1},
+		{score: -25, want: 2},
+	} {
+		if got := reputation.Penalty(tc.score); got != tc.want {
+			t.Errorf("score %v: got penalty %d, want %d", tc.score, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retrieval
+
+import "github.com/ethersphere/bee/pkg/swarm"
+
+func (s *Service) ClosestPeer(addr swarm.Address, skipPeers []swarm.Address) (swarm.Address, error) {
+	return s.closestPeer(addr, skipPeers)
+}
@@ -6,6 +6,7 @@ package retrieval
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/reputation"
 	pb "github.com/ethersphere/bee/pkg/retrieval/pb"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -43,6 +46,9 @@ type Service struct {
 	accounting    accounting.Interface
 	pricer        accounting.Pricer
 	validator     swarm.Validator
+	reputation    reputation.Interface
+	latency       pingpong.LatencyReporter
+	depther       topology.NeighborhoodDepther
 }
 
 func New(streamer p2p.Streamer, chunkPeerer topology.EachPeerer, logger logging.Logger, accounting accounting.Interface, pricer accounting.Pricer, validator swarm.Validator) *Service {
@@ -151,14 +157,19 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 
 	var d pb.Delivery
 	if err := r.ReadMsgWithContext(ctx, &d); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			s.record(peer, reputation.EventTimeout)
+		}
 		return nil, peer, fmt.Errorf("read delivery: %w peer %s", err, peer.String())
 	}
 
 	// credit the peer after successful delivery
 	chunk = swarm.NewChunk(addr, d.Data)
 	if !s.validator.Validate(chunk) {
-		return nil, peer, err
+		s.record(peer, reputation.EventInvalidData)
+		return nil, peer, fmt.Errorf("%w peer %s", storage.ErrInvalidChunk, peer.String())
 	}
+	s.record(peer, reputation.EventSuccess)
 
 	err = s.accounting.Credit(peer, chunkPrice)
 	if err != nil {
@@ -168,7 +179,14 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 	return chunk, peer, err
 }
 
+// closestPeer returns the closest peer to the address that is not skipped.
+// Peers with a negative reputation score are considered farther from the
+// address by their reputation penalty.
 func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address) (swarm.Address, error) {
+	var depth uint8
+	if s.depther != nil {
+		depth = s.depther.NeighborhoodDepth()
+	}
 	closest := swarm.Address{}
 	err := s.peerSuggester.EachPeerRev(func(peer swarm.Address, po uint8) (bool, bool, error) {
 		for _, a := range skipPeers {
@@ -176,11 +194,14 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address) (sw
 				return false, false, nil
 			}
 		}
+		if s.reputation != nil && s.reputation.Skipped(peer) {
+			return false, false, nil
+		}
 		if closest.IsZero() {
 			closest = peer
 			return false, false, nil
 		}
-		dcmp, err := swarm.DistanceCmp(addr.Bytes(), closest.Bytes(), peer.Bytes())
+		dcmp, err := s.closerCmp(addr, closest, peer, depth)
 		if err != nil {
 			return false, false, fmt.Errorf("distance compare error. addr %s closest %s peer %s: %w", addr.String(), closest.String(), peer.String(), err)
 		}
@@ -188,7 +209,8 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address) (sw
 		case 0:
 			// do nothing
 		case -1:
-			// current peer is closer
+			// current peer is closer, has a better score or is
+			// equally close and faster
 			closest = peer
 		case 1:
 			// closest is already closer to chunk
@@ -208,6 +230,62 @@ func (s *Service) closestPeer(addr swarm.Address, skipPeers []swarm.Address) (sw
 	return closest, nil
 }
 
+// closerCmp compares peers x and y by their proximity to the address reduced
+// by their reputation penalty, then by their reputation penalty, latency and
+// distance to the address. It returns 1 if x is preferred, -1 if y is
+// preferred and 0 if they are the same.
+func (s *Service) closerCmp(addr, x, y swarm.Address, depth uint8) (int, error) {
+	if s.reputation != nil {
+		penaltyX := reputation.Penalty(s.reputation.Score(x))
+		penaltyY := reputation.Penalty(s.reputation.Score(y))
+		px := int(swarm.Proximity(addr.Bytes(), x.Bytes())) - penaltyX
+		py := int(swarm.Proximity(addr.Bytes(), y.Bytes())) - penaltyY
+		switch {
+		case px > py:
+			return 1, nil
+		case px < py:
+			return -1, nil
+		case penaltyX < penaltyY:
+			return 1, nil
+		case penaltyX > penaltyY:
+			return -1, nil
+		}
+	}
+	if dcmp, ok := s.latencyCmp(addr, x, y, depth); ok {
+		return dcmp, nil
+	}
+	return swarm.DistanceCmp(addr.Bytes(), x.Bytes(), y.Bytes())
+}
+
+// latencyCmp compares peers x and y by their latency, if they have the same
+// proximity order to the address and the address is outside of the
+// neighborhood. It returns 1 if x has the lower latency and -1 if y has. The
+// result is not ok if the peers can not be compared.
+func (s *Service) latencyCmp(addr, x, y swarm.Address, depth uint8) (int, bool) {
+	if s.latency == nil {
+		return 0, false
+	}
+	po := swarm.Proximity(addr.Bytes(), x.Bytes())
+	if po >= depth || po != swarm.Proximity(addr.Bytes(), y.Bytes()) {
+		return 0, false
+	}
+	lx, ok := s.latency.Latency(x)
+	if !ok {
+		return 0, false
+	}
+	ly, ok := s.latency.Latency(y)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case lx < ly:
+		return 1, true
+	case lx > ly:
+		return -1, true
+	}
+	return 0, false
+}
+
 func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (err error) {
 	w, r := protobuf.NewWriterAndReader(stream)
 	defer func() {
@@ -247,3 +325,23 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) (e
 func (s *Service) SetStorer(storer storage.Storer) {
 	s.storer = storer
 }
+
+// SetReputation sets the peer scores that are recorded and used to choose
+// peers. This call is not goroutine safe.
+func (s *Service) SetReputation(r reputation.Interface) {
+	s.reputation = r
+}
+
+// SetLatency sets the reporter of peer latencies that is used to choose
+// between equally close peers outside of the neighborhood, which depth is
+// provided by the depther. This call is not goroutine safe.
+func (s *Service) SetLatency(l pingpong.LatencyReporter, depther topology.NeighborhoodDepther) {
+	s.latency = l
+	s.depther = depther
+}
+
+func (s *Service) record(peer swarm.Address, e reputation.Event) {
+	if s.reputation != nil {
+		s.reputation.Record(peer, e)
+	}
+}
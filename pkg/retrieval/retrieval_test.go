@@ -18,6 +18,8 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/reputation"
+	reputationmock "github.com/ethersphere/bee/pkg/reputation/mock"
 	"github.com/ethersphere/bee/pkg/retrieval"
 	pb "github.com/ethersphere/bee/pkg/retrieval/pb"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -134,6 +136,122 @@ func TestDelivery(t *testing.T) {
 	}
 }
 
+// TestInvalidDelivery tests that an invalid chunk is not returned and that it
+// is recorded against the peer reputation.
+func TestInvalidDelivery(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	reqAddr := swarm.MustParseHexAddress("00112233")
+
+	mockStorer := storemock.NewStorer()
+	if _, err := mockStorer.Put(context.Background(), storage.ModePutUpload, swarm.NewChunk(reqAddr, []byte("data data data"))); err != nil {
+		t.Fatal(err)
+	}
+
+	pricerMock := accountingmock.NewPricer(10, 10)
+
+	server := retrieval.New(nil, nil, logger, accountingmock.NewAccounting(), pricerMock, swarm.NewChunkValidator(mock.NewValidator(true)))
+	server.SetStorer(mockStorer)
+	recorder := streamtest.New(
+		streamtest.WithProtocols(server.Protocol()),
+	)
+
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	ps := mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
+		_, _, _ = f(peerID, 0)
+		return nil
+	}}
+	client := retrieval.New(recorder, ps, logger, accountingmock.NewAccounting(), pricerMock, swarm.NewChunkValidator(mock.NewValidator(false)))
+	client.SetStorer(storemock.NewStorer())
+	rep := reputationmock.NewReputation()
+	client.SetReputation(rep)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if _, err := client.RetrieveChunk(ctx, reqAddr); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if events := rep.Events(peerID); len(events) != 1 || events[0] != reputation.EventInvalidData {
+		t.Fatalf("got reputation events %v, want [%v]", events, reputation.EventInvalidData)
+	}
+}
+
+// TestClosestPeerReputation checks that peers are chosen by their proximity
+// reduced by the reputation penalty and that skipped peers are not chosen.
+func TestClosestPeerReputation(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	var (
+		peer0 = swarm.MustParseHexAddress("8000000000000000000000000000000000000000000000000000000000000000") // binary 1000
+		peer1 = swarm.MustParseHexAddress("4000000000000000000000000000000000000000000000000000000000000000") // binary 0100
+		peer2 = swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110
+	)
+	ps := mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
+		for i, p := range []swarm.Address{peer0, peer1, peer2} {
+			if _, _, err := f(p, uint8(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+
+	for _, tc := range []struct {
+		name         string
+		reputation   []reputationmock.Option
+		chunkAddress swarm.Address
+		skipPeers    []swarm.Address
+		expectedPeer swarm.Address // zero address means not found
+	}{
+		{
+			name:         "closest",
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants peer 2
+			expectedPeer: peer2,
+		},
+		{
+			name:         "deprioritised",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer2, -10)},
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants peer 1 over peer 2
+			expectedPeer: peer1,
+		},
+		{
+			name:         "deprioritised much closer",
+			reputation:   []reputationmock.Option{reputationmock.WithScore(peer2, -10)},
+			chunkAddress: swarm.MustParseHexAddress("6800000000000000000000000000000000000000000000000000000000000000"), // 01101, wants peer 2 as it is still closer
+			expectedPeer: peer2,
+		},
+		{
+			name:         "skipped",
+			reputation:   []reputationmock.Option{reputationmock.WithSkipped(peer2)},
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"), // 0111, wants peer 1
+			expectedPeer: peer1,
+		},
+		{
+			name:         "all skipped",
+			reputation:   []reputationmock.Option{reputationmock.WithSkipped(peer0, peer1)},
+			chunkAddress: swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000"),
+			skipPeers:    []swarm.Address{peer2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := retrieval.New(nil, ps, logger, accountingmock.NewAccounting(), accountingmock.NewPricer(10, 10), nil)
+			s.SetReputation(reputationmock.NewReputation(tc.reputation...))
+
+			peer, err := s.ClosestPeer(tc.chunkAddress, tc.skipPeers)
+			if tc.expectedPeer.IsZero() {
+				if !errors.Is(err, topology.ErrNotFound) {
+					t.Fatalf("got error %v, want %v", err, topology.ErrNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !peer.Equal(tc.expectedPeer) {
+				t.Fatalf("got peer %s, want %s", peer, tc.expectedPeer)
+			}
+		})
+	}
+}
+
 type mockPeerSuggester struct {
 	eachPeerRevFunc func(f topology.EachPeerFunc) error
 }
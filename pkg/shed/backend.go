@@ -0,0 +1,88 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import "errors"
+
+var (
+	// ErrNotFound is returned by backends if the key is not found.
+	ErrNotFound = errors.New("shed: not found")
+	// ErrClosed is returned by backends if they are used after Close.
+	ErrClosed = errors.New("shed: closed")
+)
+
+// Backend is an ordered key-value store on which fields and indexes are
+// constructed. Keys are ordered lexicographically.
+type Backend interface {
+	Reader
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	// NewBatch returns an empty batch that can be written
+	// only by WriteBatch of the same backend.
+	NewBatch() Batch
+	// WriteBatch applies all batch operations atomically.
+	WriteBatch(batch Batch) error
+	// NewSnapshot returns a consistent read-only view of
+	// the current state. It must be released after use.
+	NewSnapshot() (Snapshot, error)
+	// Compact releases the space of deleted and overwritten keys.
+	Compact() error
+	Close() error
+}
+
+// Reader provides read access to a backend or its snapshot.
+type Reader interface {
+	// Get returns ErrNotFound if the key is not found.
+	Get(key []byte) (value []byte, err error)
+	Has(key []byte) (yes bool, err error)
+	// NewIterator returns an iterator over all keys that is not affected
+	// by subsequent writes. It must be released after use.
+	NewIterator() Iterator
+}
+
+// Snapshot is a read-only view of the backend at the time of its creation.
+type Snapshot interface {
+	Reader
+	Release()
+}
+
+// Batch collects write operations to be applied atomically.
+type Batch interface {
+	Put(key, value []byte)
+	Delete(key []byte)
+	// Len returns the number of operations in the batch.
+	Len() int
+	// Reset removes all operations from the batch.
+	Reset()
+}
+
+// Iterator iterates over ordered key-value pairs. Positioning methods
+// return false if the iterator is exhausted. Key and value slices are valid
+// only until the next positioning call.
+type Iterator interface {
+	First() bool
+	Last() bool
+	// Seek moves the iterator to the first key that is
+	// greater or equal to the provided key.
+	Seek(key []byte) bool
+	Next() bool
+	Prev() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Release()
+}
+
+// syncWriter is implemented by backends that can wait
+// until the batch is written to disk.
+type syncWriter interface {
+	WriteBatchSync(batch Batch) error
+}
+
+// sizer is implemented by backends that can report
+// their size on disk.
+type sizer interface {
+	Size() (size uint64, err error)
+}
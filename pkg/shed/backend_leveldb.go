@@ -0,0 +1,198 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var (
+	openFileLimit = 128 // The limit for LevelDB OpenFilesCacheCapacity.
+)
+
+var _ Backend = (*LevelDBBackend)(nil)
+
+// LevelDBBackend is a Backend that stores data in LevelDB files.
+type LevelDBBackend struct {
+	ldb  *leveldb.DB
+	path string
+}
+
+// NewLevelDBBackend opens or creates LevelDB database
+// in the directory on the provided path.
+func NewLevelDBBackend(path string) (*LevelDBBackend, error) {
+	ldb, err := leveldb.OpenFile(path, &opt.Options{
+		OpenFilesCacheCapacity: openFileLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LevelDBBackend{
+		ldb:  ldb,
+		path: path,
+	}, nil
+}
+
+// NewReadOnlyLevelDBBackend opens an existing LevelDB database in the
+// directory on the provided path without writing to it.
+func NewReadOnlyLevelDBBackend(path string) (*LevelDBBackend, error) {
+	ldb, err := leveldb.OpenFile(path, &opt.Options{
+		OpenFilesCacheCapacity: openFileLimit,
+		ReadOnly:               true,
+		ErrorIfMissing:         true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LevelDBBackend{
+		ldb:  ldb,
+		path: path,
+	}, nil
+}
+
+// Get returns the value for the key or ErrNotFound.
+func (b *LevelDBBackend) Get(key []byte) (value []byte, err error) {
+	value, err = b.ldb.Get(key, nil)
+	return value, levelDBError(err)
+}
+
+// Has returns true if the key is stored.
+func (b *LevelDBBackend) Has(key []byte) (yes bool, err error) {
+	yes, err = b.ldb.Has(key, nil)
+	return yes, levelDBError(err)
+}
+
+// Put stores the value for the key.
+func (b *LevelDBBackend) Put(key, value []byte) (err error) {
+	return levelDBError(b.ldb.Put(key, value, nil))
+}
+
+// Delete removes the key.
+func (b *LevelDBBackend) Delete(key []byte) (err error) {
+	return levelDBError(b.ldb.Delete(key, nil))
+}
+
+// NewIterator returns an iterator over all keys.
+func (b *LevelDBBackend) NewIterator() Iterator {
+	return levelDBIterator{b.ldb.NewIterator(nil, nil)}
+}
+
+// NewBatch returns a new LevelDB batch.
+func (b *LevelDBBackend) NewBatch() Batch {
+	return new(leveldb.Batch)
+}
+
+// WriteBatch writes the batch returned by NewBatch.
+func (b *LevelDBBackend) WriteBatch(batch Batch) (err error) {
+	lb, ok := batch.(*leveldb.Batch)
+	if !ok {
+		return fmt.Errorf("shed: unsupported batch type %T", batch)
+	}
+	return levelDBError(b.ldb.Write(lb, nil))
+}
+
+// WriteBatchSync writes the batch returned by NewBatch and waits until it
+// is written to disk.
+func (b *LevelDBBackend) WriteBatchSync(batch Batch) (err error) {
+	lb, ok := batch.(*leveldb.Batch)
+	if !ok {
+		return fmt.Errorf("shed: unsupported batch type %T", batch)
+	}
+	return levelDBError(b.ldb.Write(lb, &opt.WriteOptions{Sync: true}))
+}
+
+// NewSnapshot returns a LevelDB snapshot.
+func (b *LevelDBBackend) NewSnapshot() (Snapshot, error) {
+	s, err := b.ldb.GetSnapshot()
+	if err != nil {
+		return nil, levelDBError(err)
+	}
+	return levelDBSnapshot{s}, nil
+}
+
+// Compact compacts the whole LevelDB key range.
+func (b *LevelDBBackend) Compact() (err error) {
+	return levelDBError(b.ldb.CompactRange(util.Range{}))
+}
+
+// Size returns the size of all database files on disk in bytes, including
+// the journal and the manifest.
+func (b *LevelDBBackend) Size() (size uint64, err error) {
+	err = filepath.Walk(b.path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			// files may be removed by compaction during the walk
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Close closes LevelDB database.
+func (b *LevelDBBackend) Close() (err error) {
+	return b.ldb.Close()
+}
+
+// levelDBSnapshot adapts LevelDB snapshot to the Snapshot interface.
+type levelDBSnapshot struct {
+	s *leveldb.Snapshot
+}
+
+func (s levelDBSnapshot) Get(key []byte) (value []byte, err error) {
+	value, err = s.s.Get(key, nil)
+	return value, levelDBError(err)
+}
+
+func (s levelDBSnapshot) Has(key []byte) (yes bool, err error) {
+	yes, err = s.s.Has(key, nil)
+	return yes, levelDBError(err)
+}
+
+func (s levelDBSnapshot) NewIterator() Iterator {
+	return levelDBIterator{s.s.NewIterator(nil, nil)}
+}
+
+func (s levelDBSnapshot) Release() {
+	s.s.Release()
+}
+
+// levelDBIterator adapts LevelDB iterator to the Iterator interface.
+type levelDBIterator struct {
+	iterator.Iterator
+}
+
+func (it levelDBIterator) Error() error {
+	return levelDBError(it.Iterator.Error())
+}
+
+// levelDBError translates LevelDB errors to the ones defined by this package.
+func levelDBError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, leveldb.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, leveldb.ErrClosed):
+		return ErrClosed
+	}
+	return err
+}
@@ -0,0 +1,334 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var _ Backend = (*MemBackend)(nil)
+
+// MemBackend is a Backend that keeps data in memory. Key-value pairs are
+// held in a sorted slice that is copied on write only when it is referenced
+// by a snapshot or an iterator.
+type MemBackend struct {
+	mu     sync.RWMutex
+	kvs    []memKV
+	shared bool // kvs is referenced by snapshots or iterators
+	closed bool
+}
+
+type memKV struct {
+	key   []byte
+	value []byte
+}
+
+// NewMemBackend returns a new empty in-memory backend.
+func NewMemBackend() *MemBackend {
+	return new(MemBackend)
+}
+
+// Get returns the value for the key or ErrNotFound.
+func (b *MemBackend) Get(key []byte) (value []byte, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	return memGet(b.kvs, key)
+}
+
+// Has returns true if the key is stored.
+func (b *MemBackend) Has(key []byte) (yes bool, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return false, ErrClosed
+	}
+	_, yes = memSearch(b.kvs, key)
+	return yes, nil
+}
+
+// Put stores the value for the key.
+func (b *MemBackend) Put(key, value []byte) (err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	b.put(key, value)
+	return nil
+}
+
+// Delete removes the key.
+func (b *MemBackend) Delete(key []byte) (err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	b.delete(key)
+	return nil
+}
+
+// NewIterator returns an iterator over all keys.
+func (b *MemBackend) NewIterator() Iterator {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return &memIterator{err: ErrClosed}
+	}
+	b.shared = true
+	return &memIterator{kvs: b.kvs}
+}
+
+// NewBatch returns a new batch.
+func (b *MemBackend) NewBatch() Batch {
+	return new(memBatch)
+}
+
+// WriteBatch applies the batch returned by NewBatch.
+func (b *MemBackend) WriteBatch(batch Batch) (err error) {
+	mb, ok := batch.(*memBatch)
+	if !ok {
+		return fmt.Errorf("shed: unsupported batch type %T", batch)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	for _, op := range mb.ops {
+		if op.delete {
+			b.delete(op.key)
+		} else {
+			b.put(op.key, op.value)
+		}
+	}
+	return nil
+}
+
+// NewSnapshot returns a snapshot of the current state.
+func (b *MemBackend) NewSnapshot() (Snapshot, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	b.shared = true
+	return memSnapshot{kvs: b.kvs}, nil
+}
+
+// Compact does nothing as deleted keys do not take any space.
+func (b *MemBackend) Compact() (err error) {
+	return nil
+}
+
+// Close releases all data.
+func (b *MemBackend) Close() (err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.kvs = nil
+	return nil
+}
+
+// put stores the copies of key and value. It must be called under the lock.
+func (b *MemBackend) put(key, value []byte) {
+	b.own(1)
+	value = append([]byte(nil), value...)
+	i, found := memSearch(b.kvs, key)
+	if found {
+		b.kvs[i].value = value
+		return
+	}
+	b.kvs = append(b.kvs, memKV{})
+	copy(b.kvs[i+1:], b.kvs[i:])
+	b.kvs[i] = memKV{
+		key:   append([]byte(nil), key...),
+		value: value,
+	}
+}
+
+// delete removes the key. It must be called under the lock.
+func (b *MemBackend) delete(key []byte) {
+	i, found := memSearch(b.kvs, key)
+	if !found {
+		return
+	}
+	b.own(0)
+	b.kvs = append(b.kvs[:i], b.kvs[i+1:]...)
+}
+
+// own copies the key-value pairs with additional capacity if they are
+// referenced by snapshots or iterators. It must be called under the lock.
+func (b *MemBackend) own(grow int) {
+	if !b.shared {
+		return
+	}
+	kvs := make([]memKV, len(b.kvs), len(b.kvs)+grow)
+	copy(kvs, b.kvs)
+	b.kvs = kvs
+	b.shared = false
+}
+
+// memSearch returns the index of the first key that is greater or equal
+// to the provided key and whether it is equal.
+func memSearch(kvs []memKV, key []byte) (i int, found bool) {
+	i = sort.Search(len(kvs), func(i int) bool {
+		return bytes.Compare(kvs[i].key, key) >= 0
+	})
+	return i, i < len(kvs) && bytes.Equal(kvs[i].key, key)
+}
+
+// memGet returns a copy of the value for the key or ErrNotFound.
+func memGet(kvs []memKV, key []byte) (value []byte, err error) {
+	i, found := memSearch(kvs, key)
+	if !found {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), kvs[i].value...), nil
+}
+
+// memSnapshot is a read-only view of MemBackend key-value pairs.
+type memSnapshot struct {
+	kvs []memKV
+}
+
+func (s memSnapshot) Get(key []byte) (value []byte, err error) {
+	return memGet(s.kvs, key)
+}
+
+func (s memSnapshot) Has(key []byte) (yes bool, err error) {
+	_, yes = memSearch(s.kvs, key)
+	return yes, nil
+}
+
+func (s memSnapshot) NewIterator() Iterator {
+	return &memIterator{kvs: s.kvs}
+}
+
+func (s memSnapshot) Release() {}
+
+// memBatch records write operations for MemBackend.
+type memBatch struct {
+	ops []memOp
+}
+
+type memOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+func (b *memBatch) Put(key, value []byte) {
+	b.ops = append(b.ops, memOp{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	})
+}
+
+func (b *memBatch) Delete(key []byte) {
+	b.ops = append(b.ops, memOp{
+		key:    append([]byte(nil), key...),
+		delete: true,
+	})
+}
+
+func (b *memBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *memBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+// memIterator iterates over MemBackend key-value pairs. As with LevelDB
+// iterators, Next and Prev move to the first and last pair of an iterator
+// that is not positioned.
+type memIterator struct {
+	kvs        []memKV
+	pos        int
+	positioned bool
+	err        error
+}
+
+func (it *memIterator) First() bool {
+	return it.move(0)
+}
+
+func (it *memIterator) Last() bool {
+	return it.move(len(it.kvs) - 1)
+}
+
+func (it *memIterator) Seek(key []byte) bool {
+	i, _ := memSearch(it.kvs, key)
+	return it.move(i)
+}
+
+func (it *memIterator) Next() bool {
+	if !it.positioned {
+		return it.First()
+	}
+	if it.pos >= len(it.kvs) {
+		return false
+	}
+	return it.move(it.pos + 1)
+}
+
+func (it *memIterator) Prev() bool {
+	if !it.positioned {
+		return it.Last()
+	}
+	if it.pos < 0 {
+		return false
+	}
+	return it.move(it.pos - 1)
+}
+
+func (it *memIterator) Key() []byte {
+	if !it.valid() {
+		return nil
+	}
+	return it.kvs[it.pos].key
+}
+
+func (it *memIterator) Value() []byte {
+	if !it.valid() {
+		return nil
+	}
+	return it.kvs[it.pos].value
+}
+
+func (it *memIterator) Error() error {
+	return it.err
+}
+
+func (it *memIterator) Release() {
+	it.kvs = nil
+	it.positioned = false
+}
+
+func (it *memIterator) move(pos int) bool {
+	it.positioned = true
+	it.pos = pos
+	return it.valid()
+}
+
+func (it *memIterator) valid() bool {
+	return it.positioned && it.pos >= 0 && it.pos < len(it.kvs)
+}
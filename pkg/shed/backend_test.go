@@ -0,0 +1,233 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// TestBackends validates that all backends provided by the package
+// behave in the same way.
+func TestBackends(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		newBackend func(t *testing.T) Backend
+	}{
+		{
+			name: "leveldb",
+			newBackend: func(t *testing.T) Backend {
+				dir, err := ioutil.TempDir("", "shed-test-backend")
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { os.RemoveAll(dir) })
+				b, err := NewLevelDBBackend(dir)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return b
+			},
+		},
+		{
+			name: "memory",
+			newBackend: func(t *testing.T) Backend {
+				return NewMemBackend()
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("get put delete", func(t *testing.T) {
+				b := tc.newBackend(t)
+				defer b.Close()
+
+				testBackendGetPutDelete(t, b)
+			})
+			t.Run("batch", func(t *testing.T) {
+				b := tc.newBackend(t)
+				defer b.Close()
+
+				testBackendBatch(t, b)
+			})
+			t.Run("iterator", func(t *testing.T) {
+				b := tc.newBackend(t)
+				defer b.Close()
+
+				testBackendIterator(t, b)
+			})
+			t.Run("snapshot", func(t *testing.T) {
+				b := tc.newBackend(t)
+				defer b.Close()
+
+				testBackendSnapshot(t, b)
+			})
+			t.Run("closed", func(t *testing.T) {
+				b := tc.newBackend(t)
+				if err := b.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := b.Get([]byte("key")); !errors.Is(err, ErrClosed) {
+					t.Errorf("got error %v, want %v", err, ErrClosed)
+				}
+			})
+		})
+	}
+}
+
+func testBackendGetPutDelete(t *testing.T, b Backend) {
+	t.Helper()
+
+	key := []byte("key")
+	if _, err := b.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, ErrNotFound)
+	}
+	for _, want := range [][]byte{[]byte("value"), []byte("overwritten value")} {
+		if err := b.Put(key, want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := b.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("got value %q, want %q", got, want)
+		}
+	}
+	if err := b.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	has, err := b.Has(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has {
+		t.Error("deleted key found")
+	}
+}
+
+func testBackendBatch(t *testing.T, b Backend) {
+	t.Helper()
+
+	if err := b.Put([]byte("deleted"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	batch := b.NewBatch()
+	batch.Put([]byte("key"), []byte("value"))
+	batch.Delete([]byte("deleted"))
+	if batch.Len() != 2 {
+		t.Errorf("got batch length %v, want %v", batch.Len(), 2)
+	}
+	// batch is not applied before it is written
+	if has, _ := b.Has([]byte("key")); has {
+		t.Fatal("key found before the batch is written")
+	}
+	if err := b.WriteBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := b.Has([]byte("key")); !has {
+		t.Error("key not found")
+	}
+	if has, _ := b.Has([]byte("deleted")); has {
+		t.Error("deleted key found")
+	}
+	batch.Reset()
+	if batch.Len() != 0 {
+		t.Errorf("got batch length %v after reset, want %v", batch.Len(), 0)
+	}
+}
+
+func testBackendIterator(t *testing.T, b Backend) {
+	t.Helper()
+
+	keys := []string{"a", "b", "ba", "c", "d"}
+	// put keys in reverse order to validate sorting
+	for i := len(keys) - 1; i >= 0; i-- {
+		if err := b.Put([]byte(keys[i]), []byte("value "+keys[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it := b.NewIterator()
+	defer it.Release()
+
+	// writes after the iterator is created are not visible
+	if err := b.Put([]byte("bb"), []byte("value bb")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete([]byte("c")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, string(it.Key()))
+		if v := string(it.Value()); v != "value "+string(it.Key()) {
+			t.Errorf("got value %q for key %q", v, it.Key())
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(keys) {
+		t.Errorf("got keys %v, want %v", got, keys)
+	}
+
+	if !it.Seek([]byte("bz")) || string(it.Key()) != "c" {
+		t.Errorf("got key %q after seek, want %q", it.Key(), "c")
+	}
+	if !it.Prev() || string(it.Key()) != "ba" {
+		t.Errorf("got key %q after prev, want %q", it.Key(), "ba")
+	}
+	if it.Seek([]byte("e")) {
+		t.Errorf("got key %q after seek past the last key", it.Key())
+	}
+	if !it.Prev() || string(it.Key()) != "d" {
+		t.Errorf("got key %q after prev, want %q", it.Key(), "d")
+	}
+	if !it.Last() || string(it.Key()) != "d" {
+		t.Errorf("got last key %q, want %q", it.Key(), "d")
+	}
+	if it.Next() {
+		t.Errorf("got key %q after the last key", it.Key())
+	}
+}
+
+func testBackendSnapshot(t *testing.T, b Backend) {
+	t.Helper()
+
+	if err := b.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	s, err := b.NewSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Release()
+
+	if err := b.Put([]byte("key"), []byte("new value")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Put([]byte("new key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Get([]byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "value" {
+		t.Errorf("got snapshot value %q, want %q", got, "value")
+	}
+	if _, err := s.Get([]byte("new key")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrNotFound)
+	}
+	if has, _ := s.Has([]byte("new key")); has {
+		t.Error("key put after the snapshot found")
+	}
+}
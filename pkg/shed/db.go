@@ -24,51 +24,65 @@ package shed
 
 import (
 	"errors"
-
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
-	"github.com/syndtr/goleveldb/leveldb/opt"
-	"github.com/syndtr/goleveldb/leveldb/storage"
-)
-
-var (
-	openFileLimit = 128 // The limit for LevelDB OpenFilesCacheCapacity.
 )
 
-// DB provides abstractions over LevelDB in order to
+// DB provides abstractions over a key-value Backend in order to
 // implement complex structures using fields and ordered indexes.
 // It provides a schema functionality to store fields and indexes
 // information about naming and types.
 type DB struct {
-	ldb     *leveldb.DB
+	backend Backend
 	metrics metrics
 	quit    chan struct{} // Quit channel to stop the metrics collection before closing the database
 }
 
-// NewDB constructs a new DB and validates the schema
-// if it exists in database on the given path.
-// metricsPrefix is used for metrics collection for the given DB.
+// NewDB constructs a new DB on LevelDB backend and validates the schema
+// if it exists in database on the given path. If the path is empty,
+// the database is kept in memory.
 func NewDB(path string) (db *DB, err error) {
-	var ldb *leveldb.DB
+	var b Backend
 	if path == "" {
-		ldb, err = leveldb.Open(storage.NewMemStorage(), nil)
+		b = NewMemBackend()
 	} else {
-		ldb, err = leveldb.OpenFile(path, &opt.Options{
-			OpenFilesCacheCapacity: openFileLimit,
-		})
+		b, err = NewLevelDBBackend(path)
+		if err != nil {
+			return nil, err
+		}
 	}
+	db, err = NewDBWithBackend(b)
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	return db, nil
+}
 
+// NewReadOnlyDB opens an existing DB on LevelDB backend on the given
+// path without writing to it. Fields and indexes that are not in the
+// schema of the database can not be created.
+func NewReadOnlyDB(path string) (db *DB, err error) {
+	b, err := NewReadOnlyLevelDBBackend(path)
 	if err != nil {
 		return nil, err
 	}
+	db, err = NewDBWithBackend(b)
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	return db, nil
+}
 
+// NewDBWithBackend constructs a new DB on the provided backend
+// and validates the schema if it exists in the backend.
+func NewDBWithBackend(b Backend) (db *DB, err error) {
 	db = &DB{
-		ldb:     ldb,
+		backend: b,
 		metrics: newMetrics(),
 	}
 
 	if _, err = db.getSchema(); err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, ErrNotFound) {
 			// save schema with initialized default fields
 			if err = db.putSchema(schema{
 				Fields:  make(map[string]fieldSpec),
@@ -87,9 +101,9 @@ func NewDB(path string) (db *DB, err error) {
 	return db, nil
 }
 
-// Put wraps LevelDB Put method to increment metrics counter.
+// Put wraps Backend Put method to increment metrics counter.
 func (db *DB) Put(key, value []byte) (err error) {
-	err = db.ldb.Put(key, value, nil)
+	err = db.backend.Put(key, value)
 	if err != nil {
 		db.metrics.PutFailCounter.Inc()
 		return err
@@ -98,10 +112,10 @@ func (db *DB) Put(key, value []byte) (err error) {
 	return nil
 }
 
-// Get wraps LevelDB Get method to increment metrics counter.
+// Get wraps Backend Get method to increment metrics counter.
 func (db *DB) Get(key []byte) (value []byte, err error) {
-	value, err = db.ldb.Get(key, nil)
-	if errors.Is(err, leveldb.ErrNotFound) {
+	value, err = db.backend.Get(key)
+	if errors.Is(err, ErrNotFound) {
 		db.metrics.GetNotFoundCounter.Inc()
 		return nil, err
 	} else {
@@ -111,9 +125,9 @@ func (db *DB) Get(key []byte) (value []byte, err error) {
 	return value, nil
 }
 
-// Has wraps LevelDB Has method to increment metrics counter.
+// Has wraps Backend Has method to increment metrics counter.
 func (db *DB) Has(key []byte) (yes bool, err error) {
-	yes, err = db.ldb.Has(key, nil)
+	yes, err = db.backend.Has(key)
 	if err != nil {
 		db.metrics.HasFailCounter.Inc()
 		return false, err
@@ -122,9 +136,9 @@ func (db *DB) Has(key []byte) (yes bool, err error) {
 	return yes, nil
 }
 
-// Delete wraps LevelDB Delete method to increment metrics counter.
+// Delete wraps Backend Delete method to increment metrics counter.
 func (db *DB) Delete(key []byte) (err error) {
-	err = db.ldb.Delete(key, nil)
+	err = db.backend.Delete(key)
 	if err != nil {
 		db.metrics.DeleteFailCounter.Inc()
 		return err
@@ -133,15 +147,25 @@ func (db *DB) Delete(key []byte) (err error) {
 	return nil
 }
 
-// NewIterator wraps LevelDB NewIterator method to increment metrics counter.
-func (db *DB) NewIterator() iterator.Iterator {
+// NewIterator wraps Backend NewIterator method to increment metrics counter.
+func (db *DB) NewIterator() Iterator {
 	db.metrics.IteratorCounter.Inc()
-	return db.ldb.NewIterator(nil, nil)
+	return db.backend.NewIterator()
+}
+
+// NewSnapshot wraps Backend NewSnapshot method.
+func (db *DB) NewSnapshot() (Snapshot, error) {
+	return db.backend.NewSnapshot()
+}
+
+// NewBatch returns a new batch that can be written by WriteBatch.
+func (db *DB) NewBatch() Batch {
+	return db.backend.NewBatch()
 }
 
-// WriteBatch wraps LevelDB Write method to increment metrics counter.
-func (db *DB) WriteBatch(batch *leveldb.Batch) (err error) {
-	err = db.ldb.Write(batch, nil)
+// WriteBatch wraps Backend WriteBatch method to increment metrics counter.
+func (db *DB) WriteBatch(batch Batch) (err error) {
+	err = db.backend.WriteBatch(batch)
 	if err != nil {
 		db.metrics.WriteBatchFailCounter.Inc()
 		return err
@@ -150,8 +174,45 @@ func (db *DB) WriteBatch(batch *leveldb.Batch) (err error) {
 	return nil
 }
 
-// Close closes LevelDB database.
+// WriteBatchSync writes the batch as WriteBatch does and waits until it is
+// written to disk, if the backend supports it.
+func (db *DB) WriteBatchSync(batch Batch) (err error) {
+	b, ok := db.backend.(syncWriter)
+	if !ok {
+		return db.WriteBatch(batch)
+	}
+	err = b.WriteBatchSync(batch)
+	if err != nil {
+		db.metrics.WriteBatchFailCounter.Inc()
+		return err
+	}
+	db.metrics.WriteBatchCounter.Inc()
+	return nil
+}
+
+// Size returns the size of the database on disk in bytes, if the backend
+// is able to report it. Databases in memory have zero size.
+func (db *DB) Size() (size uint64, err error) {
+	if b, ok := db.backend.(sizer); ok {
+		return b.Size()
+	}
+	return 0, nil
+}
+
+// Compact wraps Backend Compact method to increment metrics counter,
+// releasing the disk space of deleted keys.
+func (db *DB) Compact() (err error) {
+	err = db.backend.Compact()
+	if err != nil {
+		db.metrics.CompactFailCounter.Inc()
+		return err
+	}
+	db.metrics.CompactCounter.Inc()
+	return nil
+}
+
+// Close closes the backend.
 func (db *DB) Close() (err error) {
 	close(db.quit)
-	return db.ldb.Close()
+	return db.backend.Close()
 }
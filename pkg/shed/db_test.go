@@ -17,6 +17,7 @@
 package shed
 
 import (
+	"crypto/rand"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -89,6 +90,141 @@ func TestDB_persistence(t *testing.T) {
 	}
 }
 
+// TestDB_readOnly validates that the values are retrieved from
+// a read-only DB and that they can not be changed.
+func TestDB_readOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shed-test-read-only")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewReadOnlyDB(dir); err == nil {
+		t.Fatal("opened read-only db that does not exist")
+	}
+
+	db, err := NewDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stringField, err := db.NewStringField("preserve-me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "persistent value"
+	if err := stringField.Put(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = NewReadOnlyDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stringField, err = db.NewStringField("preserve-me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := stringField.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got string %q, want %q", got, want)
+	}
+	if err := stringField.Put("changed value"); err == nil {
+		t.Error("changed value in read-only db")
+	}
+	if _, err := db.NewStringField("new-field"); err == nil {
+		t.Error("created field in read-only db")
+	}
+}
+
+// TestDB_Size validates that the database size on disk grows with
+// the stored data and that in memory databases have zero size.
+func TestDB_Size(t *testing.T) {
+	size, err := newTestDB(t).Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 0 {
+		t.Errorf("got in memory database size %v, want 0", size)
+	}
+
+	dir, err := ioutil.TempDir("", "shed-test-size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := NewDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	initial, err := db.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// random data is not compressed by leveldb
+	value := make([]byte, 1<<20)
+	if _, err := rand.Read(value); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key"), value); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err = db.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size < initial+uint64(len(value))/2 {
+		t.Errorf("got database size %v after storing %v bytes, initial size %v", size, len(value), initial)
+	}
+}
+
+// TestDB_WriteBatchSync validates that the synced batch is applied by the
+// backends that write it to disk and by the ones that do not.
+func TestDB_WriteBatchSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shed-test-write-batch-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	diskDB, err := NewDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer diskDB.Close()
+
+	for name, db := range map[string]*DB{
+		"leveldb": diskDB,
+		"memory":  newTestDB(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			batch := db.NewBatch()
+			batch.Put([]byte("key"), []byte("value"))
+			if err := db.WriteBatchSync(batch); err != nil {
+				t.Fatal(err)
+			}
+			if has, err := db.Has([]byte("key")); err != nil || !has {
+				t.Errorf("got key found %v, error %v, want found", has, err)
+			}
+		})
+	}
+}
+
 // newTestDB is a helper function that constructs a
 // temporary database and returns a cleanup function that must
 // be called to remove the data.
@@ -19,8 +19,6 @@ package shed
 import (
 	"errors"
 	"fmt"
-
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // StringField is the most simple field implementation
@@ -49,7 +47,7 @@ func (db *DB) NewStringField(name string) (f StringField, err error) {
 func (f StringField) Get() (val string, err error) {
 	b, err := f.db.Get(f.key)
 	if err != nil {
-		if errors.Is(err, leveldb.ErrNotFound) {
+		if errors.Is(err, ErrNotFound) {
 			return "", nil
 		}
 		return "", err
@@ -64,6 +62,6 @@ func (f StringField) Put(val string) (err error) {
 
 // PutInBatch stores a string in a batch that can be
 // saved later in database.
-func (f StringField) PutInBatch(batch *leveldb.Batch, val string) {
+func (f StringField) PutInBatch(batch Batch, val string) {
 	batch.Put(f.key, []byte(val))
 }
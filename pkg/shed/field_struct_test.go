@@ -18,8 +18,6 @@ package shed
 
 import (
 	"testing"
-
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // TestStructField validates put and get operations
@@ -39,8 +37,8 @@ func TestStructField(t *testing.T) {
 	t.Run("get empty", func(t *testing.T) {
 		var s complexStructure
 		err := complexField.Get(&s)
-		if err != leveldb.ErrNotFound {
-			t.Fatalf("got error %v, want %v", err, leveldb.ErrNotFound)
+		if err != ErrNotFound {
+			t.Fatalf("got error %v, want %v", err, ErrNotFound)
 		}
 		want := ""
 		if s.A != want {
@@ -85,7 +83,7 @@ func TestStructField(t *testing.T) {
 	})
 
 	t.Run("put in batch", func(t *testing.T) {
-		batch := new(leveldb.Batch)
+		batch := db.NewBatch()
 		want := complexStructure{
 			A: "simple string batch value",
 		}
@@ -107,7 +105,7 @@ func TestStructField(t *testing.T) {
 		}
 
 		t.Run("overwrite", func(t *testing.T) {
-			batch := new(leveldb.Batch)
+			batch := db.NewBatch()
 			want := complexStructure{
 				A: "overwritten string batch value",
 			}
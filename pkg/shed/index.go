@@ -20,9 +20,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
 // Item holds fields relevant to Swarm Chunk data and metadata.
@@ -40,7 +37,9 @@ import (
 type Item struct {
 	Address         []byte
 	Data            []byte
+	Location        []byte // encoded location of the data stored outside of the database
 	AccessTimestamp int64
+	AccessCount     uint64 // maintains the no of times a chunk is accessed
 	StoreTimestamp  int64
 	BinID           uint64
 	PinCounter      uint64 // maintains the no of time a chunk is pinned
@@ -57,9 +56,15 @@ func (i Item) Merge(i2 Item) Item {
 	if i.Data == nil {
 		i.Data = i2.Data
 	}
+	if i.Location == nil {
+		i.Location = i2.Location
+	}
 	if i.AccessTimestamp == 0 {
 		i.AccessTimestamp = i2.AccessTimestamp
 	}
+	if i.AccessCount == 0 {
+		i.AccessCount = i2.AccessCount
+	}
 	if i.StoreTimestamp == 0 {
 		i.StoreTimestamp = i2.StoreTimestamp
 	}
@@ -158,9 +163,9 @@ func (f Index) Get(keyFields Item) (out Item, err error) {
 // fields. Every item must have all fields needed for encoding the
 // key set. The passed slice items will be changed so that they
 // contain data from the index values. No new slice is allocated.
-// This function uses a single leveldb snapshot.
+// This function uses a single backend snapshot.
 func (f Index) Fill(items []Item) (err error) {
-	snapshot, err := f.db.ldb.GetSnapshot()
+	snapshot, err := f.db.NewSnapshot()
 	if err != nil {
 		return fmt.Errorf("get snapshot: %w", err)
 	}
@@ -171,7 +176,7 @@ func (f Index) Fill(items []Item) (err error) {
 		if err != nil {
 			return fmt.Errorf("encode key: %w", err)
 		}
-		value, err := snapshot.Get(key, nil)
+		value, err := snapshot.Get(key)
 		if err != nil {
 			return fmt.Errorf("get value: %w", err)
 		}
@@ -199,7 +204,7 @@ func (f Index) Has(keyFields Item) (bool, error) {
 // there this Item's encoded key is stored in the index for each of them.
 func (f Index) HasMulti(items ...Item) ([]bool, error) {
 	have := make([]bool, len(items))
-	snapshot, err := f.db.ldb.GetSnapshot()
+	snapshot, err := f.db.NewSnapshot()
 	if err != nil {
 		return nil, fmt.Errorf("get snapshot: %w", err)
 	}
@@ -209,7 +214,7 @@ func (f Index) HasMulti(items ...Item) ([]bool, error) {
 		if err != nil {
 			return nil, fmt.Errorf("encode key for address %x: %w", keyFields.Address, err)
 		}
-		have[i], err = snapshot.Has(key, nil)
+		have[i], err = snapshot.Has(key)
 		if err != nil {
 			return nil, fmt.Errorf("has key for address %x: %w", keyFields.Address, err)
 		}
@@ -234,7 +239,7 @@ func (f Index) Put(i Item) (err error) {
 // PutInBatch is the same as Put method, but it just
 // saves the key/value pair to the batch instead
 // directly to the database.
-func (f Index) PutInBatch(batch *leveldb.Batch, i Item) (err error) {
+func (f Index) PutInBatch(batch Batch, i Item) (err error) {
 	key, err := f.encodeKeyFunc(i)
 	if err != nil {
 		return fmt.Errorf("encode key: %w", err)
@@ -259,7 +264,7 @@ func (f Index) Delete(keyFields Item) (err error) {
 
 // DeleteInBatch is the same as Delete just the operation
 // is performed on the batch instead on the database.
-func (f Index) DeleteInBatch(batch *leveldb.Batch, keyFields Item) (err error) {
+func (f Index) DeleteInBatch(batch Batch, keyFields Item) (err error) {
 	key, err := f.encodeKeyFunc(keyFields)
 	if err != nil {
 		return fmt.Errorf("encode key: %w", err)
@@ -320,7 +325,7 @@ func (f Index) Iterate(fn IndexIterFunc, options *IterateOptions) (err error) {
 	for ; ok; ok = it.Next() {
 		item, err := f.itemFromIterator(it, prefix)
 		if err != nil {
-			if errors.Is(err, leveldb.ErrNotFound) {
+			if errors.Is(err, ErrNotFound) {
 				break
 			}
 			return fmt.Errorf("get item from iterator: %w", err)
@@ -338,7 +343,7 @@ func (f Index) Iterate(fn IndexIterFunc, options *IterateOptions) (err error) {
 
 // First returns the first item in the Index which encoded key starts with a prefix.
 // If the prefix is nil, the first element of the whole index is returned.
-// If Index has no elements, a leveldb.ErrNotFound error is returned.
+// If Index has no elements, a ErrNotFound error is returned.
 func (f Index) First(prefix []byte) (i Item, err error) {
 	it := f.db.NewIterator()
 	defer it.Release()
@@ -351,19 +356,19 @@ func (f Index) First(prefix []byte) (i Item, err error) {
 
 // itemFromIterator returns the Item from the current iterator position.
 // If the complete encoded key does not start with totalPrefix,
-// leveldb.ErrNotFound is returned. Value for totalPrefix must start with
+// ErrNotFound is returned. Value for totalPrefix must start with
 // Index prefix.
-func (f Index) itemFromIterator(it iterator.Iterator, totalPrefix []byte) (i Item, err error) {
+func (f Index) itemFromIterator(it Iterator, totalPrefix []byte) (i Item, err error) {
 	key := it.Key()
 	if !bytes.HasPrefix(key, totalPrefix) {
-		return i, leveldb.ErrNotFound
+		return i, ErrNotFound
 	}
-	// create a copy of key byte slice not to share leveldb underlaying slice array
+	// create a copy of key byte slice not to share backend underlaying slice array
 	keyItem, err := f.decodeKeyFunc(append([]byte(nil), key...))
 	if err != nil {
 		return i, fmt.Errorf("decode key: %w", err)
 	}
-	// create a copy of value byte slice not to share leveldb underlaying slice array
+	// create a copy of value byte slice not to share backend underlaying slice array
 	valueItem, err := f.decodeValueFunc(keyItem, append([]byte(nil), it.Value()...))
 	if err != nil {
 		return i, fmt.Errorf("decode value: %w", err)
@@ -373,13 +378,13 @@ func (f Index) itemFromIterator(it iterator.Iterator, totalPrefix []byte) (i Ite
 
 // Last returns the last item in the Index which encoded key starts with a prefix.
 // If the prefix is nil, the last element of the whole index is returned.
-// If Index has no elements, a leveldb.ErrNotFound error is returned.
+// If Index has no elements, a ErrNotFound error is returned.
 func (f Index) Last(prefix []byte) (i Item, err error) {
 	it := f.db.NewIterator()
 	defer it.Release()
 
 	// get the next prefix in line
-	// since leveldb iterator Seek seeks to the
+	// since backend iterator Seek seeks to the
 	// next key if the key that it seeks to is not found
 	// and by getting the previous key, the last one for the
 	// actual prefix is found
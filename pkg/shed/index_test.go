@@ -24,8 +24,6 @@ import (
 	"sort"
 	"testing"
 	"time"
-
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // Index functions for the index that is used in tests in this file.
@@ -106,7 +104,7 @@ func TestIndex(t *testing.T) {
 			StoreTimestamp: time.Now().UTC().UnixNano(),
 		}
 
-		batch := new(leveldb.Batch)
+		batch := db.NewBatch()
 		err = index.PutInBatch(batch, want)
 		if err != nil {
 			t.Fatal(err)
@@ -130,7 +128,7 @@ func TestIndex(t *testing.T) {
 				StoreTimestamp: time.Now().UTC().UnixNano(),
 			}
 
-			batch := new(leveldb.Batch)
+			batch := db.NewBatch()
 			err = index.PutInBatch(batch, want)
 			if err != nil {
 				t.Fatal(err)
@@ -152,7 +150,7 @@ func TestIndex(t *testing.T) {
 	t.Run("put in batch twice", func(t *testing.T) {
 		// ensure that the last item of items with the same db keys
 		// is actually saved
-		batch := new(leveldb.Batch)
+		batch := db.NewBatch()
 		address := []byte("put-in-batch-twice-hash")
 
 		// put the first item
@@ -250,7 +248,7 @@ func TestIndex(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		wantErr := leveldb.ErrNotFound
+		wantErr := ErrNotFound
 		_, err = index.Get(Item{
 			Address: want.Address,
 		})
@@ -278,7 +276,7 @@ func TestIndex(t *testing.T) {
 		}
 		checkItem(t, got, want)
 
-		batch := new(leveldb.Batch)
+		batch := db.NewBatch()
 		err = index.DeleteInBatch(batch, Item{
 			Address: want.Address,
 		})
@@ -290,7 +288,7 @@ func TestIndex(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		wantErr := leveldb.ErrNotFound
+		wantErr := ErrNotFound
 		_, err = index.Get(Item{
 			Address: want.Address,
 		})
@@ -353,7 +351,7 @@ func TestIndex(t *testing.T) {
 			items = append(items, Item{
 				Address: []byte("put-hash-missing"),
 			})
-			want := leveldb.ErrNotFound
+			want := ErrNotFound
 			err := index.Fill(items)
 			if !errors.Is(err, want) {
 				t.Errorf("got error %v, want %v", err, want)
@@ -394,7 +392,7 @@ func TestIndex_Iterate(t *testing.T) {
 			Data:    []byte("data1"),
 		},
 	}
-	batch := new(leveldb.Batch)
+	batch := db.NewBatch()
 	for _, i := range items {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -567,7 +565,7 @@ func TestIndex_Iterate_withPrefix(t *testing.T) {
 		{Address: []byte("want-hash-09"), Data: []byte("data89")},
 		{Address: []byte("skip-hash-10"), Data: []byte("data90")},
 	}
-	batch := new(leveldb.Batch)
+	batch := db.NewBatch()
 	for _, i := range allItems {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -762,7 +760,7 @@ func TestIndex_count(t *testing.T) {
 			Data:    []byte("data1"),
 		},
 	}
-	batch := new(leveldb.Batch)
+	batch := db.NewBatch()
 	for _, i := range items {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
@@ -934,7 +932,7 @@ func TestIndex_firstAndLast(t *testing.T) {
 		return bytes.Compare(addrs[i], addrs[j]) == -1
 	})
 
-	batch := new(leveldb.Batch)
+	batch := db.NewBatch()
 	for _, addr := range addrs {
 		err = index.PutInBatch(batch, Item{
 			Address: addr,
@@ -996,11 +994,11 @@ func TestIndex_firstAndLast(t *testing.T) {
 		},
 		{
 			prefix: []byte{0, 3},
-			err:    leveldb.ErrNotFound,
+			err:    ErrNotFound,
 		},
 		{
 			prefix: []byte{222},
-			err:    leveldb.ErrNotFound,
+			err:    ErrNotFound,
 		},
 	} {
 		got, err := index.Last(tc.prefix)
@@ -1080,7 +1078,7 @@ func TestIndex_HasMulti(t *testing.T) {
 		Data:    []byte("data0"),
 	}
 
-	batch := new(leveldb.Batch)
+	batch := db.NewBatch()
 	for _, i := range items {
 		err = index.PutInBatch(batch, i)
 		if err != nil {
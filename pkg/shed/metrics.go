@@ -25,6 +25,8 @@ type metrics struct {
 	IteratorCounter       prometheus.Counter
 	WriteBatchCounter     prometheus.Counter
 	WriteBatchFailCounter prometheus.Counter
+	CompactCounter        prometheus.Counter
+	CompactFailCounter    prometheus.Counter
 }
 
 func newMetrics() metrics {
@@ -103,6 +105,18 @@ func newMetrics() metrics {
 			Name:      "write_batch_fail_count",
 			Help:      "Number of times the WRITE_BATCH operation failed.",
 		}),
+		CompactCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_count",
+			Help:      "Number of times the COMPACT operation is done.",
+		}),
+		CompactFailCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "compact_fail_count",
+			Help:      "Number of times the COMPACT operation failed.",
+		}),
 	}
 }
 
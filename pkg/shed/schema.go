@@ -71,6 +71,7 @@ func (db *DB) schemaFieldKey(name, fieldType string) (key []byte, err error) {
 			if f.Type != fieldType {
 				return nil, fmt.Errorf("field %q of type %q stored as %q in db", name, fieldType, f.Type)
 			}
+			found = true
 			break
 		}
 	}
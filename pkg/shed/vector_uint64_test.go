@@ -18,8 +18,6 @@ package shed
 
 import (
 	"testing"
-
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // TestUint64Vector validates put and get operations
@@ -77,7 +75,7 @@ func TestUint64Vector(t *testing.T) {
 
 	t.Run("put in batch", func(t *testing.T) {
 		for _, index := range []uint64{0, 1, 2, 3, 5, 10} {
-			batch := new(leveldb.Batch)
+			batch := db.NewBatch()
 			var want uint64 = 43 + index
 			bins.PutInBatch(batch, index, want)
 			err = db.WriteBatch(batch)
@@ -93,7 +91,7 @@ func TestUint64Vector(t *testing.T) {
 			}
 
 			t.Run("overwrite", func(t *testing.T) {
-				batch := new(leveldb.Batch)
+				batch := db.NewBatch()
 				var want uint64 = 85 + index
 				bins.PutInBatch(batch, index, want)
 				err = db.WriteBatch(batch)
@@ -154,7 +152,7 @@ func TestUint64Vector_IncInBatch(t *testing.T) {
 	}
 
 	for _, index := range []uint64{0, 1, 2, 3, 5, 10} {
-		batch := new(leveldb.Batch)
+		batch := db.NewBatch()
 		var want uint64 = 1
 		got, err := bins.IncInBatch(batch, index)
 		if err != nil {
@@ -175,7 +173,7 @@ func TestUint64Vector_IncInBatch(t *testing.T) {
 			t.Errorf("got %v uint64 %v, want %v", index, got, want)
 		}
 
-		batch2 := new(leveldb.Batch)
+		batch2 := db.NewBatch()
 		want = 2
 		got, err = bins.IncInBatch(batch2, index)
 		if err != nil {
@@ -247,7 +245,7 @@ func TestUint64Vector_DecInBatch(t *testing.T) {
 	}
 
 	for _, index := range []uint64{0, 1, 2, 3, 5, 10} {
-		batch := new(leveldb.Batch)
+		batch := db.NewBatch()
 		var want uint64
 		got, err := bins.DecInBatch(batch, index)
 		if err != nil {
@@ -268,7 +266,7 @@ func TestUint64Vector_DecInBatch(t *testing.T) {
 			t.Errorf("got %v uint64 %v, want %v", index, got, want)
 		}
 
-		batch2 := new(leveldb.Batch)
+		batch2 := db.NewBatch()
 		want = 42 + index
 		bins.PutInBatch(batch2, index, want)
 		err = db.WriteBatch(batch2)
@@ -283,7 +281,7 @@ func TestUint64Vector_DecInBatch(t *testing.T) {
 			t.Errorf("got %v uint64 %v, want %v", index, got, want)
 		}
 
-		batch3 := new(leveldb.Batch)
+		batch3 := db.NewBatch()
 		want = 41 + index
 		got, err = bins.DecInBatch(batch3, index)
 		if err != nil {
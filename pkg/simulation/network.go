@@ -0,0 +1,514 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/topology"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var (
+	errNetworkClosed     = errors.New("network closed")
+	errUnknownAddress    = errors.New("unknown address")
+	errConnectionRefused = errors.New("connection refused")
+	errPartitioned       = errors.New("peer is in another partition")
+)
+
+// partitionRetry is the time after which the topology retries to connect to
+// a peer in another partition.
+var partitionRetry = time.Minute
+
+// network connects the in-memory p2p services of simulated nodes.
+type network struct {
+	services map[string]*service // by underlay address
+	links    map[linkKey]*link
+	groups   map[string]int // partition group by overlay, nil if not partitioned
+	closed   bool
+	mu       sync.RWMutex
+	handlers sync.WaitGroup // running stream handlers
+}
+
+// linkKey identifies the link by the ordered overlays of its peers.
+type linkKey struct {
+	a, b string
+}
+
+func newLinkKey(a, b swarm.Address) linkKey {
+	if strings.Compare(a.ByteString(), b.ByteString()) > 0 {
+		a, b = b, a
+	}
+	return linkKey{a: a.ByteString(), b: b.ByteString()}
+}
+
+// link is a connection between two services.
+type link struct {
+	dialer   *service
+	listener *service
+	ctx      context.Context // cancelled on disconnect
+	cancel   context.CancelFunc
+	streams  []*stream
+}
+
+// peer returns the other end of the link.
+func (l *link) peer(s *service) *service {
+	if l.dialer == s {
+		return l.listener
+	}
+	return l.dialer
+}
+
+func newNetwork() *network {
+	return &network{
+		services: make(map[string]*service),
+		links:    make(map[linkKey]*link),
+	}
+}
+
+func (n *network) add(s *service) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.services[s.address.Underlay.String()] = s
+}
+
+func (n *network) lookup(addr ma.Multiaddr) *service {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.services[addr.String()]
+}
+
+// connect links the services if they are not already connected and are in
+// the same partition.
+func (n *network) connect(dialer, listener *service) (*link, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return nil, errNetworkClosed
+	}
+
+	key := newLinkKey(dialer.overlay(), listener.overlay())
+	if _, ok := n.links[key]; ok {
+		return nil, p2p.ErrAlreadyConnected
+	}
+	if !n.reachable(dialer.overlay(), listener.overlay()) {
+		return nil, p2p.NewConnectionBackoffError(errPartitioned, time.Now().Add(partitionRetry))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &link{
+		dialer:   dialer,
+		listener: listener,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	n.links[key] = l
+	return l, nil
+}
+
+// disconnect removes the link between the peers and resets all of its
+// streams. It returns nil if the peers are not connected.
+func (n *network) disconnect(a, b swarm.Address) *link {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := newLinkKey(a, b)
+	l, ok := n.links[key]
+	if !ok {
+		return nil
+	}
+	delete(n.links, key)
+	n.closeLink(l)
+	return l
+}
+
+// closeLink cancels the link context and resets its streams. It must be
+// called with the lock held.
+func (n *network) closeLink(l *link) {
+	l.cancel()
+	for _, s := range l.streams {
+		_ = s.Reset()
+	}
+	l.streams = nil
+}
+
+func (n *network) link(a, b swarm.Address) (*link, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	l, ok := n.links[newLinkKey(a, b)]
+	return l, ok
+}
+
+// addStreams registers streams to be reset when the link is closed and
+// accounts for a new stream handler, which must call handlers.Done when it
+// returns. It returns false if the link is already closed.
+func (n *network) addStreams(l *link, streams ...*stream) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if l.ctx.Err() != nil {
+		return false
+	}
+	l.streams = append(l.streams, streams...)
+	n.handlers.Add(1)
+	return true
+}
+
+// close removes all links and prevents new ones. It returns after all stream
+// handlers return.
+func (n *network) close() {
+	n.mu.Lock()
+	n.closed = true
+	links := make([]*link, 0, len(n.links))
+	for key, l := range n.links {
+		delete(n.links, key)
+		n.closeLink(l)
+		links = append(links, l)
+	}
+	n.mu.Unlock()
+
+	for _, l := range links {
+		notifyDisconnected(l)
+	}
+	n.handlers.Wait()
+}
+
+func (n *network) connected(a, b swarm.Address) bool {
+	_, ok := n.link(a, b)
+	return ok
+}
+
+func (n *network) peers(s *service) []p2p.Peer {
+	n.mu.RLock()
+	peers := make([]p2p.Peer, 0)
+	for _, l := range n.links {
+		if l.dialer == s || l.listener == s {
+			peers = append(peers, p2p.Peer{Address: l.peer(s).overlay()})
+		}
+	}
+	n.mu.RUnlock()
+
+	sort.Slice(peers, func(i, j int) bool {
+		return bytes.Compare(peers[i].Address.Bytes(), peers[j].Address.Bytes()) == -1
+	})
+	return peers
+}
+
+// partition assigns the partition groups and removes the links between the
+// peers in different groups. The removed links are returned.
+func (n *network) partition(groups map[string]int) (dropped []*link) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.groups = groups
+	for key, l := range n.links {
+		if n.reachable(l.dialer.overlay(), l.listener.overlay()) {
+			continue
+		}
+		delete(n.links, key)
+		n.closeLink(l)
+		dropped = append(dropped, l)
+	}
+	return dropped
+}
+
+func (n *network) heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.groups = nil
+}
+
+// reachable returns true if the peers are in the same partition. It must be
+// called with the lock held.
+func (n *network) reachable(a, b swarm.Address) bool {
+	if n.groups == nil {
+		return true
+	}
+	return n.groups[a.ByteString()] == n.groups[b.ByteString()]
+}
+
+var _ p2p.Service = (*service)(nil)
+var _ p2p.Streamer = (*service)(nil)
+var _ node.P2PService = (*service)(nil)
+
+// service is an in-memory p2p service of a simulated node.
+type service struct {
+	net         *network
+	address     *bzz.Address
+	addressbook addressbook.Putter
+	protocols   []p2p.ProtocolSpec
+	notifiers   []topology.Notifier
+	blocklist   map[string]time.Time // zero expiry blocks permanently
+	mu          sync.RWMutex
+	logger      logging.Logger
+}
+
+func newService(net *network, address *bzz.Address, addressbook addressbook.Putter, logger logging.Logger) *service {
+	s := &service{
+		net:         net,
+		address:     address,
+		addressbook: addressbook,
+		blocklist:   make(map[string]time.Time),
+		logger:      logger,
+	}
+	net.add(s)
+	return s
+}
+
+func (s *service) overlay() swarm.Address {
+	return s.address.Overlay
+}
+
+func (s *service) AddProtocol(p p2p.ProtocolSpec) error {
+	if _, err := semver.NewVersion(p.Version); err != nil {
+		return fmt.Errorf("protocol %s version %s: %w", p.Name, p.Version, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protocols = append(s.protocols, p)
+	return nil
+}
+
+func (s *service) ConnectNotify(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+	address, err := s.Connect(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect notify: %w", err)
+	}
+
+	for _, n := range s.topologyNotifiers() {
+		if err := n.Connected(ctx, address.Overlay); err != nil {
+			_ = s.Disconnect(address.Overlay)
+			return nil, fmt.Errorf("notify topology: %w", err)
+		}
+	}
+	return address, nil
+}
+
+func (s *service) Connect(ctx context.Context, addr ma.Multiaddr) (*bzz.Address, error) {
+	remote := s.net.lookup(addr)
+	if remote == nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, errUnknownAddress)
+	}
+	if remote == s {
+		return nil, fmt.Errorf("dial %s: %w", addr, errConnectionRefused)
+	}
+	if s.blocked(remote.overlay()) {
+		return nil, p2p.ErrPeerBlocklisted
+	}
+	if remote.blocked(s.overlay()) {
+		return nil, fmt.Errorf("dial %s: %w", addr, errConnectionRefused)
+	}
+
+	l, err := s.net.connect(s, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addressbook.Put(remote.overlay(), *remote.address); err != nil {
+		_ = s.Disconnect(remote.overlay())
+		return nil, fmt.Errorf("storing bzz address: %w", err)
+	}
+
+	// the remote topology is notified asynchronously, as it is with the
+	// inbound connections handler of the libp2p service
+	go remote.accept(l, s)
+
+	return remote.address, nil
+}
+
+// accept stores the dialer address and notifies the topology about the
+// inbound connection.
+func (s *service) accept(l *link, dialer *service) {
+	if err := s.addressbook.Put(dialer.overlay(), *dialer.address); err != nil {
+		s.logger.Debugf("simulation: addressbook put %s: %v", dialer.overlay(), err)
+		_ = s.Disconnect(dialer.overlay())
+		return
+	}
+
+	for _, n := range s.topologyNotifiers() {
+		if err := n.Connected(l.ctx, dialer.overlay()); err != nil {
+			s.logger.Debugf("simulation: topology notifier %s: %v", dialer.overlay(), err)
+		}
+	}
+}
+
+func (s *service) Disconnect(overlay swarm.Address) error {
+	l := s.net.disconnect(s.overlay(), overlay)
+	if l == nil {
+		return p2p.ErrPeerNotFound
+	}
+	notifyDisconnected(l)
+	return nil
+}
+
+// notifyDisconnected notifies the topologies of both peers of the link.
+func notifyDisconnected(l *link) {
+	for _, n := range l.dialer.topologyNotifiers() {
+		n.Disconnected(l.listener.overlay())
+	}
+	for _, n := range l.listener.topologyNotifiers() {
+		n.Disconnected(l.dialer.overlay())
+	}
+}
+
+// Close disconnects the service from all peers.
+func (s *service) Close() error {
+	for _, p := range s.Peers() {
+		if err := s.Disconnect(p.Address); err != nil && !errors.Is(err, p2p.ErrPeerNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) Peers() []p2p.Peer {
+	return s.net.peers(s)
+}
+
+func (s *service) AddNotifier(n topology.Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifiers = append(s.notifiers, n)
+}
+
+func (s *service) topologyNotifiers() []topology.Notifier {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]topology.Notifier(nil), s.notifiers...)
+}
+
+func (s *service) Addresses() ([]ma.Multiaddr, error) {
+	return []ma.Multiaddr{s.address.Underlay}, nil
+}
+
+func (s *service) Blocklist(overlay swarm.Address, duration time.Duration) error {
+	var expiry time.Time
+	if duration > 0 {
+		expiry = time.Now().Add(duration)
+	}
+
+	s.mu.Lock()
+	s.blocklist[overlay.ByteString()] = expiry
+	s.mu.Unlock()
+
+	if err := s.Disconnect(overlay); err != nil && !errors.Is(err, p2p.ErrPeerNotFound) {
+		return fmt.Errorf("disconnect blocklisted peer %s: %w", overlay, err)
+	}
+	return nil
+}
+
+func (s *service) Blocklisted(overlay swarm.Address) (bool, error) {
+	return s.blocked(overlay), nil
+}
+
+func (s *service) blocked(overlay swarm.Address) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiry, ok := s.blocklist[overlay.ByteString()]
+	return ok && (expiry.IsZero() || time.Now().Before(expiry))
+}
+
+func (s *service) NewStream(ctx context.Context, overlay swarm.Address, headers p2p.Headers, protocolName, protocolVersion, streamName string) (p2p.Stream, error) {
+	l, ok := s.net.link(s.overlay(), overlay)
+	if !ok {
+		return nil, p2p.ErrPeerNotFound
+	}
+	remote := l.peer(s)
+
+	spec, ok := remote.streamSpec(protocolName, protocolVersion, streamName)
+	if !ok {
+		return nil, p2p.NewIncompatibleStreamError(fmt.Errorf("protocol %s version %s stream %s not supported", protocolName, protocolVersion, streamName))
+	}
+
+	client, server := newStreamPair(protocolVersion)
+	if !s.net.addStreams(l, client, server) {
+		return nil, p2p.ErrPeerNotFound
+	}
+
+	if headers == nil {
+		headers = make(p2p.Headers)
+	}
+	server.headers = headers
+	if spec.Headler != nil {
+		client.headers = spec.Headler(headers)
+	}
+
+	go func() {
+		defer s.net.handlers.Done()
+
+		err := spec.Handler(l.ctx, p2p.Peer{Address: s.overlay()}, server)
+		if err == nil {
+			return
+		}
+
+		var de *p2p.DisconnectError
+		if errors.As(err, &de) {
+			_ = remote.Disconnect(s.overlay())
+		}
+
+		var bpe *p2p.BlockPeerError
+		if errors.As(err, &bpe) {
+			if err := remote.Blocklist(s.overlay(), bpe.Duration()); err != nil {
+				remote.logger.Debugf("simulation: blocklist peer %s: %v", s.overlay(), err)
+			}
+		}
+
+		remote.logger.Debugf("simulation: handle protocol %s/%s: stream %s: peer %s: %v", protocolName, protocolVersion, streamName, s.overlay(), err)
+	}()
+
+	return client, nil
+}
+
+// streamSpec returns the stream specification of the protocol that handles
+// the requested version, preferring the exact version match, as the libp2p
+// service does.
+func (s *service) streamSpec(protocolName, protocolVersion, streamName string) (spec p2p.StreamSpec, ok bool) {
+	requested, err := semver.NewVersion(protocolVersion)
+	if err != nil {
+		return spec, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.protocols {
+		if p.Name != protocolName {
+			continue
+		}
+		v, err := semver.NewVersion(p.Version)
+		if err != nil || v.Major != requested.Major || v.Minor < requested.Minor {
+			continue
+		}
+		for _, ss := range p.StreamSpecs {
+			if ss.Name != streamName {
+				continue
+			}
+			if p.Version == protocolVersion {
+				return ss, true
+			}
+			if !ok {
+				spec, ok = ss, true
+			}
+		}
+	}
+	return spec, ok
+}
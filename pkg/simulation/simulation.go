@@ -0,0 +1,369 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simulation runs networks of Bee nodes in a single process. The nodes
+// are constructed by the node package, but they are connected over an
+// in-memory p2p network instead of libp2p, so that the simulations can run in
+// plain go tests.
+package simulation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/addressbook"
+	"github.com/ethersphere/bee/pkg/bzz"
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/kademlia"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/node"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	defaultNetworkID        = 1
+	defaultPaymentThreshold = 100000
+	defaultPaymentTolerance = 10000
+
+	// convergenceCheckInterval is the interval between the checks of the
+	// topology in WaitConverged.
+	convergenceCheckInterval = 50 * time.Millisecond
+)
+
+// ErrNodeIndex is returned when a node index is out of range.
+var ErrNodeIndex = errors.New("node index out of range")
+
+// Options configure the simulation.
+type Options struct {
+	// Nodes is the number of nodes in the network.
+	Nodes int
+	// Seed determines the node keys and therefore the overlay addresses.
+	// Simulations with the same seed have the same overlays.
+	Seed int64
+	// Node configures every node. The data directory, the key, the p2p
+	// service and the bootnodes are set by the simulation, all data is kept
+	// in memory and the API servers and mdns discovery are not started.
+	Node node.Options
+	// Logger is shared by all nodes. Logs are discarded if it is nil.
+	Logger logging.Logger
+}
+
+// Node is a simulated Bee node.
+type Node struct {
+	Overlay  swarm.Address
+	Underlay ma.Multiaddr
+	P2P      p2p.Service
+	Topology *kademlia.Kad
+	Storer   *localstore.DB
+	NetStore storage.Storer
+	Tags     *tags.Tags
+
+	bee *node.Bee
+}
+
+// Simulation is a network of simulated nodes.
+type Simulation struct {
+	nodes []*Node
+	net   *network
+
+	// links that were removed by the partition, redialed on heal
+	partitioned []*link
+}
+
+// New starts the network of simulated nodes. The first node is the bootnode of
+// all other nodes.
+func New(o Options) (*Simulation, error) {
+	if o.Nodes <= 0 {
+		return nil, errors.New("no simulation nodes")
+	}
+	if o.Node.NetworkID == 0 {
+		o.Node.NetworkID = defaultNetworkID
+	}
+	if o.Node.PaymentThreshold == 0 {
+		o.Node.PaymentThreshold = defaultPaymentThreshold
+	}
+	if o.Node.PaymentTolerance == 0 {
+		o.Node.PaymentTolerance = defaultPaymentTolerance
+	}
+	if o.Logger == nil {
+		o.Logger = logging.New(ioutil.Discard, 0)
+	}
+
+	s := &Simulation{
+		net: newNetwork(),
+	}
+
+	for i := 0; i < o.Nodes; i++ {
+		var bootnodes []string
+		if i > 0 {
+			bootnodes = append(bootnodes, s.nodes[0].Underlay.String())
+		}
+
+		n, err := s.newNode(i, bootnodes, o)
+		if err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("node %d: %w", i, err)
+		}
+		s.nodes = append(s.nodes, n)
+	}
+
+	return s, nil
+}
+
+// newNode constructs the node with the index, connected to the in-memory
+// network.
+func (s *Simulation) newNode(i int, bootnodes []string, o Options) (*Node, error) {
+	privateKey, err := nodeKey(o.Seed, i)
+	if err != nil {
+		return nil, err
+	}
+	underlay, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/10.%d.%d.%d/tcp/1634", (i>>16)&0xff, (i>>8)&0xff, i&0xff))
+	if err != nil {
+		return nil, err
+	}
+
+	no := o.Node
+	no.DataDir = ""
+	no.APIAddr = ""
+	no.DebugAPIAddr = ""
+	no.EnableMDNS = false
+	no.Bootnodes = bootnodes
+	no.PrivateKey = privateKey
+	no.P2P = func(signer crypto.Signer, overlay swarm.Address, addressbook addressbook.Interface) (node.P2PService, error) {
+		bzzAddress, err := bzz.NewAddress(signer, underlay, overlay, no.NetworkID)
+		if err != nil {
+			return nil, err
+		}
+		return newService(s.net, bzzAddress, addressbook, o.Logger), nil
+	}
+
+	b, err := node.NewBee("", o.Logger, no)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{
+		Overlay:  b.Overlay(),
+		Underlay: underlay,
+		P2P:      b.P2P(),
+		Topology: b.Topology(),
+		Storer:   b.Storer(),
+		NetStore: b.NetStore(),
+		Tags:     b.Tags(),
+		bee:      b,
+	}, nil
+}
+
+// nodeKey returns the deterministic private key of the node with the index.
+func nodeKey(seed int64, i int) (*ecdsa.PrivateKey, error) {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(seed))
+	binary.BigEndian.PutUint64(b[8:], uint64(i))
+	h, err := crypto.LegacyKeccak256(b)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.DecodeSecp256k1PrivateKey(h)
+}
+
+// Nodes returns all simulated nodes.
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Node returns the node with the index.
+func (s *Simulation) Node(i int) (*Node, error) {
+	if i < 0 || i >= len(s.nodes) {
+		return nil, ErrNodeIndex
+	}
+	return s.nodes[i], nil
+}
+
+// Upload stores the data as a content addressed chunk on the node with the
+// index and waits until the chunk is push synced to the network.
+func (s *Simulation) Upload(ctx context.Context, i int, data []byte) (swarm.Address, error) {
+	n, err := s.Node(i)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	ch, err := content.NewChunk(data)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("new chunk: %w", err)
+	}
+
+	tag, err := n.Tags.Create("simulation", 1, false)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("create tag: %w", err)
+	}
+
+	exists, err := n.Storer.Put(ctx, storage.ModePutUpload, ch.WithTagID(tag.Uid))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("put chunk: %w", err)
+	}
+	if exists[0] {
+		tag.Inc(tags.StateSeen)
+	}
+	tag.Inc(tags.StateStored)
+
+	if err := tag.WaitTillDone(ctx, tags.StateSynced); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("wait for push sync: %w", err)
+	}
+	return ch.Address(), nil
+}
+
+// Retrieve gets the chunk from the node with the index, which retrieves it
+// from the network if it is not stored locally.
+func (s *Simulation) Retrieve(ctx context.Context, i int, addr swarm.Address) (swarm.Chunk, error) {
+	n, err := s.Node(i)
+	if err != nil {
+		return nil, err
+	}
+	return n.NetStore.Get(ctx, storage.ModeGetRequest, addr)
+}
+
+// Partition splits the network into groups of nodes, given by their indexes.
+// Nodes that are not in any group form an additional group. Connections
+// between the nodes in different groups are dropped and the nodes can not
+// connect until the partition is healed.
+func (s *Simulation) Partition(groups ...[]int) error {
+	assigned := make(map[string]int, len(s.nodes))
+	for _, n := range s.nodes {
+		assigned[n.Overlay.ByteString()] = len(groups)
+	}
+	for g, group := range groups {
+		for _, i := range group {
+			n, err := s.Node(i)
+			if err != nil {
+				return err
+			}
+			assigned[n.Overlay.ByteString()] = g
+		}
+	}
+
+	dropped := s.net.partition(assigned)
+	for _, l := range dropped {
+		notifyDisconnected(l)
+	}
+	s.partitioned = append(s.partitioned, dropped...)
+	return nil
+}
+
+// Heal removes the partition. Connections that were dropped by the partition
+// are dialed again by the nodes that originally dialed them.
+func (s *Simulation) Heal(ctx context.Context) error {
+	s.net.heal()
+
+	partitioned := s.partitioned
+	s.partitioned = nil
+	for _, l := range partitioned {
+		_, err := l.dialer.ConnectNotify(ctx, l.listener.address.Underlay)
+		if err != nil && !errors.Is(err, p2p.ErrAlreadyConnected) {
+			return fmt.Errorf("reconnect %s to %s: %w", l.dialer.overlay(), l.listener.overlay(), err)
+		}
+	}
+	return nil
+}
+
+// Converged returns true if every node is connected to all nodes within its
+// neighborhood depth that it can reach.
+func (s *Simulation) Converged() bool {
+	for _, n := range s.nodes {
+		depth := n.Topology.NeighborhoodDepth()
+		reachable := 0
+		for _, m := range s.nodes {
+			if n == m || !s.reachable(n, m) {
+				continue
+			}
+			reachable++
+			if swarm.Proximity(n.Overlay.Bytes(), m.Overlay.Bytes()) < depth {
+				continue
+			}
+			if !s.net.connected(n.Overlay, m.Overlay) {
+				return false
+			}
+		}
+		if reachable > 0 && len(n.P2P.Peers()) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Simulation) reachable(a, b *Node) bool {
+	s.net.mu.RLock()
+	defer s.net.mu.RUnlock()
+	return s.net.reachable(a.Overlay, b.Overlay)
+}
+
+// WaitConverged waits until the network is converged or the context is done.
+func (s *Simulation) WaitConverged(ctx context.Context) error {
+	ticker := time.NewTicker(convergenceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.Converged() {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("wait for convergence: %w", ctx.Err())
+		}
+	}
+}
+
+// Close stops all nodes.
+func (s *Simulation) Close() error {
+	s.net.close()
+
+	errs := new(multiError)
+	for i, n := range s.nodes {
+		if err := n.close(); err != nil {
+			errs.add(fmt.Errorf("node %d: %w", i, err))
+		}
+	}
+	if errs.hasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (n *Node) close() error {
+	return n.bee.Shutdown(context.Background())
+}
+
+type multiError struct {
+	errors []error
+}
+
+func (e *multiError) Error() string {
+	if len(e.errors) == 0 {
+		return ""
+	}
+	s := e.errors[0].Error()
+	for _, err := range e.errors[1:] {
+		s += "; " + err.Error()
+	}
+	return s
+}
+
+func (e *multiError) add(err error) {
+	e.errors = append(e.errors, err)
+}
+
+func (e *multiError) hasErrors() bool {
+	return len(e.errors) > 0
+}
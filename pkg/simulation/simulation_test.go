@@ -0,0 +1,128 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/simulation"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const testNodes = 8
+
+func newSimulation(t *testing.T, o simulation.Options) *simulation.Simulation {
+	t.Helper()
+
+	s, err := simulation.New(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.WaitConverged(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDeterministicOverlays(t *testing.T) {
+	s1 := newSimulation(t, simulation.Options{Nodes: 2, Seed: 1})
+	s2 := newSimulation(t, simulation.Options{Nodes: 2, Seed: 1})
+	s3 := newSimulation(t, simulation.Options{Nodes: 2, Seed: 2})
+
+	for i := range s1.Nodes() {
+		o1, o2, o3 := s1.Nodes()[i].Overlay, s2.Nodes()[i].Overlay, s3.Nodes()[i].Overlay
+		if !o1.Equal(o2) {
+			t.Fatalf("node %d: got overlays %s and %s with the same seed", i, o1, o2)
+		}
+		if o1.Equal(o3) {
+			t.Fatalf("node %d: got the same overlay %s with different seeds", i, o1)
+		}
+	}
+}
+
+func TestUploadRetrieve(t *testing.T) {
+	s := newSimulation(t, simulation.Options{Nodes: testNodes})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	data := []byte("simulation upload")
+	addr, err := s.Upload(ctx, 0, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < testNodes; i++ {
+		expectChunk(t, s, i, addr, data)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	s := newSimulation(t, simulation.Options{Nodes: testNodes})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// isolate the last node
+	last := testNodes - 1
+	if err := s.Partition([]int{last}); err != nil {
+		t.Fatal(err)
+	}
+	n, err := s.Node(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peers := n.P2P.Peers(); len(peers) != 0 {
+		t.Fatalf("got %v peers of the isolated node, want none", len(peers))
+	}
+
+	data := []byte("simulation partition")
+	addr, err := s.Upload(ctx, 0, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rctx, rcancel := context.WithTimeout(ctx, time.Second)
+	defer rcancel()
+	if _, err := s.Retrieve(rctx, last, addr); err == nil {
+		t.Fatal("chunk retrieved across the partition")
+	}
+
+	if err := s.Heal(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WaitConverged(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expectChunk(t, s, last, addr, data)
+}
+
+func expectChunk(t *testing.T, s *simulation.Simulation, i int, addr swarm.Address, data []byte) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ch, err := s.Retrieve(ctx, i, addr)
+	if err != nil {
+		t.Fatalf("node %d: retrieve %s: %v", i, addr, err)
+	}
+	// the chunk data is prefixed with the 8 bytes span
+	if !bytes.Equal(ch.Data()[8:], data) {
+		t.Fatalf("node %d: got data %q, want %q", i, ch.Data()[8:], data)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simulation
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/p2p"
+)
+
+var (
+	errStreamClosed = errors.New("stream closed")
+	errStreamReset  = errors.New("stream reset")
+)
+
+var _ p2p.Stream = (*stream)(nil)
+
+// stream is one end of an in-memory bidirectional stream. Writes are
+// buffered, so that the writer never waits for the reader.
+type stream struct {
+	in      *pipe
+	out     *pipe
+	headers p2p.Headers
+	version string
+}
+
+// newStreamPair returns both ends of a new stream.
+func newStreamPair(version string) (*stream, *stream) {
+	a, b := newPipe(), newPipe()
+	return &stream{in: a, out: b, version: version}, &stream{in: b, out: a, version: version}
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	return s.in.read(p)
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	return s.out.write(p)
+}
+
+func (s *stream) Headers() p2p.Headers {
+	return s.headers
+}
+
+func (s *stream) ProtocolVersion() string {
+	return s.version
+}
+
+func (s *stream) Close() error {
+	s.out.close(nil)
+	return nil
+}
+
+func (s *stream) FullClose() error {
+	return s.Close()
+}
+
+func (s *stream) Reset() error {
+	s.out.close(errStreamReset)
+	s.in.close(errStreamReset)
+	return nil
+}
+
+// pipe is a one directional buffer with blocking reads.
+type pipe struct {
+	buf    []byte
+	closed bool
+	err    error // returned by reads after close, io.EOF if nil
+	mu     sync.Mutex
+	cond   *sync.Cond
+}
+
+func newPipe() *pipe {
+	p := new(pipe)
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *pipe) read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.buf) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if p.err != nil {
+		return 0, p.err
+	}
+	if len(p.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	return n, nil
+}
+
+func (p *pipe) write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		if p.err != nil {
+			return 0, p.err
+		}
+		return 0, errStreamClosed
+	}
+	p.buf = append(p.buf, b...)
+	p.cond.Broadcast()
+	return len(b), nil
+}
+
+// close closes the pipe. Buffered data is still readable, unless the pipe is
+// closed with an error.
+func (p *pipe) close(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed && (err == nil || p.err != nil) {
+		return
+	}
+	p.closed = true
+	if err != nil {
+		p.err = err
+		p.buf = nil
+	}
+	p.cond.Broadcast()
+}
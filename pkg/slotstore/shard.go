@@ -0,0 +1,355 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slotstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"os"
+	"sync"
+)
+
+// growSlots is the number of slots that are preallocated when a shard file
+// has no free slots.
+var growSlots uint32 = 1024
+
+// file is the storage of shard slots.
+type file interface {
+	io.ReaderAt
+	io.WriterAt
+	io.Seeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// shard keeps slots in a single file and tracks the free ones in a bitmap.
+type shard struct {
+	file     file
+	freePath string // path of the saved free slots bitmap, empty in memory
+	slotSize int64
+	readOnly bool // the file and the free slots bitmap are not changed
+
+	mu        sync.Mutex
+	slots     uint32 // number of preallocated slots in the file
+	free      []byte // bit is set if the slot is free
+	freeCount uint32
+	hint      uint32 // slots lower than hint are not free
+}
+
+// newShard opens the shard with the free slots saved on the last close. If
+// the free slots are not saved, all slots are marked as used and clean is
+// false. Files are not changed if readOnly is true.
+func newShard(f file, freePath string, slotSize int, readOnly bool) (s *shard, clean bool, err error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, false, err
+	}
+	s = &shard{
+		file:     f,
+		freePath: freePath,
+		slotSize: int64(slotSize),
+		slots:    uint32(size / int64(slotSize)),
+		readOnly: readOnly,
+	}
+	if size%int64(slotSize) != 0 && !readOnly {
+		// remove the partially preallocated slot
+		if err := f.Truncate(int64(s.slots) * s.slotSize); err != nil {
+			return nil, false, err
+		}
+	}
+	s.free = make([]byte, bitmapSize(s.slots))
+	if freePath == "" || s.slots == 0 {
+		s.releaseAll()
+		return s, true, nil
+	}
+
+	free, err := ioutil.ReadFile(freePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// not closed cleanly
+			return s, false, nil
+		}
+		return nil, false, err
+	}
+	if len(free) != len(s.free) {
+		return nil, false, fmt.Errorf("free slots bitmap length %d, want %d", len(free), len(s.free))
+	}
+	copy(s.free, free)
+	for i := uint32(0); i < s.slots; i++ {
+		if s.isFree(i) {
+			s.freeCount++
+		}
+	}
+	if readOnly {
+		return s, true, nil
+	}
+	// free slots are saved again on close and their absence
+	// signals that the shard is not closed cleanly
+	if err := os.Remove(freePath); err != nil {
+		return nil, false, err
+	}
+	return s, true, nil
+}
+
+// write stores the data in a free slot, preallocating new slots if there
+// are no free ones.
+func (s *shard) write(data []byte) (slot uint32, err error) {
+	s.mu.Lock()
+	slot, ok := s.findFree()
+	if !ok {
+		if err := s.grow(); err != nil {
+			s.mu.Unlock()
+			return 0, err
+		}
+		slot, _ = s.findFree()
+	}
+	s.setUsed(slot)
+	s.mu.Unlock()
+
+	if _, err := s.file.WriteAt(data, int64(slot)*s.slotSize); err != nil {
+		s.release(slot)
+		return 0, err
+	}
+	return slot, nil
+}
+
+// read returns length bytes of data from the slot.
+func (s *shard) read(slot uint32, length int) (data []byte, err error) {
+	s.mu.Lock()
+	slots := s.slots
+	s.mu.Unlock()
+	if slot >= slots {
+		return nil, ErrInvalidLocation
+	}
+	data = make([]byte, length)
+	if _, err := s.file.ReadAt(data, int64(slot)*s.slotSize); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// sync commits the written data of the file to disk.
+func (s *shard) sync() error {
+	if s.readOnly {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+// release marks the slot as free.
+func (s *shard) release(slot uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slot >= s.slots || s.isFree(slot) {
+		return
+	}
+	s.free[slot/8] |= 1 << (slot % 8)
+	s.freeCount++
+	if slot < s.hint {
+		s.hint = slot
+	}
+}
+
+// use marks the slot as used.
+func (s *shard) use(slot uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slot >= s.slots || !s.isFree(slot) {
+		return
+	}
+	s.setUsed(slot)
+}
+
+// releaseAll marks all slots as free.
+func (s *shard) releaseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.free {
+		s.free[i] = 0xff
+	}
+	if r := s.slots % 8; r != 0 {
+		s.free[len(s.free)-1] = 1<<r - 1
+	}
+	s.freeCount = s.slots
+	s.hint = 0
+}
+
+// count returns the number of all and free slots.
+func (s *shard) count() (slots, free uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.slots, s.freeCount
+}
+
+// close saves the free slots bitmap and closes the file.
+func (s *shard) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.freePath != "" && !s.readOnly {
+		tmp := s.freePath + ".tmp"
+		if err := ioutil.WriteFile(tmp, s.free, 0644); err != nil {
+			s.file.Close()
+			return err
+		}
+		if err := os.Rename(tmp, s.freePath); err != nil {
+			s.file.Close()
+			return err
+		}
+	}
+	return s.file.Close()
+}
+
+// findFree returns the lowest free slot. It must be called under the lock.
+func (s *shard) findFree() (slot uint32, ok bool) {
+	if s.freeCount == 0 {
+		return 0, false
+	}
+	for i := s.hint / 8; i < uint32(len(s.free)); i++ {
+		if b := s.free[i]; b != 0 {
+			slot = i*8 + uint32(bits.TrailingZeros8(b))
+			if slot >= s.slots {
+				break
+			}
+			s.hint = slot
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// setUsed marks the free slot as used. It must be called under the lock.
+func (s *shard) setUsed(slot uint32) {
+	s.free[slot/8] &^= 1 << (slot % 8)
+	s.freeCount--
+}
+
+// isFree returns true if the slot is free. It must be called under the
+// lock.
+func (s *shard) isFree(slot uint32) bool {
+	return s.free[slot/8]&(1<<(slot%8)) != 0
+}
+
+// grow preallocates growSlots slots at the end of the file. It must be
+// called under the lock.
+func (s *shard) grow() error {
+	slots := s.slots + growSlots
+	if slots < s.slots {
+		return errors.New("no free slots")
+	}
+	if err := s.file.Truncate(int64(slots) * s.slotSize); err != nil {
+		return err
+	}
+	free := make([]byte, bitmapSize(slots))
+	copy(free, s.free)
+	s.free = free
+	for i := s.slots; i < slots; i++ {
+		s.free[i/8] |= 1 << (i % 8)
+	}
+	s.freeCount += growSlots
+	if s.hint > s.slots {
+		s.hint = s.slots
+	}
+	s.slots = slots
+	return nil
+}
+
+// bitmapSize returns the number of bytes needed for a bitmap of slots.
+func bitmapSize(slots uint32) int {
+	return int((uint64(slots) + 7) / 8)
+}
+
+// memFile is a file kept in memory. Truncating does not allocate memory
+// for the data that is not written.
+type memFile struct {
+	mu   sync.RWMutex
+	data []byte
+	size int64
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (n int, err error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	if end := off + int64(len(p)); end > f.size {
+		p = p[:f.size-off]
+		err = io.EOF
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	if off < int64(len(f.data)) {
+		copy(p, f.data[off:])
+	}
+	return len(p), err
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if end := off + int64(len(p)); end > int64(len(f.data)) {
+		if end <= int64(cap(f.data)) {
+			l := len(f.data)
+			f.data = f.data[:end]
+			// clear the data removed by truncation
+			for i := l; i < int(off); i++ {
+				f.data[i] = 0
+			}
+		} else {
+			data := make([]byte, end, 2*end)
+			copy(data, f.data)
+			f.data = data
+		}
+		if end > f.size {
+			f.size = end
+		}
+	}
+	return copy(f.data[off:], p), nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	switch whence {
+	case io.SeekStart:
+		return offset, nil
+	case io.SeekEnd:
+		return f.size + offset, nil
+	default:
+		return 0, errors.New("unsupported seek")
+	}
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if size < int64(len(f.data)) {
+		f.data = f.data[:size]
+	}
+	f.size = size
+	return nil
+}
+
+func (f *memFile) Sync() error {
+	return nil
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package slotstore stores fixed maximal size data blobs in preallocated
+// slots of shard files, outside of the database that references them.
+//
+// Data is written to a free slot of one of the shards, selected in round
+// robin, and the returned Location is the only information needed to read
+// it back. Released slots are reused by subsequent writes, so that files do
+// not grow as long as the amount of stored data does not. Written data must
+// be synced before its location is stored, and slots must be released only
+// after the removal of their locations is durable. Free slots of every
+// shard are tracked in a bitmap that is saved on Close. If the store is not
+// closed cleanly, all slots are considered used until the free slots are
+// recovered from the locations referenced by the database.
+package slotstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// LocationSize is the length of the binary encoded Location.
+const LocationSize = 7
+
+var (
+	// ErrDataTooLarge is returned by Write if the data does not fit
+	// into a slot.
+	ErrDataTooLarge = errors.New("slotstore: data too large")
+	// ErrInvalidLocation is returned if the location does not
+	// reference a slot in the store.
+	ErrInvalidLocation = errors.New("slotstore: invalid location")
+	// ErrReadOnly is returned by Write if the store is opened
+	// with NewReadOnly.
+	ErrReadOnly = errors.New("slotstore: read-only store")
+)
+
+// Location references data stored in a slot.
+type Location struct {
+	Shard  uint8
+	Slot   uint32
+	Length uint16
+}
+
+// MarshalBinary encodes the location into LocationSize bytes.
+func (l Location) MarshalBinary() ([]byte, error) {
+	b := make([]byte, LocationSize)
+	b[0] = l.Shard
+	binary.BigEndian.PutUint32(b[1:5], l.Slot)
+	binary.BigEndian.PutUint16(b[5:7], l.Length)
+	return b, nil
+}
+
+// UnmarshalBinary decodes the location encoded by MarshalBinary.
+func (l *Location) UnmarshalBinary(b []byte) error {
+	if len(b) != LocationSize {
+		return ErrInvalidLocation
+	}
+	l.Shard = b[0]
+	l.Slot = binary.BigEndian.Uint32(b[1:5])
+	l.Length = binary.BigEndian.Uint16(b[5:7])
+	return nil
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%d/%d/%d", l.Shard, l.Slot, l.Length)
+}
+
+// Store holds data in slots of shard files.
+type Store struct {
+	shards   []*shard
+	slotSize int
+	next     uint32 // round robin counter of shards to write to
+	clean    bool
+	readOnly bool
+	closeMu  sync.Mutex
+	closed   bool
+}
+
+// New opens or creates a store in the directory on the provided path, with
+// shardCount shards and slots of slotSize bytes. If the path is empty, the
+// store is kept in memory. Shard count and slot size must not be changed for
+// an existing store.
+func New(path string, shardCount, slotSize int) (s *Store, err error) {
+	return open(path, shardCount, slotSize, false)
+}
+
+// NewReadOnly opens an existing store in the directory on the provided path
+// without changing its files. Data can not be written to the store, and
+// free slots are not saved on Close.
+func NewReadOnly(path string, shardCount, slotSize int) (s *Store, err error) {
+	return open(path, shardCount, slotSize, true)
+}
+
+// open opens the store for New and NewReadOnly.
+func open(path string, shardCount, slotSize int, readOnly bool) (s *Store, err error) {
+	if shardCount <= 0 || shardCount > 256 {
+		return nil, fmt.Errorf("slotstore: invalid shard count %d", shardCount)
+	}
+	if slotSize <= 0 || slotSize > 1<<16-1 {
+		return nil, fmt.Errorf("slotstore: invalid slot size %d", slotSize)
+	}
+	if path != "" && !readOnly {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+	}
+	store := &Store{
+		shards:   make([]*shard, shardCount),
+		slotSize: slotSize,
+		clean:    true,
+		readOnly: readOnly,
+	}
+	defer func() {
+		if err != nil {
+			store.closeShards()
+		}
+	}()
+	s = store
+	for i := range s.shards {
+		var (
+			f        file
+			freePath string
+		)
+		if path == "" {
+			f = new(memFile)
+		} else {
+			flag := os.O_RDWR | os.O_CREATE
+			if readOnly {
+				flag = os.O_RDONLY
+			}
+			f, err = os.OpenFile(filepath.Join(path, fmt.Sprintf("shard_%03d", i)), flag, 0644)
+			if err != nil {
+				return nil, err
+			}
+			freePath = filepath.Join(path, fmt.Sprintf("free_%03d", i))
+		}
+		sh, clean, err := newShard(f, freePath, slotSize, readOnly)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("slotstore: shard %d: %w", i, err)
+		}
+		s.shards[i] = sh
+		if !clean {
+			s.clean = false
+		}
+	}
+	return s, nil
+}
+
+// Clean returns false if the store was not closed cleanly and free slots
+// need to be recovered.
+func (s *Store) Clean() bool {
+	return s.clean
+}
+
+// Write stores the data in a free slot and returns its location.
+func (s *Store) Write(data []byte) (loc Location, err error) {
+	if s.readOnly {
+		return loc, ErrReadOnly
+	}
+	if len(data) > s.slotSize {
+		return loc, ErrDataTooLarge
+	}
+	i := int(atomic.AddUint32(&s.next, 1) % uint32(len(s.shards)))
+	slot, err := s.shards[i].write(data)
+	if err != nil {
+		return loc, fmt.Errorf("slotstore: shard %d: %w", i, err)
+	}
+	return Location{
+		Shard:  uint8(i),
+		Slot:   slot,
+		Length: uint16(len(data)),
+	}, nil
+}
+
+// Sync commits the data written on the locations to disk, so that the
+// locations can be stored durably.
+func (s *Store) Sync(locs ...Location) error {
+	synced := make(map[uint8]struct{})
+	for _, loc := range locs {
+		if _, ok := synced[loc.Shard]; ok || int(loc.Shard) >= len(s.shards) {
+			continue
+		}
+		if err := s.shards[loc.Shard].sync(); err != nil {
+			return fmt.Errorf("slotstore: shard %d: %w", loc.Shard, err)
+		}
+		synced[loc.Shard] = struct{}{}
+	}
+	return nil
+}
+
+// Read returns the data stored on the location. The result is undefined if
+// the slot is released.
+func (s *Store) Read(loc Location) (data []byte, err error) {
+	if int(loc.Shard) >= len(s.shards) || int(loc.Length) > s.slotSize {
+		return nil, ErrInvalidLocation
+	}
+	data, err = s.shards[loc.Shard].read(loc.Slot, int(loc.Length))
+	if err != nil {
+		return nil, fmt.Errorf("slotstore: shard %d: %w", loc.Shard, err)
+	}
+	return data, nil
+}
+
+// Release marks the slots on the locations free to be reused. Data must not
+// be read from released locations, which must not be referenced after a
+// crash, as their slots may be overwritten.
+func (s *Store) Release(locs ...Location) {
+	for _, loc := range locs {
+		if int(loc.Shard) < len(s.shards) {
+			s.shards[loc.Shard].release(loc.Slot)
+		}
+	}
+}
+
+// Recover marks all slots as free, except the ones on locations that the
+// iterate function provides to its argument. It must be called before the
+// store is used.
+func (s *Store) Recover(iterate func(use func(Location)) error) error {
+	for _, sh := range s.shards {
+		sh.releaseAll()
+	}
+	if err := iterate(func(loc Location) {
+		if int(loc.Shard) < len(s.shards) {
+			s.shards[loc.Shard].use(loc.Slot)
+		}
+	}); err != nil {
+		return err
+	}
+	s.clean = true
+	return nil
+}
+
+// Size returns the size of all slots in bytes and the size of the free
+// ones.
+func (s *Store) Size() (size, free uint64) {
+	for _, sh := range s.shards {
+		slots, freeSlots := sh.count()
+		size += uint64(slots) * uint64(s.slotSize)
+		free += uint64(freeSlots) * uint64(s.slotSize)
+	}
+	return size, free
+}
+
+// Close saves the free slots and closes the shard files.
+func (s *Store) Close() (err error) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	for i, sh := range s.shards {
+		if err := sh.close(); err != nil {
+			return fmt.Errorf("slotstore: shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// closeShards closes the files of opened shards without saving free slots.
+func (s *Store) closeShards() {
+	for _, sh := range s.shards {
+		if sh != nil {
+			sh.file.Close()
+		}
+	}
+}
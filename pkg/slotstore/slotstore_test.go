@@ -0,0 +1,311 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slotstore_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/slotstore"
+)
+
+const (
+	testShardCount = 4
+	testSlotSize   = 64
+)
+
+func TestLocation(t *testing.T) {
+	want := slotstore.Location{Shard: 3, Slot: 123456, Length: 4104}
+	b, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != slotstore.LocationSize {
+		t.Fatalf("got location length %v, want %v", len(b), slotstore.LocationSize)
+	}
+	var got slotstore.Location
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got location %v, want %v", got, want)
+	}
+	if err := got.UnmarshalBinary(b[1:]); !errors.Is(err, slotstore.ErrInvalidLocation) {
+		t.Errorf("got error %v, want %v", err, slotstore.ErrInvalidLocation)
+	}
+}
+
+func TestStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		s, err := slotstore.New("", testShardCount, testSlotSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		testStore(t, s)
+	})
+	t.Run("disk", func(t *testing.T) {
+		dir := tempDir(t)
+		s, err := slotstore.New(dir, testShardCount, testSlotSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+
+		testStore(t, s)
+	})
+}
+
+func testStore(t *testing.T, s *slotstore.Store) {
+	t.Helper()
+
+	if !s.Clean() {
+		t.Fatal("new store is not clean")
+	}
+
+	data := make(map[slotstore.Location][]byte)
+	for i := 0; i < 100; i++ {
+		d := randomData(t, i%testSlotSize+1)
+		loc, err := s.Write(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := data[loc]; ok {
+			t.Fatalf("location %v written twice", loc)
+		}
+		data[loc] = d
+	}
+	for loc, want := range data {
+		got, err := s.Read(loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("got data %x on location %v, want %x", got, loc, want)
+		}
+	}
+
+	size, free := s.Size()
+	if used := (size - free) / testSlotSize; used != 100 {
+		t.Errorf("got %v used slots, want %v", used, 100)
+	}
+
+	// released slots are reused
+	var released []slotstore.Location
+	for loc := range data {
+		released = append(released, loc)
+		delete(data, loc)
+		if len(released) == 10 {
+			break
+		}
+	}
+	s.Release(released...)
+	for i := 0; i < 10; i++ {
+		if _, err := s.Write(randomData(t, testSlotSize)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newSize, _ := s.Size()
+	if newSize != size {
+		t.Errorf("got size %v after reusing slots, want %v", newSize, size)
+	}
+
+	if _, err := s.Write(randomData(t, testSlotSize+1)); !errors.Is(err, slotstore.ErrDataTooLarge) {
+		t.Errorf("got error %v, want %v", err, slotstore.ErrDataTooLarge)
+	}
+	if _, err := s.Read(slotstore.Location{Shard: testShardCount}); !errors.Is(err, slotstore.ErrInvalidLocation) {
+		t.Errorf("got error %v, want %v", err, slotstore.ErrInvalidLocation)
+	}
+}
+
+// TestStore_reopen validates that the data and free slots are persisted
+// on close.
+func TestStore_reopen(t *testing.T) {
+	dir := tempDir(t)
+
+	s, err := slotstore.New(dir, testShardCount, testSlotSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locs []slotstore.Location
+	var data [][]byte
+	for i := 0; i < 20; i++ {
+		d := randomData(t, testSlotSize)
+		loc, err := s.Write(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locs = append(locs, loc)
+		data = append(data, d)
+	}
+	if err := s.Sync(locs...); err != nil {
+		t.Fatal(err)
+	}
+	s.Release(locs[10:]...)
+	size, free := s.Size()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = slotstore.New(dir, testShardCount, testSlotSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if !s.Clean() {
+		t.Error("reopened store is not clean")
+	}
+	gotSize, gotFree := s.Size()
+	if gotSize != size || gotFree != free {
+		t.Errorf("got size %v and free %v, want %v and %v", gotSize, gotFree, size, free)
+	}
+	for i, loc := range locs[:10] {
+		got, err := s.Read(loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data[i]) {
+			t.Errorf("got data %x on location %v, want %x", got, loc, data[i])
+		}
+	}
+}
+
+// TestStore_readOnly validates that data is read from a read-only store
+// and that its files are not changed.
+func TestStore_readOnly(t *testing.T) {
+	dir := tempDir(t)
+
+	if _, err := slotstore.NewReadOnly(dir+"/missing", testShardCount, testSlotSize); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("got error %v, want %v", err, os.ErrNotExist)
+	}
+
+	s, err := slotstore.New(dir, testShardCount, testSlotSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := randomData(t, testSlotSize)
+	loc, err := s.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = slotstore.NewReadOnly(dir, testShardCount, testSlotSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Clean() {
+		t.Error("read-only store is not clean")
+	}
+	got, err := s.Read(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got data %x, want %x", got, data)
+	}
+	if _, err := s.Write(data); !errors.Is(err, slotstore.ErrReadOnly) {
+		t.Errorf("got error %v, want %v", err, slotstore.ErrReadOnly)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gotFiles, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotFiles) != len(files) {
+		t.Fatalf("got %v files, want %v", len(gotFiles), len(files))
+	}
+	for i, f := range files {
+		if g := gotFiles[i]; g.Name() != f.Name() || g.Size() != f.Size() || !g.ModTime().Equal(f.ModTime()) {
+			t.Errorf("got file %s changed", f.Name())
+		}
+	}
+}
+
+// TestStore_recover validates that free slots are recovered if the store
+// is not closed cleanly.
+func TestStore_recover(t *testing.T) {
+	dir := tempDir(t)
+
+	s, err := slotstore.New(dir, testShardCount, testSlotSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locs []slotstore.Location
+	for i := 0; i < 20; i++ {
+		loc, err := s.Write(randomData(t, testSlotSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		locs = append(locs, loc)
+	}
+	// open the same files again without closing,
+	// as after a crash, the free slots are not saved
+	s, err = slotstore.New(dir, testShardCount, testSlotSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if s.Clean() {
+		t.Fatal("store is clean")
+	}
+	if _, free := s.Size(); free != 0 {
+		t.Errorf("got %v free bytes before recovery, want 0", free)
+	}
+
+	err = s.Recover(func(use func(slotstore.Location)) error {
+		for _, loc := range locs[:5] {
+			use(loc)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Clean() {
+		t.Error("recovered store is not clean")
+	}
+	size, free := s.Size()
+	if used := (size - free) / testSlotSize; used != 5 {
+		t.Errorf("got %v used slots, want %v", used, 5)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "slotstore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -5,8 +5,13 @@
 package mock
 
 import (
+	"archive/tar"
 	"context"
+	"encoding/hex"
 	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
 	"sync"
 
 	"github.com/ethersphere/bee/pkg/storage"
@@ -29,6 +34,9 @@ type MockStorer struct {
 	quit            chan struct{}
 	baseAddress     []byte
 	bins            []uint64
+	usage           storage.Usage
+	scrubStatus     storage.ScrubStatus
+	gcStatus        storage.GCStatus
 }
 
 func WithSubscribePullChunks(chs ...storage.Descriptor) Option {
@@ -58,6 +66,24 @@ func WithPartialInterval(v bool) Option {
 	})
 }
 
+func WithUsage(u storage.Usage) Option {
+	return optionFunc(func(m *MockStorer) {
+		m.usage = u
+	})
+}
+
+func WithScrubStatus(s storage.ScrubStatus) Option {
+	return optionFunc(func(m *MockStorer) {
+		m.scrubStatus = s
+	})
+}
+
+func WithGCStatus(s storage.GCStatus) Option {
+	return optionFunc(func(m *MockStorer) {
+		m.gcStatus = s
+	})
+}
+
 func NewStorer(opts ...Option) *MockStorer {
 	s := &MockStorer{
 		store:    make(map[string][]byte),
@@ -297,6 +323,135 @@ func (m *MockStorer) PinInfo(address swarm.Address) (uint64, error) {
 	return 0, storage.ErrNotFound
 }
 
+func (m *MockStorer) Usage() (storage.Usage, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.usage, nil
+}
+
+func (m *MockStorer) ScrubStatus() (storage.ScrubStatus, error) {
+	return m.scrubStatus, nil
+}
+
+func (m *MockStorer) GCStatus() (storage.GCStatus, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.gcStatus, nil
+}
+
+// TriggerGC counts garbage collection runs in the status.
+func (m *MockStorer) TriggerGC() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if !m.gcStatus.Paused {
+		m.gcStatus.Runs++
+	}
+}
+
+func (m *MockStorer) SetGCPaused(paused bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.gcStatus.Paused = paused
+}
+
+// SetCapacity sets the garbage collection capacity in the status
+// to the capacity reduced by the reserve capacity of the usage.
+func (m *MockStorer) SetCapacity(capacity uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.usage.ReserveCapacity >= capacity {
+		return storage.ErrInvalidCapacity
+	}
+	m.usage.ChunkCapacity = capacity
+	m.gcStatus.Capacity = capacity - m.usage.ReserveCapacity
+	return nil
+}
+
+// Export writes the stored chunks in the localstore export format. Store
+// times are not recorded, so the time range of the filter is ignored.
+func (m *MockStorer) Export(w io.Writer, f storage.ExportFilter) (count uint64, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	bins := make(map[uint8]bool, len(f.Bins))
+	for _, bin := range f.Bins {
+		bins[bin] = true
+	}
+	pinned := make(map[string]bool, len(m.pinnedAddress))
+	for _, addr := range m.pinnedAddress {
+		pinned[addr.String()] = true
+	}
+	keys := make([]string, 0, len(m.store))
+	for k := range m.store {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tar.NewWriter(w)
+	defer tw.Close()
+
+	version := []byte("1")
+	if err := tw.WriteHeader(&tar.Header{Name: ".swarm-export-version", Mode: 0644, Size: int64(len(version))}); err != nil {
+		return 0, err
+	}
+	if _, err := tw.Write(version); err != nil {
+		return 0, err
+	}
+	for _, k := range keys {
+		addr := swarm.MustParseHexAddress(k)
+		if len(bins) > 0 && !bins[swarm.Proximity(addr.Bytes(), m.baseAddress)] {
+			continue
+		}
+		if f.Pinned && !pinned[k] {
+			continue
+		}
+		data := m.store[k]
+		if err := tw.WriteHeader(&tar.Header{Name: k, Mode: 0644, Size: int64(len(data))}); err != nil {
+			return count, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Import stores the chunks from the localstore export format.
+func (m *MockStorer) Import(r io.Reader, o storage.ImportOptions) (result storage.ImportResult, err error) {
+	mode := storage.ModePutRequest
+	if o.PushSync {
+		mode = storage.ModePutUpload
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+			return result, err
+		}
+		addr, err := hex.DecodeString(hdr.Name)
+		if err != nil || len(addr) != swarm.HashSize {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return result, err
+		}
+		ch := swarm.NewChunk(swarm.NewAddress(addr), data)
+		if o.Validator != nil && !o.Validator.Validate(ch) {
+			result.Invalid++
+			continue
+		}
+		if _, err := m.Put(context.Background(), mode, ch); err != nil {
+			return result, err
+		}
+		result.Imported++
+	}
+}
+
 func (m *MockStorer) Close() error {
 	close(m.quit)
 	return nil
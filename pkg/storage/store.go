@@ -9,13 +9,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 var (
-	ErrNotFound     = errors.New("storage: not found")
-	ErrInvalidChunk = errors.New("storage: invalid chunk")
+	ErrNotFound        = errors.New("storage: not found")
+	ErrInvalidChunk    = errors.New("storage: invalid chunk")
+	ErrInvalidCapacity = errors.New("storage: invalid capacity")
+	ErrReadOnly        = errors.New("storage: read-only")
+	ErrInvalidImport   = errors.New("storage: invalid import data")
 )
 
 // ModeGet enumerates different Getter modes.
@@ -129,6 +133,69 @@ type Pinner struct {
 	PinCounter uint64
 }
 
+// Usage holds the disk usage of a store against its capacity.
+type Usage struct {
+	Size            uint64 // size on disk in bytes
+	Capacity        uint64 // capacity in bytes, zero if not limited
+	Chunks          uint64 // number of garbage collectable chunks
+	ChunkCapacity   uint64 // capacity in number of chunks
+	Reserve         uint64 // number of chunks in the reserve
+	ReserveCapacity uint64 // reserve capacity in number of chunks
+	StorageRadius   uint8  // proximity order of the reserve chunks
+}
+
+// ScrubStatus holds the progress and findings of the background
+// validation of stored chunks.
+type ScrubStatus struct {
+	Enabled       bool      // scrubbing is configured
+	Running       bool      // a pass is in progress
+	Passes        uint64    // number of completed passes
+	Checked       uint64    // chunks checked in the current or the last pass
+	Corrupt       uint64    // corrupt chunks found in all passes
+	Removed       uint64    // corrupt chunks removed from the store
+	Repaired      uint64    // corrupt pinned chunks retrieved from the network
+	Quarantined   uint64    // corrupt pinned chunks waiting to be retrieved
+	LastPassStart time.Time // start of the current or the last pass
+	LastPassEnd   time.Time // end of the last completed pass
+}
+
+// GCStatus holds the state of the garbage collection of a store.
+type GCStatus struct {
+	Paused             bool        // runs are skipped until it is resumed
+	Running            bool        // a run is in progress
+	Size               uint64      // number of garbage collectable chunks
+	Capacity           uint64      // number of garbage collectable chunks that triggers a run
+	Target             uint64      // number of garbage collectable chunks left by a run
+	Excluded           uint64      // pinned chunks waiting to be excluded from garbage collection
+	NextEvictionAccess []time.Time // access times of the chunks that are collected first, in the gc policy order
+	Runs               uint64      // number of completed runs
+	LastRunStart       time.Time   // start of the current or the last run
+	LastRunEnd         time.Time   // end of the last completed run
+	LastRunCollected   uint64      // chunks removed by the last completed run
+	LastRunError       string      // last error of the last completed run
+}
+
+// ExportFilter selects the chunks that are exported from a store. Zero
+// values of fields do not filter chunks.
+type ExportFilter struct {
+	Bins   []uint8   // proximity order bins of chunks to the base address
+	Pinned bool      // only pinned chunks
+	Since  time.Time // chunks stored at or after the time
+	Until  time.Time // chunks stored before the time
+}
+
+// ImportOptions defines how the chunks are imported to a store.
+type ImportOptions struct {
+	Validator swarm.Validator // invalid chunks are skipped if it is set
+	PushSync  bool            // chunks are stored as uploaded to be push synced
+}
+
+// ImportResult holds the numbers of chunks read by an import.
+type ImportResult struct {
+	Imported uint64 // stored chunks
+	Invalid  uint64 // skipped invalid chunks
+}
+
 func (d *Descriptor) String() string {
 	if d == nil {
 		return ""
@@ -147,6 +214,14 @@ type Storer interface {
 	SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop func())
 	PinnedChunks(ctx context.Context, cursor swarm.Address) (pinnedChunks []*Pinner, err error)
 	PinInfo(address swarm.Address) (uint64, error)
+	Usage() (Usage, error)
+	ScrubStatus() (ScrubStatus, error)
+	GCStatus() (GCStatus, error)
+	TriggerGC()
+	SetGCPaused(paused bool)
+	SetCapacity(capacity uint64) error
+	Export(w io.Writer, f ExportFilter) (count uint64, err error)
+	Import(r io.Reader, o ImportOptions) (ImportResult, error)
 	io.Closer
 }
 
@@ -45,6 +45,7 @@ const (
 	StateSeen                // chunk previously seen
 	StateSent                // chunk sent to neighbourhood
 	StateSynced              // proof is received; chunk removed from sync db; chunk is available everywhere
+	StateFailed              // chunk failed to be pushed after the maximal number of attempts
 )
 
 // Tag represents info on the status of new chunks
@@ -55,6 +56,7 @@ type Tag struct {
 	Stored int64 // number of chunks already stored locally
 	Sent   int64 // number of chunks sent for push syncing
 	Synced int64 // number of chunks synced with proof
+	Failed int64 // number of chunks that failed to be pushed
 
 	Uid       uint32        // a unique identifier for this tag
 	Anonymous bool          // indicates if the tag is anonymous (i.e. if only pull sync should be used)
@@ -112,6 +114,8 @@ func (t *Tag) IncN(state State, n int) {
 		v = &t.Sent
 	case StateSynced:
 		v = &t.Synced
+	case StateFailed:
+		v = &t.Failed
 	}
 	atomic.AddInt64(v, int64(n))
 }
@@ -137,6 +141,8 @@ func (t *Tag) Get(state State) int64 {
 		v = &t.Sent
 	case StateSynced:
 		v = &t.Synced
+	case StateFailed:
+		v = &t.Failed
 	}
 	return atomic.LoadInt64(v)
 }
@@ -228,6 +234,7 @@ func (tag *Tag) MarshalBinary() (data []byte, err error) {
 	encodeInt64Append(&buffer, tag.Stored)
 	encodeInt64Append(&buffer, tag.Sent)
 	encodeInt64Append(&buffer, tag.Synced)
+	encodeInt64Append(&buffer, tag.Failed)
 
 	intBuffer := make([]byte, 8)
 
@@ -256,6 +263,7 @@ func (tag *Tag) UnmarshalBinary(buffer []byte) error {
 	tag.Stored = decodeInt64Splice(&buffer)
 	tag.Sent = decodeInt64Splice(&buffer)
 	tag.Synced = decodeInt64Splice(&buffer)
+	tag.Failed = decodeInt64Splice(&buffer)
 
 	t, n := binary.Varint(buffer)
 	tag.StartedAt = time.Unix(t, 0)
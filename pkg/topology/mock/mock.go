@@ -16,6 +16,7 @@ type mock struct {
 	peers           []swarm.Address
 	closestPeer     swarm.Address
 	closestPeerErr  error
+	depth           uint8
 	addPeersErr     error
 	marshalJSONFunc func() ([]byte, error)
 	mtx             sync.Mutex
@@ -39,6 +40,12 @@ func WithClosestPeerErr(err error) Option {
 	})
 }
 
+func WithNeighborhoodDepth(depth uint8) Option {
+	return optionFunc(func(d *mock) {
+		d.depth = depth
+	})
+}
+
 func WithMarshalJSONFunc(f func() ([]byte, error)) Option {
 	return optionFunc(func(d *mock) {
 		d.marshalJSONFunc = f
@@ -86,8 +93,8 @@ func (d *mock) SubscribePeersChange() (c <-chan struct{}, unsubscribe func()) {
 	return c, unsubscribe
 }
 
-func (_ *mock) NeighborhoodDepth() uint8 {
-	return 0
+func (d *mock) NeighborhoodDepth() uint8 {
+	return d.depth
 }
 
 // EachPeer iterates from closest bin to farthest
@@ -22,7 +22,7 @@ type Driver interface {
 	ClosestPeerer
 	EachPeerer
 	Notifier
-	NeighborhoodDepth() uint8
+	NeighborhoodDepther
 	SubscribePeersChange() (c <-chan struct{}, unsubscribe func())
 	io.Closer
 }
@@ -32,6 +32,10 @@ type Notifier interface {
 	Disconnecter
 }
 
+type NeighborhoodDepther interface {
+	NeighborhoodDepth() uint8
+}
+
 type PeerAdder interface {
 	// AddPeers is called when peers are added to the topology backlog
 	AddPeers(ctx context.Context, addr ...swarm.Address) error